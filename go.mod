@@ -2,7 +2,10 @@ module github.com/gustapinto/go-sql-store
 
 go 1.23.2
 
-require github.com/gustapinto/go-kv-store v1.3.1
+require (
+	github.com/google/uuid v1.6.0
+	github.com/gustapinto/go-kv-store v1.3.1
+)
 
 require (
 	github.com/aws/aws-sdk-go-v2 v1.32.5 // indirect
@@ -16,6 +19,5 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/internal/s3shared v1.18.5 // indirect
 	github.com/aws/aws-sdk-go-v2/service/s3 v1.69.0 // indirect
 	github.com/aws/smithy-go v1.22.1 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	google.golang.org/protobuf v1.35.2 // indirect
 )
@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+)
+
+const testConcurrencyWorkers = 16
+
+func testConcurrencyRun(workers int, fn func(worker int)) {
+	var wg sync.WaitGroup
+	for worker := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(worker)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestConcurrentInsertSamePrimaryKey(t *testing.T) {
+	engine := testEngineMockEngine(
+		t,
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY, worker INTEGER)",
+	)
+
+	var mu sync.Mutex
+	succeeded, conflicted := 0, 0
+
+	testConcurrencyRun(testConcurrencyWorkers, func(worker int) {
+		_, err := engine.Exec(fmt.Sprintf("INSERT INTO FOO_DB.FOO_TABLE (id, worker) VALUES (1, %d)", worker))
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case err == nil:
+			succeeded++
+
+		case errors.Is(err, dml.ErrPrimaryKeyAlreadyExists):
+			conflicted++
+
+		default:
+			t.Errorf("not expected error, got %s", err)
+		}
+	})
+
+	if succeeded != 1 || conflicted != testConcurrencyWorkers-1 {
+		t.Errorf("expected 1 insert to succeed and %d to conflict, got %d and %d", testConcurrencyWorkers-1, succeeded, conflicted)
+	}
+}
+
+func TestConcurrentInsertAndSelect(t *testing.T) {
+	engine := testEngineMockEngine(
+		t,
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY, name TEXT)",
+	)
+
+	testConcurrencyRun(testConcurrencyWorkers, func(worker int) {
+		if worker%2 == 0 {
+			if _, err := engine.Query("SELECT * FROM FOO_DB.FOO_TABLE"); err != nil {
+				t.Errorf("not expected error when selecting, got %s", err)
+			}
+
+			return
+		}
+
+		if _, err := engine.Exec(fmt.Sprintf("INSERT INTO FOO_DB.FOO_TABLE (id, name) VALUES (%d, 'foo')", worker)); err != nil {
+			t.Errorf("not expected error when inserting, got %s", err)
+		}
+	})
+
+	result, err := engine.Query("SELECT * FROM FOO_DB.FOO_TABLE")
+	if err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if len(result.Rows) != testConcurrencyWorkers/2 {
+		t.Errorf("expected %d rows, got %d", testConcurrencyWorkers/2, len(result.Rows))
+	}
+}
+
+func TestConcurrentUpdateDifferentColumns(t *testing.T) {
+	columns, names, values := "", "", ""
+	for worker := range testConcurrencyWorkers {
+		columns += fmt.Sprintf(", c%d INTEGER", worker)
+		names += fmt.Sprintf(", c%d", worker)
+		values += ", -1"
+	}
+
+	engine := testEngineMockEngine(
+		t,
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY"+columns+")",
+		"INSERT INTO FOO_DB.FOO_TABLE (id"+names+") VALUES (1"+values+")",
+	)
+
+	testConcurrencyRun(testConcurrencyWorkers, func(worker int) {
+		if _, err := engine.Exec(fmt.Sprintf("UPDATE FOO_DB.FOO_TABLE SET c%d = %d WHERE id = 1", worker, worker)); err != nil {
+			t.Errorf("not expected error when updating, got %s", err)
+		}
+	})
+
+	result, err := engine.Query("SELECT * FROM FOO_DB.FOO_TABLE WHERE id = 1")
+	if err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if len(result.Rows) != 1 {
+		t.Errorf("expected 1 row, got %d", len(result.Rows))
+		return
+	}
+
+	for worker := range testConcurrencyWorkers {
+		if value := result.Rows[0][worker+1]; value != int64(worker) {
+			t.Errorf("expected column c%d to be %d, got %v", worker, worker, value)
+		}
+	}
+}
+
+func TestConcurrentDeleteSameRow(t *testing.T) {
+	engine := testEngineMockEngine(
+		t,
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY)",
+		"INSERT INTO FOO_DB.FOO_TABLE (id) VALUES (1)",
+	)
+
+	var mu sync.Mutex
+	deleted := 0
+
+	testConcurrencyRun(testConcurrencyWorkers, func(worker int) {
+		result, err := engine.Exec("DELETE FROM FOO_DB.FOO_TABLE WHERE id = 1")
+		if err != nil {
+			t.Errorf("not expected error when deleting, got %s", err)
+			return
+		}
+
+		mu.Lock()
+		deleted += result["AffectedRows"].(int)
+		mu.Unlock()
+	})
+
+	if deleted != 1 {
+		t.Errorf("expected exactly 1 deleted row, got %d", deleted)
+	}
+}
+
+func TestConcurrentUpdateAndSelectSnapshot(t *testing.T) {
+	const rows = 8
+
+	engine := testEngineMockEngine(
+		t,
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY, version INTEGER)",
+	)
+
+	for id := range rows {
+		if _, err := engine.Exec(fmt.Sprintf("INSERT INTO FOO_DB.FOO_TABLE (id, version) VALUES (%d, 0)", id)); err != nil {
+			t.Fatalf("not expected error when inserting, got %s", err)
+		}
+	}
+
+	testConcurrencyRun(testConcurrencyWorkers, func(worker int) {
+		if worker%2 == 0 {
+			if _, err := engine.Exec(fmt.Sprintf("UPDATE FOO_DB.FOO_TABLE SET version = %d", worker)); err != nil {
+				t.Errorf("not expected error when updating, got %s", err)
+			}
+
+			return
+		}
+
+		result, err := engine.Query("SELECT version FROM FOO_DB.FOO_TABLE")
+		if err != nil {
+			t.Errorf("not expected error when selecting, got %s", err)
+			return
+		}
+
+		// Every row is updated by the same statements, so a consistent snapshot sees the
+		// same version in all of them
+		for _, row := range result.Rows {
+			if row[0] != result.Rows[0][0] {
+				t.Errorf("expected a consistent snapshot, got rows %v", result.Rows)
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"errors"
+
+	gokvstore "github.com/gustapinto/go-kv-store"
+	"github.com/gustapinto/go-sql-store/pkg/executor"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/planner"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var (
+	ErrStatementDoesNotReturnRows = errors.New("statement does not return rows")
+)
+
+type Engine struct {
+	store       *storage.Storage
+	planOptions []planner.Option
+}
+
+// Option Configures an [Engine]
+type Option func(e *Engine)
+
+// WithMaxRecursiveIterations Limits how many times the recursive query of a WITH RECURSIVE
+// common table can run in a statement, see [dql.CommonTable]
+func WithMaxRecursiveIterations(iterations int) Option {
+	return func(e *Engine) {
+		e.planOptions = append(e.planOptions, planner.WithMaxRecursiveIterations(iterations))
+	}
+}
+
+// WithScalarFunction Registers a Go function callable from SQL, see
+// [planner.WithScalarFunction]
+func WithScalarFunction(name string, arguments []ddl.ColumnDataType, returns ddl.ColumnDataType, fn func(arguments []any) (any, error)) Option {
+	return func(e *Engine) {
+		e.planOptions = append(e.planOptions, planner.WithScalarFunction(name, arguments, returns, fn))
+	}
+}
+
+// WithAggregateFunction Registers a Go aggregate function callable from SQL, see
+// [planner.WithAggregateFunction]
+func WithAggregateFunction(name string, argument ddl.ColumnDataType, returns ddl.ColumnDataType, newState func() dql.AggregateState) Option {
+	return func(e *Engine) {
+		e.planOptions = append(e.planOptions, planner.WithAggregateFunction(name, argument, returns, newState))
+	}
+}
+
+func newEngine(store *storage.Storage, options []Option) *Engine {
+	e := &Engine{store: store}
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+// Result The rows returned by a query, ColumnTypes holds the type of each of the Columns
+type Result struct {
+	Columns     []string
+	ColumnTypes []ddl.ColumnDataType
+	Rows        [][]any
+}
+
+func New(rootCollection *gokvstore.Collection, options ...Option) (*Engine, error) {
+	store, err := storage.New(rootCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEngine(store, options), nil
+}
+
+func Open(dataDir string, options ...Option) (*Engine, error) {
+	store, err := storage.Open(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEngine(store, options), nil
+}
+
+func (e *Engine) Storage() *storage.Storage {
+	return e.store
+}
+
+func (e *Engine) Close() error {
+	return e.store.Close()
+}
+
+// Exec Runs a script of statements in a new [Session], a transaction left open by the
+// script is rolled back
+func (e *Engine) Exec(query string) (executor.ExecuteResult, error) {
+	session := e.NewSession()
+	defer session.Close()
+
+	return session.Exec(query)
+}
+
+func (e *Engine) Query(query string) (*Result, error) {
+	result, err := e.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultFromExecuteResult(result)
+}
+
+func resultFromExecuteResult(result executor.ExecuteResult) (*Result, error) {
+	columns, hasColumns := result["Columns"].([]string)
+	columnTypes, hasColumnTypes := result["ColumnTypes"].([]ddl.ColumnDataType)
+	rows, hasRows := result["Rows"].([][]any)
+	if !hasColumns || !hasColumnTypes || !hasRows {
+		return nil, ErrStatementDoesNotReturnRows
+	}
+
+	return &Result{
+		Columns:     columns,
+		ColumnTypes: columnTypes,
+		Rows:        rows,
+	}, nil
+}
@@ -0,0 +1,306 @@
+package engine
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/planner"
+)
+
+func testEngineMockEngine(t *testing.T, queries ...string) *Engine {
+	engine, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("not expected error when opening engine, got %s", err)
+	}
+
+	for _, query := range queries {
+		if _, err := engine.Exec(query); err != nil {
+			t.Fatalf("not expected error when executing %s, got %s", query, err)
+		}
+	}
+
+	return engine
+}
+
+func TestEngineIsolation(t *testing.T) {
+	setup := []string{
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY, name TEXT)",
+	}
+
+	e1 := testEngineMockEngine(t, append(setup, "INSERT INTO FOO_DB.FOO_TABLE (id, name) VALUES (1, 'e1')")...)
+	e2 := testEngineMockEngine(t, append(setup, "INSERT INTO FOO_DB.FOO_TABLE (id, name) VALUES (1, 'e2')")...)
+
+	testCases := []struct {
+		name         string
+		engine       *Engine
+		expectedRows [][]any
+	}{
+		{
+			name:         "should read rows from the first engine",
+			engine:       e1,
+			expectedRows: [][]any{{int64(1), "e1"}},
+		},
+		{
+			name:         "should read rows from the second engine",
+			engine:       e2,
+			expectedRows: [][]any{{int64(1), "e2"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testCase.engine.Query("SELECT * FROM FOO_DB.FOO_TABLE")
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if !slices.EqualFunc(result.Rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, result.Rows)
+				return
+			}
+		})
+	}
+}
+
+func TestEngineRecreateDroppedObjects(t *testing.T) {
+	engine := testEngineMockEngine(
+		t,
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY)",
+		"INSERT INTO FOO_DB.FOO_TABLE (id) VALUES (1)",
+		"DROP DATABASE FOO_DB",
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE OR REPLACE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY, name TEXT)",
+	)
+
+	result, err := engine.Query("SELECT * FROM FOO_DB.FOO_TABLE")
+	if err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if !slices.Equal(result.Columns, []string{"ID", "NAME"}) || len(result.Rows) != 0 {
+		t.Errorf("expected an empty table with columns ID and NAME, got %v", result)
+		return
+	}
+}
+
+func TestEngineQuery(t *testing.T) {
+	engine := testEngineMockEngine(
+		t,
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.BAR (id INTEGER PRIMARY KEY, name TEXT)",
+		"INSERT INTO FOO_DB.BAR (id, name) VALUES (1, 'foo')",
+	)
+
+	if _, err := engine.Query("CREATE DATABASE BAR_DB"); !errors.Is(err, ErrStatementDoesNotReturnRows) {
+		t.Errorf("expected error %v, got %v", ErrStatementDoesNotReturnRows, err)
+		return
+	}
+
+	result, err := engine.Query("SELECT name AS label, id + 0.5 AS half FROM FOO_DB.BAR")
+	if err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	expectedColumnTypes := []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeFloat}
+	if !slices.Equal(result.Columns, []string{"label", "half"}) || !slices.Equal(result.ColumnTypes, expectedColumnTypes) {
+		t.Errorf("expected columns [label half] of types %v, got %v of types %v", expectedColumnTypes, result.Columns, result.ColumnTypes)
+		return
+	}
+
+	if len(result.Rows) != 1 || !slices.Equal(result.Rows[0], []any{"foo", 1.5}) {
+		t.Errorf("expected rows [[foo 1.5]], got %v", result.Rows)
+		return
+	}
+}
+
+func TestEngineMaxRecursiveIterations(t *testing.T) {
+	engine, err := Open(t.TempDir(), WithMaxRecursiveIterations(3))
+	if err != nil {
+		t.Fatalf("not expected error when opening engine, got %s", err)
+	}
+
+	setup := []string{
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.BAR (id INTEGER PRIMARY KEY)",
+		"INSERT INTO FOO_DB.BAR (id) VALUES (1)",
+	}
+	for _, query := range setup {
+		if _, err := engine.Exec(query); err != nil {
+			t.Fatalf("not expected error when executing %s, got %s", query, err)
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		expectedRows  [][]any
+		expectedError error
+	}{
+		{
+			name:         "should run recursive queries up to the limit",
+			query:        "WITH RECURSIVE n (x) AS (SELECT id FROM FOO_DB.BAR UNION ALL SELECT x + 1 FROM n WHERE x < 3) SELECT x FROM n",
+			expectedRows: [][]any{{int64(1)}, {int64(2)}, {int64(3)}},
+		},
+		{
+			name:          "should return ErrRecursionLimitExceeded for recursive queries over the limit",
+			query:         "WITH RECURSIVE n (x) AS (SELECT id FROM FOO_DB.BAR UNION ALL SELECT x + 1 FROM n WHERE x < 4) SELECT x FROM n",
+			expectedError: dql.ErrRecursionLimitExceeded,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := engine.Query(testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if !slices.EqualFunc(result.Rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, result.Rows)
+				return
+			}
+		})
+	}
+}
+
+// productState Multiplies the values of a group
+type productState struct {
+	product int64
+}
+
+func (p *productState) Add(value any) error {
+	p.product *= value.(int64)
+	return nil
+}
+
+func (p *productState) Result() (any, error) {
+	return p.product, nil
+}
+
+func TestEngineUserDefinedFunctions(t *testing.T) {
+	engine, err := Open(
+		t.TempDir(),
+		WithScalarFunction("slug", []ddl.ColumnDataType{ddl.ColumnDataTypeText}, ddl.ColumnDataTypeText, func(arguments []any) (any, error) {
+			return strings.ReplaceAll(strings.ToLower(arguments[0].(string)), " ", "-"), nil
+		}),
+		WithScalarFunction("tax", []ddl.ColumnDataType{ddl.ColumnDataTypeFloat}, ddl.ColumnDataTypeFloat, func(arguments []any) (any, error) {
+			return arguments[0].(float64) * 0.25, nil
+		}),
+		WithScalarFunction("broken", []ddl.ColumnDataType{ddl.ColumnDataTypeInteger}, ddl.ColumnDataTypeInteger, func(arguments []any) (any, error) {
+			return "not an integer", nil
+		}),
+		WithAggregateFunction("product", ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, func() dql.AggregateState {
+			return &productState{product: 1}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("not expected error when opening engine, got %s", err)
+	}
+
+	setup := []string{
+		"CREATE DATABASE SHOP",
+		"CREATE TABLE SHOP.ITEMS (id INTEGER PRIMARY KEY, name TEXT, color TEXT, price FLOAT, quantity INTEGER)",
+		"INSERT INTO SHOP.ITEMS (id, name, color, price, quantity) VALUES (1, 'Blue Lamp', 'blue', 10, 2)",
+		"INSERT INTO SHOP.ITEMS (id, name, color, price, quantity) VALUES (2, 'Red Desk', 'red', 100, 3)",
+		"INSERT INTO SHOP.ITEMS (id, name, color, price, quantity) VALUES (3, 'Blue Chair', 'blue', 40, 4)",
+	}
+	for _, query := range setup {
+		if _, err := engine.Exec(query); err != nil {
+			t.Fatalf("not expected error when executing %s, got %s", query, err)
+		}
+	}
+
+	testCases := []struct {
+		name                string
+		query               string
+		expectedColumnTypes []ddl.ColumnDataType
+		expectedRows        [][]any
+		expectedError       error
+	}{
+		{
+			name:                "should call scalar functions with their declared types",
+			query:               "SELECT SLUG(name), tax(price), Tax(quantity) FROM SHOP.ITEMS WHERE slug(name) <> 'red-desk' ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeFloat, ddl.ColumnDataTypeFloat},
+			expectedRows:        [][]any{{"blue-lamp", 2.5, 0.5}, {"blue-chair", float64(10), float64(1)}},
+		},
+		{
+			name:                "should call aggregate functions for each group",
+			query:               "SELECT color, PRODUCT(quantity) FROM SHOP.ITEMS GROUP BY color HAVING PRODUCT(quantity) > 1 ORDER BY color",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{"blue", int64(8)}, {"red", int64(3)}},
+		},
+		{
+			name:                "should call aggregate functions as window functions",
+			query:               "SELECT id, PRODUCT(quantity) OVER (ORDER BY id) FROM SHOP.ITEMS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(1), int64(2)}, {int64(2), int64(6)}, {int64(3), int64(24)}},
+		},
+		{
+			name:          "should return ErrInvalidDataType for arguments of another type",
+			query:         "SELECT SLUG(price) FROM SHOP.ITEMS",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for results of another type",
+			query:         "SELECT BROKEN(id) FROM SHOP.ITEMS",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrFunctionArguments for a wrong number of arguments",
+			query:         "SELECT TAX(price, price) FROM SHOP.ITEMS",
+			expectedError: planner.ErrFunctionArguments,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := engine.Query(testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if !slices.Equal(result.ColumnTypes, testCase.expectedColumnTypes) {
+				t.Errorf("expected column types %v, got %v", testCase.expectedColumnTypes, result.ColumnTypes)
+				return
+			}
+
+			if !slices.EqualFunc(result.Rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, result.Rows)
+				return
+			}
+		})
+	}
+}
+
+func TestEngineFunctionExists(t *testing.T) {
+	engine, err := Open(t.TempDir(), WithScalarFunction("upper", nil, ddl.ColumnDataTypeText, func([]any) (any, error) {
+		return "", nil
+	}))
+	if err != nil {
+		t.Fatalf("not expected error when opening engine, got %s", err)
+	}
+
+	if _, err := engine.Exec("CREATE DATABASE FOO_DB"); !errors.Is(err, planner.ErrFunctionExists) {
+		t.Errorf("expected error %v, got %v", planner.ErrFunctionExists, err)
+	}
+}
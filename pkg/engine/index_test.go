@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+)
+
+func TestEngineIndexes(t *testing.T) {
+	setup := []string{
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY, name TEXT, team TEXT)",
+		"INSERT INTO FOO_DB.FOO_TABLE (id, name, team) VALUES (1, 'foo', 'a')",
+		"INSERT INTO FOO_DB.FOO_TABLE (id, name, team) VALUES (2, 'bar', 'a')",
+		"INSERT INTO FOO_DB.FOO_TABLE (id, name, team) VALUES (3, 'baz', 'b')",
+		"CREATE INDEX foo_team_idx ON FOO_DB.FOO_TABLE (team)",
+	}
+
+	testCases := []struct {
+		name          string
+		queries       []string
+		query         string
+		expectedRows  [][]any
+		expectedError error
+	}{
+		{
+			name:         "should select rows through an index built over existing rows",
+			query:        "SELECT id FROM FOO_DB.FOO_TABLE WHERE team = 'a'",
+			expectedRows: [][]any{{int64(1)}, {int64(2)}},
+		},
+		{
+			name: "should keep the index up to date on writes",
+			queries: []string{
+				"INSERT INTO FOO_DB.FOO_TABLE (id, name, team) VALUES (4, 'qux', 'a')",
+				"UPDATE FOO_DB.FOO_TABLE SET team = 'b' WHERE id = 1",
+				"DELETE FROM FOO_DB.FOO_TABLE WHERE id = 2",
+			},
+			query:        "SELECT id FROM FOO_DB.FOO_TABLE WHERE team = 'a'",
+			expectedRows: [][]any{{int64(4)}},
+		},
+		{
+			name: "should select rows through a multi column unique index",
+			queries: []string{
+				"CREATE UNIQUE INDEX foo_team_name_idx ON FOO_DB.FOO_TABLE (team, name)",
+			},
+			query:        "SELECT id FROM FOO_DB.FOO_TABLE WHERE name = 'bar' AND team = 'a'",
+			expectedRows: [][]any{{int64(2)}},
+		},
+		{
+			name: "should not insert a repeated value into an unique index",
+			queries: []string{
+				"CREATE UNIQUE INDEX foo_name_idx ON FOO_DB.FOO_TABLE (name)",
+				"INSERT INTO FOO_DB.FOO_TABLE (id, name, team) VALUES (4, 'foo', 'b')",
+			},
+			expectedError: dml.ErrUniqueConstraintViolation,
+		},
+		{
+			name: "should not create an unique index over repeated values",
+			queries: []string{
+				"CREATE UNIQUE INDEX foo_team_unique_idx ON FOO_DB.FOO_TABLE (team)",
+			},
+			expectedError: dml.ErrUniqueConstraintViolation,
+		},
+		{
+			name: "should not create an index with a repeated name",
+			queries: []string{
+				"CREATE INDEX foo_team_idx ON FOO_DB.FOO_TABLE (name)",
+			},
+			expectedError: ddl.ErrIndexAlreadyExists,
+		},
+		{
+			name: "should drop an index",
+			queries: []string{
+				"DROP INDEX foo_team_idx ON FOO_DB.FOO_TABLE",
+				"CREATE INDEX foo_team_idx ON FOO_DB.FOO_TABLE (name)",
+			},
+			query:        "SELECT id FROM FOO_DB.FOO_TABLE WHERE team = 'b'",
+			expectedRows: [][]any{{int64(3)}},
+		},
+		{
+			name: "should not drop an unknown index",
+			queries: []string{
+				"DROP INDEX bar_idx ON FOO_DB.FOO_TABLE",
+			},
+			expectedError: ddl.ErrIndexDoesNotExists,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			engine := testEngineMockEngine(t, setup...)
+			defer engine.Close()
+
+			for _, query := range testCase.queries {
+				if _, err := engine.Exec(query); err != nil {
+					if !errors.Is(err, testCase.expectedError) {
+						t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+					}
+
+					return
+				}
+			}
+
+			if testCase.expectedError != nil {
+				t.Errorf("expected error %v, got nil", testCase.expectedError)
+				return
+			}
+
+			result, err := engine.Query(testCase.query)
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			rows := slices.Clone(result.Rows)
+			slices.SortFunc(rows, func(r1, r2 []any) int {
+				return int(r1[0].(int64) - r2[0].(int64))
+			})
+
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
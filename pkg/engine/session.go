@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gustapinto/go-sql-store/pkg/executor"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/planner"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var (
+	ErrTransactionAlreadyStarted = errors.New("a transaction is already in progress")
+	ErrNoTransaction             = errors.New("there is no transaction in progress")
+)
+
+// maxWriteConflictRetries Limits how many times an autocommit statement is retried after
+// losing a write conflict to a concurrent transaction
+const maxWriteConflictRetries = 64
+
+// Session A connection to the engine. Statements run in autocommit mode until a BEGIN
+// statement starts an explicit transaction, which lasts until COMMIT or ROLLBACK. A
+// Session is not safe for concurrent use, concurrent clients must use one Session each
+type Session struct {
+	engine *Engine
+	tx     *storage.Tx
+}
+
+func (e *Engine) NewSession() *Session {
+	return &Session{engine: e}
+}
+
+func (s *Session) InTransaction() bool {
+	return s.tx != nil
+}
+
+func (s *Session) Exec(query string) (executor.ExecuteResult, error) {
+	statements, err := parser.ParseStatementsIntoAST(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var result executor.ExecuteResult
+	for _, statement := range statements {
+		result, err = s.execStatement(statement)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Session) Query(query string) (*Result, error) {
+	result, err := s.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultFromExecuteResult(result)
+}
+
+// Close Rolls back the transaction in progress, if any
+func (s *Session) Close() error {
+	if s.tx == nil {
+		return nil
+	}
+
+	tx := s.tx
+	s.tx = nil
+
+	return tx.Rollback()
+}
+
+func (s *Session) execStatement(statement *parser.AST) (executor.ExecuteResult, error) {
+	switch statement.Type {
+	case parser.TypeBeginOperation:
+		if s.tx != nil {
+			return nil, ErrTransactionAlreadyStarted
+		}
+
+		s.tx = s.engine.store.Begin()
+		return transactionControlResult(), nil
+
+	case parser.TypeCommitOperation:
+		if s.tx == nil {
+			return nil, ErrNoTransaction
+		}
+
+		tx := s.tx
+		s.tx = nil
+
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+
+		return transactionControlResult(), nil
+
+	case parser.TypeRollbackOperation:
+		if s.tx == nil {
+			return nil, ErrNoTransaction
+		}
+
+		if err := s.Close(); err != nil {
+			return nil, err
+		}
+
+		return transactionControlResult(), nil
+	}
+
+	if s.tx != nil {
+		// A failed statement is undone without aborting the whole transaction
+		savepoint := s.tx.Savepoint()
+
+		result, err := s.engine.runStatement(s.tx, statement)
+		if err != nil {
+			if rollbackErr := s.tx.RollbackTo(savepoint); rollbackErr != nil {
+				return nil, errors.Join(err, rollbackErr)
+			}
+
+			return nil, err
+		}
+
+		return result, nil
+	}
+
+	return s.autocommit(statement)
+}
+
+func (s *Session) autocommit(statement *parser.AST) (result executor.ExecuteResult, err error) {
+	for range maxWriteConflictRetries {
+		tx := s.engine.store.Begin()
+
+		result, err = s.engine.runStatement(tx, statement)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = tx.Commit()
+		if !errors.Is(err, storage.ErrWriteConflict) {
+			break
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (e *Engine) runStatement(tx *storage.Tx, statement *parser.AST) (executor.ExecuteResult, error) {
+	plan, err := planner.Plan(tx, statement, e.planOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return executor.Run(tx, plan, context.Background())
+}
+
+func transactionControlResult() executor.ExecuteResult {
+	return executor.ExecuteResult{"Status": "SUCCESS"}
+}
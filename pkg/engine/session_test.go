@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+func TestSession(t *testing.T) {
+	setup := []string{
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY, name TEXT)",
+		"INSERT INTO FOO_DB.FOO_TABLE (id, name) VALUES (1, 'foo')",
+	}
+
+	testCases := []struct {
+		name          string
+		queries       []string
+		expectedError error
+		expectedRows  [][]any
+	}{
+		{
+			name: "should make committed writes visible",
+			queries: []string{
+				"BEGIN",
+				"INSERT INTO FOO_DB.FOO_TABLE (id, name) VALUES (2, 'bar')",
+				"UPDATE FOO_DB.FOO_TABLE SET name = 'baz' WHERE id = 1",
+				"COMMIT",
+			},
+			expectedRows: [][]any{{int64(1), "baz"}, {int64(2), "bar"}},
+		},
+		{
+			name: "should discard rolled back writes",
+			queries: []string{
+				"BEGIN",
+				"INSERT INTO FOO_DB.FOO_TABLE (id, name) VALUES (2, 'bar')",
+				"DELETE FROM FOO_DB.FOO_TABLE WHERE id = 1",
+				"ROLLBACK",
+			},
+			expectedRows: [][]any{{int64(1), "foo"}},
+		},
+		{
+			name: "should discard catalog writes on rollback",
+			queries: []string{
+				"BEGIN",
+				"DROP TABLE FOO_DB.FOO_TABLE",
+				"ROLLBACK",
+			},
+			expectedRows: [][]any{{int64(1), "foo"}},
+		},
+		{
+			name: "should undo only the failed statement of a transaction",
+			queries: []string{
+				"BEGIN",
+				"INSERT INTO FOO_DB.FOO_TABLE (id, name) VALUES (2, 'bar')",
+				"INSERT INTO FOO_DB.FOO_TABLE (id, name) VALUES (1, 'qux')",
+				"COMMIT",
+			},
+			expectedError: dml.ErrPrimaryKeyAlreadyExists,
+			expectedRows:  [][]any{{int64(1), "foo"}, {int64(2), "bar"}},
+		},
+		{
+			name:          "should return ErrNoTransaction when committing without a transaction",
+			queries:       []string{"COMMIT"},
+			expectedError: ErrNoTransaction,
+			expectedRows:  [][]any{{int64(1), "foo"}},
+		},
+		{
+			name:          "should return ErrTransactionAlreadyStarted when nesting transactions",
+			queries:       []string{"BEGIN", "BEGIN", "ROLLBACK"},
+			expectedError: ErrTransactionAlreadyStarted,
+			expectedRows:  [][]any{{int64(1), "foo"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			engine := testEngineMockEngine(t, setup...)
+			session := engine.NewSession()
+			defer session.Close()
+
+			var err error
+			for _, query := range testCase.queries {
+				if _, queryErr := session.Exec(query); queryErr != nil {
+					err = queryErr
+				}
+			}
+
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if session.InTransaction() {
+				t.Errorf("expected transaction to be closed")
+				return
+			}
+
+			result, err := engine.Query("SELECT * FROM FOO_DB.FOO_TABLE")
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			slices.SortFunc(result.Rows, func(a, b []any) int {
+				return int(a[0].(int64) - b[0].(int64))
+			})
+
+			if !slices.EqualFunc(result.Rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, result.Rows)
+				return
+			}
+		})
+	}
+}
+
+func TestSessionIsolation(t *testing.T) {
+	engine := testEngineMockEngine(
+		t,
+		"CREATE DATABASE FOO_DB",
+		"CREATE TABLE FOO_DB.FOO_TABLE (id INTEGER PRIMARY KEY, name TEXT)",
+		"INSERT INTO FOO_DB.FOO_TABLE (id, name) VALUES (1, 'foo')",
+	)
+
+	s1, s2 := engine.NewSession(), engine.NewSession()
+	defer s1.Close()
+	defer s2.Close()
+
+	if _, err := s1.Exec("BEGIN; UPDATE FOO_DB.FOO_TABLE SET name = 's1' WHERE id = 1"); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	result, err := s2.Query("SELECT name FROM FOO_DB.FOO_TABLE WHERE id = 1")
+	if err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if !slices.EqualFunc(result.Rows, [][]any{{"foo"}}, slices.Equal) {
+		t.Errorf("expected uncommitted writes to be invisible, got %v", result.Rows)
+		return
+	}
+
+	if _, err := s2.Exec("BEGIN; UPDATE FOO_DB.FOO_TABLE SET name = 's2' WHERE id = 1; COMMIT"); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if _, err := s1.Exec("COMMIT"); !errors.Is(err, storage.ErrWriteConflict) {
+		t.Errorf("expected error %v, got %v", storage.ErrWriteConflict, err)
+		return
+	}
+
+	result, err = engine.Query("SELECT name FROM FOO_DB.FOO_TABLE WHERE id = 1")
+	if err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if !slices.EqualFunc(result.Rows, [][]any{{"s2"}}, slices.Equal) {
+		t.Errorf("expected the first committed write to win, got %v", result.Rows)
+		return
+	}
+}
@@ -3,8 +3,8 @@ package executor
 import (
 	"context"
 
-	gokvstore "github.com/gustapinto/go-kv-store"
 	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 )
 
 var (
@@ -13,12 +13,16 @@ var (
 	CreateDatabaseParamsCreateOrReplace   ctxKey = "CREATE_DATABASE_PARAMS_CREATE_OR_REPLACE"
 	CreateDatabaseParamsCreateIfNotExists ctxKey = "CREATE_DATABASE_PARAMS_CREATE_IF_NOT_EXISTS"
 	CreateDatabaseResponse                ctxKey = "CREATE_DATABASE_RESPONSE"
+
+	DropDatabaseID                           = "DROP_DATABASE"
+	DropDatabaseParamsDatabaseNameKey ctxKey = "DROP_DATABASE_PARAMS_DATABASE_NAME"
+	DropDatabaseResponseKey           ctxKey = "DROP_DATABASE_RESPONSE"
 )
 
 func CreateDatabaseAction() Action {
 	return Action{
 		ID: CreateDatabaseID,
-		Execute: func(rootCollection *gokvstore.Collection, in context.Context) (context.Context, ExecuteResult, error) {
+		Execute: func(tx *storage.Tx, in context.Context) (context.Context, ExecuteResult, error) {
 			name, ok := in.Value(CreateDatabaseParamsDatabaseName).(string)
 			if !ok {
 				return in, nil, valueMissingOrWithWrongTypeError(CreateDatabaseParamsDatabaseName)
@@ -36,7 +40,25 @@ func CreateDatabaseAction() Action {
 
 			database := ddl.Database{Name: name}
 
-			if err := ddl.CreateDatabase(nil, database, createOrReplace, createIfNotExists); err != nil {
+			if err := ddl.CreateDatabase(tx, database, createOrReplace, createIfNotExists); err != nil {
+				return in, nil, err
+			}
+
+			return in, successExecutionResult(), nil
+		},
+	}
+}
+
+func DropDatabaseAction() Action {
+	return Action{
+		ID: DropDatabaseID,
+		Execute: func(tx *storage.Tx, in context.Context) (context.Context, ExecuteResult, error) {
+			name, ok := in.Value(DropDatabaseParamsDatabaseNameKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(DropDatabaseParamsDatabaseNameKey)
+			}
+
+			if err := ddl.DropDatabase(tx, name); err != nil {
 				return in, nil, err
 			}
 
@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var (
+	DeleteID                        = "DELETE"
+	DeleteParamsDatabaseKey  ctxKey = "DELETE_PARAMS_DATABASE"
+	DeleteParamsTableNameKey ctxKey = "DELETE_PARAMS_TABLE_NAME"
+	DeleteParamsWhereKey     ctxKey = "DELETE_PARAMS_WHERE"
+	DeleteResponseKey        ctxKey = "DELETE_RESPONSE"
+)
+
+func DeleteAction() Action {
+	return Action{
+		ID: DeleteID,
+		Execute: func(tx *storage.Tx, in context.Context) (context.Context, ExecuteResult, error) {
+			database, ok := in.Value(DeleteParamsDatabaseKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(DeleteParamsDatabaseKey)
+			}
+
+			tableName, ok := in.Value(DeleteParamsTableNameKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(DeleteParamsTableNameKey)
+			}
+
+			where, ok := in.Value(DeleteParamsWhereKey).(dql.Expression)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(DeleteParamsWhereKey)
+			}
+
+			rows, err := dql.SelectWhere(tx, database, tableName, where)
+			if err != nil {
+				return in, nil, err
+			}
+
+			affectedRows := 0
+			for _, row := range rows {
+				if err := dml.Delete(tx, row); err != nil {
+					return in, nil, err
+				}
+
+				affectedRows++
+			}
+
+			return in, affectedRowsExecutionResult(affectedRows), nil
+		},
+	}
+}
@@ -5,14 +5,23 @@ import (
 	"fmt"
 	"log/slog"
 
-	gokvstore "github.com/gustapinto/go-kv-store"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 )
 
 type ctxKey string
 
+type Params map[ctxKey]any
+
 type Action struct {
 	ID      string
-	Execute func(rootCollection *gokvstore.Collection, in context.Context) (out context.Context, result ExecuteResult, err error)
+	Params  Params
+	Execute func(tx *storage.Tx, in context.Context) (out context.Context, result ExecuteResult, err error)
+}
+
+func (a Action) WithParams(params Params) Action {
+	a.Params = params
+	return a
 }
 
 type ExecutionPlan struct {
@@ -26,6 +35,19 @@ func successExecutionResult() ExecuteResult {
 	return ExecuteResult{"Status": "SUCCESS"}
 }
 
+func affectedRowsExecutionResult(affectedRows int) ExecuteResult {
+	return ExecuteResult{"Status": "SUCCESS", "AffectedRows": affectedRows}
+}
+
+func selectExecutionResult(resultSet dql.ResultSet) ExecuteResult {
+	return ExecuteResult{
+		"Status":      "SUCCESS",
+		"Columns":     resultSet.ColumnNames(),
+		"ColumnTypes": resultSet.ColumnDataTypes(),
+		"Rows":        resultSet.Rows,
+	}
+}
+
 func errorExecutionResult(err error) ExecuteResult {
 	return ExecuteResult{"Status": "ERROR", "Error": err.Error()}
 }
@@ -39,7 +61,7 @@ func valueMissingOrWithWrongTypeError(key ctxKey) error {
 	return fmt.Errorf("value missing or with wrong type %s", string(key))
 }
 
-func Execute(rootCollection *gokvstore.Collection, plan ExecutionPlan, ctx context.Context) ExecuteResult {
+func Run(tx *storage.Tx, plan ExecutionPlan, ctx context.Context) (ExecuteResult, error) {
 	logger := slog.Default().With("actionPlan.ID", plan.ID)
 	ctx = context.WithValue(ctx, ExecutionIDKey, plan.ID)
 
@@ -47,10 +69,14 @@ func Execute(rootCollection *gokvstore.Collection, plan ExecutionPlan, ctx conte
 	for i, action := range plan.Actions {
 		logger.Info("Executing", "action.Index", i, "action.ID", action.ID, "execution.Status", "Started")
 
-		out, res, err := action.Execute(rootCollection, ctx)
+		for key, value := range action.Params {
+			ctx = context.WithValue(ctx, key, value)
+		}
+
+		out, res, err := action.Execute(tx, ctx)
 		if err != nil {
 			logger.Info("Executing", "action.Index", i, "action.ID", action.ID, "execution.Status", "Failed", "error", err.Error())
-			return errorExecutionResult(err)
+			return nil, err
 		}
 		ctx = out
 		lastResult = res
@@ -58,5 +84,32 @@ func Execute(rootCollection *gokvstore.Collection, plan ExecutionPlan, ctx conte
 		logger.Info("Executing", "action.Index", i, "action.ID", action.ID, "execution.Status", "Success")
 	}
 
-	return lastResult
+	return lastResult, nil
+}
+
+func Execute(tx *storage.Tx, plan ExecutionPlan, ctx context.Context) ExecuteResult {
+	result, err := Run(tx, plan, ctx)
+	if err != nil {
+		return errorExecutionResult(err)
+	}
+
+	return result
+}
+
+// RunInTransaction Runs the plan in a new transaction, committing it if every action succeeds
+// and rolling it back otherwise
+func RunInTransaction(store *storage.Storage, plan ExecutionPlan, ctx context.Context) (ExecuteResult, error) {
+	tx := store.Begin()
+
+	result, err := Run(tx, plan, ctx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var (
+	CreateIndexID                        = "CREATE_INDEX"
+	CreateIndexParamsDatabaseKey  ctxKey = "CREATE_INDEX_PARAMS_DATABASE"
+	CreateIndexParamsTableNameKey ctxKey = "CREATE_INDEX_PARAMS_TABLE_NAME"
+	CreateIndexParamsIndexKey     ctxKey = "CREATE_INDEX_PARAMS_INDEX"
+
+	DropIndexID                        = "DROP_INDEX"
+	DropIndexParamsDatabaseKey  ctxKey = "DROP_INDEX_PARAMS_DATABASE"
+	DropIndexParamsTableNameKey ctxKey = "DROP_INDEX_PARAMS_TABLE_NAME"
+	DropIndexParamsIndexNameKey ctxKey = "DROP_INDEX_PARAMS_INDEX_NAME"
+)
+
+func CreateIndexAction() Action {
+	return Action{
+		ID: CreateIndexID,
+		Execute: func(tx *storage.Tx, in context.Context) (context.Context, ExecuteResult, error) {
+			database, ok := in.Value(CreateIndexParamsDatabaseKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(CreateIndexParamsDatabaseKey)
+			}
+
+			tableName, ok := in.Value(CreateIndexParamsTableNameKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(CreateIndexParamsTableNameKey)
+			}
+
+			index, ok := in.Value(CreateIndexParamsIndexKey).(ddl.Index)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(CreateIndexParamsIndexKey)
+			}
+
+			if err := ddl.CreateIndex(tx, database, tableName, index); err != nil {
+				return in, nil, err
+			}
+
+			if err := dml.BuildIndex(tx, database, tableName, index); err != nil {
+				return in, nil, err
+			}
+
+			return in, successExecutionResult(), nil
+		},
+	}
+}
+
+func DropIndexAction() Action {
+	return Action{
+		ID: DropIndexID,
+		Execute: func(tx *storage.Tx, in context.Context) (context.Context, ExecuteResult, error) {
+			database, ok := in.Value(DropIndexParamsDatabaseKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(DropIndexParamsDatabaseKey)
+			}
+
+			tableName, ok := in.Value(DropIndexParamsTableNameKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(DropIndexParamsTableNameKey)
+			}
+
+			indexName, ok := in.Value(DropIndexParamsIndexNameKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(DropIndexParamsIndexNameKey)
+			}
+
+			if err := ddl.DropIndex(tx, database, tableName, indexName); err != nil {
+				return in, nil, err
+			}
+
+			if err := dml.DropIndexEntries(tx, database, tableName, indexName); err != nil {
+				return in, nil, err
+			}
+
+			return in, successExecutionResult(), nil
+		},
+	}
+}
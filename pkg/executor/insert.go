@@ -0,0 +1,32 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var (
+	InsertID                  = "INSERT"
+	InsertParamsRowKey ctxKey = "INSERT_PARAMS_ROW"
+	InsertResponseKey  ctxKey = "INSERT_RESPONSE"
+)
+
+func InsertAction() Action {
+	return Action{
+		ID: InsertID,
+		Execute: func(tx *storage.Tx, in context.Context) (context.Context, ExecuteResult, error) {
+			row, ok := in.Value(InsertParamsRowKey).(dml.Row)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(InsertParamsRowKey)
+			}
+
+			if err := dml.Insert(tx, row); err != nil {
+				return in, nil, err
+			}
+
+			return in, affectedRowsExecutionResult(1), nil
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var (
+	QueryID                    = "QUERY"
+	QueryParamsQueryKey ctxKey = "QUERY_PARAMS_QUERY"
+)
+
+// QueryAction Runs a query composed of other queries, as set operations between selects
+func QueryAction() Action {
+	return Action{
+		ID: QueryID,
+		Execute: func(tx *storage.Tx, in context.Context) (context.Context, ExecuteResult, error) {
+			query, ok := in.Value(QueryParamsQueryKey).(dql.Query)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(QueryParamsQueryKey)
+			}
+
+			resultSet, err := query.Run(tx)
+			if err != nil {
+				return in, nil, err
+			}
+
+			return in, selectExecutionResult(resultSet), nil
+		},
+	}
+}
@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var (
+	SelectID                          = "SELECT"
+	SelectParamsWithKey        ctxKey = "SELECT_PARAMS_WITH"
+	SelectParamsDatabaseKey    ctxKey = "SELECT_PARAMS_DATABASE"
+	SelectParamsTableNameKey   ctxKey = "SELECT_PARAMS_TABLE_NAME"
+	SelectParamsSourceKey      ctxKey = "SELECT_PARAMS_SOURCE"
+	SelectParamsAliasKey       ctxKey = "SELECT_PARAMS_ALIAS"
+	SelectParamsJoinsKey       ctxKey = "SELECT_PARAMS_JOINS"
+	SelectParamsWhereKey       ctxKey = "SELECT_PARAMS_WHERE"
+	SelectParamsProjectionsKey ctxKey = "SELECT_PARAMS_PROJECTIONS"
+	SelectParamsAggregationKey ctxKey = "SELECT_PARAMS_AGGREGATION"
+	SelectParamsWindowsKey     ctxKey = "SELECT_PARAMS_WINDOWS"
+	SelectParamsDistinctKey    ctxKey = "SELECT_PARAMS_DISTINCT"
+	SelectParamsOrdersKey      ctxKey = "SELECT_PARAMS_ORDERS"
+	SelectParamsLimitKey       ctxKey = "SELECT_PARAMS_LIMIT"
+	SelectParamsOffsetKey      ctxKey = "SELECT_PARAMS_OFFSET"
+	SelectResponseKey          ctxKey = "SELECT_RESPONSE"
+)
+
+func SelectAction() Action {
+	return Action{
+		ID: SelectID,
+		Execute: func(tx *storage.Tx, in context.Context) (context.Context, ExecuteResult, error) {
+			database, ok := in.Value(SelectParamsDatabaseKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsDatabaseKey)
+			}
+
+			tableName, ok := in.Value(SelectParamsTableNameKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsTableNameKey)
+			}
+
+			with, ok := in.Value(SelectParamsWithKey).([]*dql.CommonTable)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsWithKey)
+			}
+
+			source, ok := in.Value(SelectParamsSourceKey).(*dql.CommonTable)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsSourceKey)
+			}
+
+			alias, ok := in.Value(SelectParamsAliasKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsAliasKey)
+			}
+
+			joins, ok := in.Value(SelectParamsJoinsKey).([]dql.Join)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsJoinsKey)
+			}
+
+			where, ok := in.Value(SelectParamsWhereKey).(dql.Expression)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsWhereKey)
+			}
+
+			projections, ok := in.Value(SelectParamsProjectionsKey).([]dql.Projection)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsProjectionsKey)
+			}
+
+			aggregation, ok := in.Value(SelectParamsAggregationKey).(*dql.Aggregation)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsAggregationKey)
+			}
+
+			windows, ok := in.Value(SelectParamsWindowsKey).([]dql.Window)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsWindowsKey)
+			}
+
+			orders, ok := in.Value(SelectParamsOrdersKey).([]dql.Order)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsOrdersKey)
+			}
+
+			limit, ok := in.Value(SelectParamsLimitKey).(int)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsLimitKey)
+			}
+
+			offset, ok := in.Value(SelectParamsOffsetKey).(int)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsOffsetKey)
+			}
+
+			distinct, ok := in.Value(SelectParamsDistinctKey).(bool)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(SelectParamsDistinctKey)
+			}
+
+			resultSet, err := dql.SelectQuery{
+				With:        with,
+				Database:    database,
+				Table:       tableName,
+				Source:      source,
+				Alias:       alias,
+				Joins:       joins,
+				Where:       where,
+				Aggregation: aggregation,
+				Windows:     windows,
+				Projections: projections,
+				Distinct:    distinct,
+				Orders:      orders,
+				Limit:       limit,
+				Offset:      offset,
+			}.Run(tx)
+			if err != nil {
+				return in, nil, err
+			}
+
+			return in, selectExecutionResult(resultSet), nil
+		},
+	}
+}
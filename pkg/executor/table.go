@@ -3,8 +3,8 @@ package executor
 import (
 	"context"
 
-	gokvstore "github.com/gustapinto/go-kv-store"
 	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 )
 
 var (
@@ -23,7 +23,7 @@ var (
 func CreateTableAction() Action {
 	return Action{
 		ID: CreateTableID,
-		Execute: func(rootCollection *gokvstore.Collection, in context.Context) (out context.Context, res ExecuteResult, err error) {
+		Execute: func(tx *storage.Tx, in context.Context) (out context.Context, res ExecuteResult, err error) {
 			table, ok := in.Value(CreateTableParamsTableKey).(ddl.Table)
 			if !ok {
 				return in, nil, valueMissingOrWithWrongTypeError(CreateTableParamsTableKey)
@@ -39,7 +39,7 @@ func CreateTableAction() Action {
 				return in, nil, valueMissingOrWithWrongTypeError(CreateTableParamsCreateIfNotExistsKey)
 			}
 
-			if err := ddl.CreateTable(nil, table, createOrReplace, createIfNotExists); err != nil {
+			if err := ddl.CreateTable(tx, table, createOrReplace, createIfNotExists); err != nil {
 				return in, nil, err
 			}
 
@@ -50,8 +50,8 @@ func CreateTableAction() Action {
 
 func DropTableAction() Action {
 	return Action{
-		ID: CreateTableID,
-		Execute: func(rootCollection *gokvstore.Collection, in context.Context) (context.Context, ExecuteResult, error) {
+		ID: DropTableID,
+		Execute: func(tx *storage.Tx, in context.Context) (context.Context, ExecuteResult, error) {
 			database, ok := in.Value(DropTableParamsDatabaseKey).(string)
 			if !ok {
 				return in, nil, valueMissingOrWithWrongTypeError(DropTableParamsDatabaseKey)
@@ -62,7 +62,7 @@ func DropTableAction() Action {
 				return in, nil, valueMissingOrWithWrongTypeError(DropTableParamsTableNameKey)
 			}
 
-			if err := ddl.DropTable(nil, database, tableName); err != nil {
+			if err := ddl.DropTable(tx, database, tableName); err != nil {
 				return in, nil, err
 			}
 
@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var (
+	UpdateID                        = "UPDATE"
+	UpdateParamsDatabaseKey  ctxKey = "UPDATE_PARAMS_DATABASE"
+	UpdateParamsTableNameKey ctxKey = "UPDATE_PARAMS_TABLE_NAME"
+	UpdateParamsWhereKey     ctxKey = "UPDATE_PARAMS_WHERE"
+	UpdateParamsColumnsKey   ctxKey = "UPDATE_PARAMS_COLUMNS"
+	UpdateResponseKey        ctxKey = "UPDATE_RESPONSE"
+)
+
+func UpdateAction() Action {
+	return Action{
+		ID: UpdateID,
+		Execute: func(tx *storage.Tx, in context.Context) (context.Context, ExecuteResult, error) {
+			database, ok := in.Value(UpdateParamsDatabaseKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(UpdateParamsDatabaseKey)
+			}
+
+			tableName, ok := in.Value(UpdateParamsTableNameKey).(string)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(UpdateParamsTableNameKey)
+			}
+
+			where, ok := in.Value(UpdateParamsWhereKey).(dql.Expression)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(UpdateParamsWhereKey)
+			}
+
+			columns, ok := in.Value(UpdateParamsColumnsKey).(map[string]dql.Expression)
+			if !ok {
+				return in, nil, valueMissingOrWithWrongTypeError(UpdateParamsColumnsKey)
+			}
+
+			rows, err := dql.SelectWhere(tx, database, tableName, where)
+			if err != nil {
+				return in, nil, err
+			}
+
+			affectedRows := 0
+			for _, row := range rows {
+				values := make(map[string]any, len(columns))
+				for name, expression := range columns {
+					if values[name], err = expression.Evaluate(row); err != nil {
+						return in, nil, err
+					}
+				}
+
+				updated, err := dml.Update(tx, row, values)
+				if err != nil {
+					return in, nil, err
+				}
+
+				if updated {
+					affectedRows++
+				}
+			}
+
+			return in, affectedRowsExecutionResult(affectedRows), nil
+		},
+	}
+}
@@ -1,12 +1,21 @@
 package ddl
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"slices"
+	"time"
 
 	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
 )
 
+var (
+	ErrValueTypeMismatch = errors.New("value does not have the type of the column")
+	ErrValueOutOfRange   = errors.New("value out of range for the type of the column")
+)
+
 type ColumnDataType string
 
 type ConstraintDataType string
@@ -31,6 +40,7 @@ const (
 
 	ConstraintPrimaryKey ConstraintDataType = "PRIMARY_KEY"
 	ConstraintUnique     ConstraintDataType = "UNIQUE"
+	ConstraintNotNull    ConstraintDataType = "NOT_NULL"
 )
 
 func AreConstraintsEqual(c1, c2 Constraint) bool {
@@ -61,21 +71,117 @@ func ColumnIsPrimaryKey(column Column) bool {
 	return false
 }
 
+// ColumnIsNotNull Checks if the column can not hold NULL values, as primary keys and
+// NOT NULL columns
+func ColumnIsNotNull(column Column) bool {
+	return slices.ContainsFunc(column.Constraints, func(constraint Constraint) bool {
+		return constraint.Type == ConstraintPrimaryKey || constraint.Type == ConstraintNotNull
+	})
+}
+
+func IsColumnDataTypeSupported(dataType ColumnDataType) bool {
+	switch dataType {
+	case ColumnDataTypeText, ColumnDataTypeFloat, ColumnDataTypeInteger, ColumnDataTypeTimestamp:
+		return true
+	}
+
+	return false
+}
+
+// ValueHasCorrectTypeForColumn Checks if the value is not NULL and can be a value of the
+// column, see [ValueForDataType]
 func ValueHasCorrectTypeForColumn(value any, column Column) bool {
-	switch column.DataType {
+	_, err := ValueForDataType(value, column.DataType)
+	return value != nil && err == nil
+}
+
+// maxExactFloatInteger The largest integer up to which every integer is exactly a float64
+const maxExactFloatInteger = 1 << 53
+
+// ValueForDataType Returns the value as a value of the type, which is an int64 for INTEGER,
+// from any Go integer that fits in it, a float64 for FLOAT, from any Go float or any integer
+// it represents exactly, a string for TEXT and a time.Time for TIMESTAMP. NULL is nil for
+// every type
+func ValueForDataType(value any, dataType ColumnDataType) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch dataType {
 	case ColumnDataTypeText:
-		_, ok := value.(string)
-		return ok
+		if text, isText := value.(string); isText {
+			return text, nil
+		}
+
+	case ColumnDataTypeInteger:
+		if integer, isInteger, err := integerValue(value); isInteger {
+			return integer, err
+		}
 
 	case ColumnDataTypeFloat:
-		_, ok := value.(float64)
-		return ok
+		switch number := value.(type) {
+		case float64:
+			return number, nil
+
+		case float32:
+			return float64(number), nil
+		}
+
+		if integer, isInteger, err := integerValue(value); isInteger {
+			if err == nil && (integer > maxExactFloatInteger || integer < -maxExactFloatInteger) {
+				err = fmt.Errorf("%w: %d can not be exactly a %s", ErrValueOutOfRange, integer, dataType)
+			}
+
+			return float64(integer), err
+		}
 
-	case ColumnDataTypeInteger:
 	case ColumnDataTypeTimestamp:
-		_, ok := value.(int64)
-		return ok
+		if timestamp, isTimestamp := value.(time.Time); isTimestamp {
+			// Drops the monotonic clock reading, which only makes sense in the running process
+			return timestamp.Round(0), nil
+		}
 	}
 
-	return false
+	return nil, fmt.Errorf("%w: %T is not a %s value", ErrValueTypeMismatch, value, dataType)
+}
+
+// integerValue Returns the value as an int64 if it is a Go integer, failing with
+// ErrValueOutOfRange for unsigned integers greater than the greatest int64
+func integerValue(value any) (int64, bool, error) {
+	number := reflect.ValueOf(value)
+	switch {
+	case number.CanInt():
+		return number.Int(), true, nil
+
+	case number.CanUint():
+		if number.Uint() > math.MaxInt64 {
+			return 0, true, fmt.Errorf("%w: %d is greater than the greatest %s", ErrValueOutOfRange, number.Uint(), ColumnDataTypeInteger)
+		}
+
+		return int64(number.Uint()), true, nil
+	}
+
+	return 0, false, nil
+}
+
+// timestampLayouts The layouts of TIMESTAMP texts, which are in UTC when they have no zone
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	time.DateOnly,
+}
+
+// ParseTimestamp Parses a TIMESTAMP text, as 2006-01-02, 2006-01-02 15:04:05 or an RFC 3339
+// timestamp, optionally with fractional seconds up to nanoseconds and a zone offset. The
+// offset of the text is kept, texts without one being in UTC
+func ParseTimestamp(text string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if timestamp, err := time.Parse(layout, text); err == nil {
+			return timestamp, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q is not a %s value", ErrValueTypeMismatch, text, ColumnDataTypeTimestamp)
 }
@@ -1,7 +1,10 @@
 package ddl
 
 import (
+	"errors"
+	"math"
 	"testing"
+	"time"
 )
 
 func TestAreConstraintsEqual(t *testing.T) {
@@ -209,6 +212,51 @@ func TestColumnIsKey(t *testing.T) {
 	}
 }
 
+func TestColumnIsNotNull(t *testing.T) {
+	testCases := []struct {
+		name          string
+		column        Column
+		expectedValue bool
+	}{
+		{
+			name: "should be false for column with UNIQUE constraints",
+			column: Column{
+				Name:        "name",
+				DataType:    ColumnDataTypeText,
+				Constraints: []Constraint{{Type: ConstraintUnique, Name: "name_unique"}},
+			},
+			expectedValue: false,
+		},
+		{
+			name: "should be true for column with NOT NULL constraints",
+			column: Column{
+				Name:        "name",
+				DataType:    ColumnDataTypeText,
+				Constraints: []Constraint{{Type: ConstraintNotNull, Name: "name_not_null"}},
+			},
+			expectedValue: true,
+		},
+		{
+			name: "should be true for column with PRIMARY KEY constraints",
+			column: Column{
+				Name:        "id",
+				DataType:    ColumnDataTypeInteger,
+				Constraints: []Constraint{{Type: ConstraintPrimaryKey, Name: "id_pkey"}},
+			},
+			expectedValue: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if value := ColumnIsNotNull(testCase.column); value != testCase.expectedValue {
+				t.Errorf("expected value %v, got %v", testCase.expectedValue, value)
+				return
+			}
+		})
+	}
+}
+
 func TestValueHasCorrectTypeForColumn(t *testing.T) {
 	var testCases = []struct {
 		name          string
@@ -234,6 +282,87 @@ func TestValueHasCorrectTypeForColumn(t *testing.T) {
 			},
 			expectedValue: false,
 		},
+		{
+			name:  "should return true for int64 value and ColumnDataTypeInteger column",
+			value: int64(123),
+			column: Column{
+				Name:     "id",
+				DataType: ColumnDataTypeInteger,
+			},
+			expectedValue: true,
+		},
+		{
+			name:  "should return true for any Go integer and ColumnDataTypeInteger column",
+			value: int16(123),
+			column: Column{
+				Name:     "id",
+				DataType: ColumnDataTypeInteger,
+			},
+			expectedValue: true,
+		},
+		{
+			name:  "should return false for unsigned integers out of range and ColumnDataTypeInteger column",
+			value: uint64(math.MaxUint64),
+			column: Column{
+				Name:     "id",
+				DataType: ColumnDataTypeInteger,
+			},
+			expectedValue: false,
+		},
+		{
+			name:  "should return false for float64 value and ColumnDataTypeInteger column",
+			value: float64(1),
+			column: Column{
+				Name:     "id",
+				DataType: ColumnDataTypeInteger,
+			},
+			expectedValue: false,
+		},
+		{
+			name:  "should return true for integers exactly represented by floats and ColumnDataTypeFloat column",
+			value: int64(1 << 53),
+			column: Column{
+				Name:     "price",
+				DataType: ColumnDataTypeFloat,
+			},
+			expectedValue: true,
+		},
+		{
+			name:  "should return false for integers not exactly represented by floats and ColumnDataTypeFloat column",
+			value: int64(1<<53 + 1),
+			column: Column{
+				Name:     "price",
+				DataType: ColumnDataTypeFloat,
+			},
+			expectedValue: false,
+		},
+		{
+			name:  "should return true for time.Time value and ColumnDataTypeTimestamp column",
+			value: time.Now(),
+			column: Column{
+				Name:     "created_at",
+				DataType: ColumnDataTypeTimestamp,
+			},
+			expectedValue: true,
+		},
+		{
+			name:  "should return false for int64 value and ColumnDataTypeTimestamp column",
+			value: time.Now().UnixMilli(),
+			column: Column{
+				Name:     "created_at",
+				DataType: ColumnDataTypeTimestamp,
+			},
+			expectedValue: false,
+		},
+		{
+			name:  "should return false for NULL",
+			value: nil,
+			column: Column{
+				Name:     "name",
+				DataType: ColumnDataTypeText,
+			},
+			expectedValue: false,
+		},
 		{
 			name:  "should return false for invalid column type",
 			value: "Foo",
@@ -254,3 +383,48 @@ func TestValueHasCorrectTypeForColumn(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTimestamp(t *testing.T) {
+	testCases := []struct {
+		name          string
+		text          string
+		expectedValue time.Time
+		expectedError error
+	}{
+		{
+			name:          "should parse dates in UTC",
+			text:          "2024-02-29",
+			expectedValue: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "should parse nanoseconds",
+			text:          "2024-02-29 13:45:30.123456789",
+			expectedValue: time.Date(2024, time.February, 29, 13, 45, 30, 123456789, time.UTC),
+		},
+		{
+			name:          "should keep zone offsets",
+			text:          "2024-02-29T13:45:30-03:00",
+			expectedValue: time.Date(2024, time.February, 29, 13, 45, 30, 0, time.FixedZone("", -3*60*60)),
+		},
+		{
+			name:          "should return ErrValueTypeMismatch for texts that are not timestamps",
+			text:          "29/02/2024",
+			expectedError: ErrValueTypeMismatch,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			value, err := ParseTimestamp(testCase.text)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if !value.Equal(testCase.expectedValue) || value.Format(time.RFC3339Nano) != testCase.expectedValue.Format(time.RFC3339Nano) {
+				t.Errorf("expected value %v, got %v", testCase.expectedValue, value)
+				return
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	gokvstore "github.com/gustapinto/go-kv-store"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
 )
 
@@ -16,8 +17,6 @@ type Database struct {
 var (
 	ErrDatabaseDoesNotExists = errors.New("database does not exist")
 	ErrDatabaseAlreadyExists = errors.New("database already exists")
-
-	databaseCollectionsCache = map[string]*gokvstore.Collection{}
 )
 
 func databaseDataDir(dd Database) string {
@@ -28,32 +27,9 @@ func databaseDataDir(dd Database) string {
 	return builder.String()
 }
 
-func DatabaseCollection(rootCollection *gokvstore.Collection, database Database) (*gokvstore.Collection, error) {
-	if databaseCollectionsCache == nil {
-		databaseCollectionsCache = map[string]*gokvstore.Collection{}
-	}
-
-	if collection, exists := databaseCollectionsCache[database.Name]; exists {
-		return collection, nil
-	}
-
-	newCollection, err := rootCollection.NewCollection(databaseDataDir(database))
-	if err != nil {
-		return nil, err
-	}
-
-	databaseCollectionsCache[database.Name] = newCollection
-	return newCollection, nil
-}
-
-func putDatabase(rootCollection *gokvstore.Collection, database Database, replace bool) error {
-	databaseCollection, err := DatabaseCollection(rootCollection, database)
-	if err != nil {
-		return err
-	}
-
+func putDatabase(tx *storage.Tx, database Database, replace bool) error {
 	if replace {
-		if err := databaseCollection.Truncate(); err != nil {
+		if err := tx.Truncate(databaseDataDir(database)); err != nil {
 			return err
 		}
 	}
@@ -63,20 +39,15 @@ func putDatabase(rootCollection *gokvstore.Collection, database Database, replac
 		return err
 	}
 
-	if err := databaseCollection.Put(database.Name, databaseBuffer, true); err != nil {
+	if err := tx.Put(databaseDataDir(database), database.Name, databaseBuffer, true); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func GetDatabase(rootCollection *gokvstore.Collection, databaseName string) (*Database, error) {
-	databaseCollection, err := DatabaseCollection(rootCollection, Database{Name: databaseName})
-	if err != nil {
-		return nil, err
-	}
-
-	databaseBuffer, err := databaseCollection.Get(databaseName)
+func GetDatabase(tx *storage.Tx, databaseName string) (*Database, error) {
+	databaseBuffer, err := tx.Get(databaseDataDir(Database{Name: databaseName}), databaseName)
 	if err != nil {
 		if errors.Is(err, gokvstore.ErrKeyNotFound) {
 			return nil, ErrDatabaseDoesNotExists
@@ -93,31 +64,30 @@ func GetDatabase(rootCollection *gokvstore.Collection, databaseName string) (*Da
 	return &database, nil
 }
 
-func DatabaseExists(rootCollection *gokvstore.Collection, database Database) (bool, error) {
-	databaseCollection, err := DatabaseCollection(rootCollection, database)
-	if err != nil {
-		return false, err
-	}
-
-	return databaseCollection.Exists(database.Name), nil
+func DatabaseExists(tx *storage.Tx, database Database) (bool, error) {
+	return tx.Exists(databaseDataDir(database), database.Name)
 }
 
-func CreateDatabase(rootCollection *gokvstore.Collection, database Database, createOrReplace, createIfNotExists bool) error {
-	exists, err := DatabaseExists(rootCollection, database)
+func CreateDatabase(tx *storage.Tx, database Database, createOrReplace, createIfNotExists bool) error {
+	exists, err := DatabaseExists(tx, database)
 	if err != nil {
 		return err
 	}
 
-	canIgnoreIfExists := createOrReplace || createIfNotExists
-	if exists && !canIgnoreIfExists {
+	if exists && !createOrReplace {
+		if createIfNotExists {
+			// The existing definition is kept as is, it is only replaced by OR REPLACE
+			return nil
+		}
+
 		return ErrDatabaseAlreadyExists
 	}
 
-	return putDatabase(rootCollection, database, createOrReplace)
+	return putDatabase(tx, database, createOrReplace)
 }
 
-func AlterDatabase(rootCollection *gokvstore.Collection, database Database) error {
-	exists, err := DatabaseExists(rootCollection, database)
+func AlterDatabase(tx *storage.Tx, database Database) error {
+	exists, err := DatabaseExists(tx, database)
 	if err != nil {
 		return err
 	}
@@ -126,11 +96,11 @@ func AlterDatabase(rootCollection *gokvstore.Collection, database Database) erro
 		return ErrDatabaseDoesNotExists
 	}
 
-	return putDatabase(rootCollection, database, false)
+	return putDatabase(tx, database, false)
 }
 
-func DropDatabase(rootCollection *gokvstore.Collection, name string) error {
-	exists, err := DatabaseExists(rootCollection, Database{Name: name})
+func DropDatabase(tx *storage.Tx, name string) error {
+	exists, err := DatabaseExists(tx, Database{Name: name})
 	if err != nil {
 		return err
 	}
@@ -139,10 +109,5 @@ func DropDatabase(rootCollection *gokvstore.Collection, name string) error {
 		return ErrDatabaseDoesNotExists
 	}
 
-	databaseCollection, err := DatabaseCollection(rootCollection, Database{Name: name})
-	if err != nil {
-		return err
-	}
-
-	return databaseCollection.Truncate()
+	return tx.Truncate(databaseDataDir(Database{Name: name}))
 }
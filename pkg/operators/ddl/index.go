@@ -0,0 +1,86 @@
+package ddl
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
+)
+
+var (
+	ErrIndexDoesNotExists = errors.New("index does not exists")
+	ErrIndexAlreadyExists = errors.New("index already exists")
+)
+
+// Index A lookup structure over some columns of a table, mapping their values to the
+// primary keys of the rows holding them
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// ConstraintIndexes Returns the indexes backing the PRIMARY KEY and UNIQUE constraints of
+// the columns, the primary key index keeps the rows ordered by it
+func ConstraintIndexes(columns []Column) []Index {
+	var indexes []Index
+	for _, column := range columns {
+		for _, constraint := range column.Constraints {
+			if constraint.Type != ConstraintPrimaryKey && constraint.Type != ConstraintUnique {
+				continue
+			}
+
+			indexes = append(indexes, Index{
+				Name:    constraint.Name,
+				Columns: []string{column.Name},
+				Unique:  true,
+			})
+		}
+	}
+
+	return indexes
+}
+
+// TableIndexes Returns every index of the table, the ones backing its constraints followed
+// by the ones created with CREATE INDEX
+func TableIndexes(table Table) []Index {
+	return append(ConstraintIndexes(table.Columns), table.Indexes...)
+}
+
+func CreateIndex(tx *storage.Tx, database, tableName string, index Index) error {
+	table, err := GetTable(tx, database, tableName)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range TableIndexes(*table) {
+		if stringutils.EqualsIgnoreCase(existing.Name, index.Name) {
+			return ErrIndexAlreadyExists
+		}
+	}
+
+	table.Indexes = append(table.Indexes, index)
+
+	return AlterTable(tx, *table)
+}
+
+// DropIndex Removes an index created with CREATE INDEX from the table definition, the
+// indexes backing constraints can only be dropped with the table
+func DropIndex(tx *storage.Tx, database, tableName, name string) error {
+	table, err := GetTable(tx, database, tableName)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(table.Indexes, func(index Index) bool {
+		return stringutils.EqualsIgnoreCase(index.Name, name)
+	})
+	if i == -1 {
+		return ErrIndexDoesNotExists
+	}
+
+	table.Indexes = slices.Delete(table.Indexes, i, i+1)
+
+	return AlterTable(tx, *table)
+}
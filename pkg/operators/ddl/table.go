@@ -2,9 +2,11 @@ package ddl
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	gokvstore "github.com/gustapinto/go-kv-store"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
 )
 
@@ -12,13 +14,13 @@ type Table struct {
 	Name     string
 	Database string
 	Columns  []Column
+	Indexes  []Index
 }
 
 var (
-	ErrTableDoesNotExists = errors.New("table does not exists")
-	ErrTableAlreadyExists = errors.New("table already exists")
-
-	tableCollectionsCache = map[string]*gokvstore.Collection{}
+	ErrTableDoesNotExists   = errors.New("table does not exists")
+	ErrTableAlreadyExists   = errors.New("table already exists")
+	ErrDuplicatedConstraint = errors.New("duplicated constraint")
 )
 
 func tableQualifiedName(database, name string) string {
@@ -40,33 +42,9 @@ func tableDataDir(database, name string) string {
 	return builder.String()
 }
 
-func TableCollection(rootCollection *gokvstore.Collection, database, name string) (*gokvstore.Collection, error) {
-	if tableCollectionsCache == nil {
-		tableCollectionsCache = map[string]*gokvstore.Collection{}
-	}
-
-	dataDir := tableDataDir(database, name)
-	if collection, exists := tableCollectionsCache[dataDir]; exists {
-		return collection, nil
-	}
-
-	newCollection, err := rootCollection.NewCollection(dataDir)
-	if err != nil {
-		return nil, err
-	}
-
-	tableCollectionsCache[dataDir] = newCollection
-	return newCollection, nil
-}
-
-func putTable(rootCollection *gokvstore.Collection, table Table, replace bool) error {
-	tableCollection, err := TableCollection(rootCollection, table.Database, table.Name)
-	if err != nil {
-		return err
-	}
-
+func putTable(tx *storage.Tx, table Table, replace bool) error {
 	if replace {
-		if err := tableCollection.Truncate(); err != nil {
+		if err := tx.Truncate(tableDataDir(table.Database, table.Name)); err != nil {
 			return err
 		}
 	}
@@ -76,20 +54,15 @@ func putTable(rootCollection *gokvstore.Collection, table Table, replace bool) e
 		return err
 	}
 
-	if err := tableCollection.Put(tableQualifiedName(table.Database, table.Name), tableBuffer, true); err != nil {
+	if err := tx.Put(tableDataDir(table.Database, table.Name), tableQualifiedName(table.Database, table.Name), tableBuffer, true); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func GetTable(rootCollection *gokvstore.Collection, database, name string) (*Table, error) {
-	tableCollection, err := TableCollection(rootCollection, database, name)
-	if err != nil {
-		return nil, err
-	}
-
-	tableBuffer, err := tableCollection.Get(tableQualifiedName(database, name))
+func GetTable(tx *storage.Tx, database, name string) (*Table, error) {
+	tableBuffer, err := tx.Get(tableDataDir(database, name), tableQualifiedName(database, name))
 	if err != nil {
 		if errors.Is(err, gokvstore.ErrKeyNotFound) {
 			return nil, ErrTableDoesNotExists
@@ -106,31 +79,52 @@ func GetTable(rootCollection *gokvstore.Collection, database, name string) (*Tab
 	return &table, nil
 }
 
-func TableExists(rootCollection *gokvstore.Collection, database, name string) (bool, error) {
-	tableCollection, err := TableCollection(rootCollection, database, name)
-	if err != nil {
-		return false, err
-	}
-
-	return tableCollection.Exists(tableQualifiedName(database, name)), nil
+func TableExists(tx *storage.Tx, database, name string) (bool, error) {
+	return tx.Exists(tableDataDir(database, name), tableQualifiedName(database, name))
 }
 
-func CreateTable(rootCollection *gokvstore.Collection, table Table, createOrReplace, createIfNotExists bool) error {
-	exists, err := TableExists(rootCollection, table.Database, table.Name)
+func CreateTable(tx *storage.Tx, table Table, createOrReplace, createIfNotExists bool) error {
+	if err := checkConstraintNames(table.Columns); err != nil {
+		return err
+	}
+
+	exists, err := TableExists(tx, table.Database, table.Name)
 	if err != nil {
 		return err
 	}
 
-	canIgnoreIfExists := createOrReplace || createIfNotExists
-	if exists && !canIgnoreIfExists {
+	if exists && !createOrReplace {
+		if createIfNotExists {
+			// The existing definition is kept as is, it is only replaced by OR REPLACE
+			return nil
+		}
+
 		return ErrTableAlreadyExists
 	}
 
-	return putTable(rootCollection, table, createOrReplace)
+	return putTable(tx, table, createOrReplace)
+}
+
+// checkConstraintNames Checks that the constraints of the columns have distinct names, as
+// the indexes backing them are stored under their names
+func checkConstraintNames(columns []Column) error {
+	names := make(map[string]bool)
+	for _, column := range columns {
+		for _, constraint := range column.Constraints {
+			name := strings.ToUpper(constraint.Name)
+			if names[name] {
+				return fmt.Errorf("%w: %s", ErrDuplicatedConstraint, constraint.Name)
+			}
+
+			names[name] = true
+		}
+	}
+
+	return nil
 }
 
-func AlterTable(rootCollection *gokvstore.Collection, table Table) error {
-	exists, err := TableExists(rootCollection, table.Database, table.Name)
+func AlterTable(tx *storage.Tx, table Table) error {
+	exists, err := TableExists(tx, table.Database, table.Name)
 	if err != nil {
 		return err
 	}
@@ -139,11 +133,11 @@ func AlterTable(rootCollection *gokvstore.Collection, table Table) error {
 		return ErrTableDoesNotExists
 	}
 
-	return putTable(rootCollection, table, false)
+	return putTable(tx, table, false)
 }
 
-func DropTable(rootCollection *gokvstore.Collection, database, name string) error {
-	exists, err := TableExists(rootCollection, database, name)
+func DropTable(tx *storage.Tx, database, name string) error {
+	exists, err := TableExists(tx, database, name)
 	if err != nil {
 		return err
 	}
@@ -152,10 +146,5 @@ func DropTable(rootCollection *gokvstore.Collection, database, name string) erro
 		return ErrTableDoesNotExists
 	}
 
-	tableCollection, err := TableCollection(rootCollection, database, name)
-	if err != nil {
-		return err
-	}
-
-	return tableCollection.Truncate()
+	return tx.Truncate(tableDataDir(database, name))
 }
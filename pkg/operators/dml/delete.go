@@ -1,17 +1,16 @@
 package dml
 
-import gokvstore "github.com/gustapinto/go-kv-store"
+import "github.com/gustapinto/go-sql-store/pkg/storage"
 
-func Delete(rootCollection *gokvstore.Collection, row Row) error {
-	rowCollection, err := RowCollection(rootCollection, row.Database, row.Table)
+func Delete(tx *storage.Tx, row Row) error {
+	primaryKey, err := PrimaryKeyForRow(row)
 	if err != nil {
 		return err
 	}
 
-	primaryKey, err := PrimaryKeyForRow(row)
-	if err != nil {
+	if err := tx.Delete(RowDataDir(row.Database, row.Table), primaryKey); err != nil {
 		return err
 	}
 
-	return rowCollection.Delete(primaryKey)
+	return deleteIndexEntries(tx, row, primaryKey)
 }
@@ -7,10 +7,11 @@ import (
 
 	gokvstore "github.com/gustapinto/go-kv-store"
 	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
 )
 
-func testDeleteMockRootCollection() (*gokvstore.Collection, error) {
+func testDeleteMockStorage() (*storage.Storage, error) {
 	row := Row{
 		Table:    "FOO_TABLE",
 		Database: "FOO_DB",
@@ -43,7 +44,7 @@ func testDeleteMockRootCollection() (*gokvstore.Collection, error) {
 		return nil, err
 	}
 
-	rowCollection, err := RowCollection(collection, row.Database, row.Table)
+	store, err := storage.New(collection)
 	if err != nil {
 		return nil, err
 	}
@@ -53,11 +54,16 @@ func testDeleteMockRootCollection() (*gokvstore.Collection, error) {
 		return nil, err
 	}
 
-	if err := rowCollection.Put("FOO", rowBuffer, false); err != nil {
+	tx := store.Begin()
+	if err := tx.Put(RowDataDir(row.Database, row.Table), "FOO", rowBuffer, false); err != nil {
 		return nil, err
 	}
 
-	return collection, nil
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
 }
 
 func TestDelete(t *testing.T) {
@@ -94,27 +100,35 @@ func TestDelete(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		rootCollection, err := testDeleteMockRootCollection()
+		store, err := testDeleteMockStorage()
 		if err != nil {
-			t.Errorf("not expected error when mocking root collection, got %s", err)
+			t.Errorf("not expected error when mocking storage, got %s", err)
 			return
 		}
-		defer rootCollection.Truncate()
+		defer store.TruncateAll()
 
 		t.Run(testCase.name, func(t *testing.T) {
-			if err := Delete(rootCollection, testCase.row); !errors.Is(err, testCase.expectedError) {
+			tx := store.Begin()
+			if err := Delete(tx, testCase.row); !errors.Is(err, testCase.expectedError) {
 				t.Errorf("expected %s error, got %s", testCase.expectedError.Error(), err.Error())
 				return
 			}
 
-			rowCollection, err := RowCollection(rootCollection, testCase.row.Database, testCase.row.Table)
+			if err := tx.Commit(); err != nil {
+				t.Errorf("not expected error when committing, got %s", err)
+				return
+			}
+
+			tx = store.Begin()
+			defer tx.Rollback()
+
+			exists, err := tx.Exists(RowDataDir(testCase.row.Database, testCase.row.Table), testCase.primaryKey)
 			if err != nil {
-				t.Errorf("not expected error when retrieving row collection, got %s", err)
+				t.Errorf("not expected error when reading row, got %s", err)
 				return
 			}
-			defer rowCollection.Truncate()
 
-			if exists := rowCollection.Exists(testCase.primaryKey); exists {
+			if exists {
 				t.Errorf("expected deleted row to not exist in the row collection")
 			}
 		})
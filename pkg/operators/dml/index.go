@@ -0,0 +1,212 @@
+package dml
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	gokvstore "github.com/gustapinto/go-kv-store"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
+)
+
+var (
+	ErrUniqueConstraintViolation = errors.New("unique constraint violation")
+)
+
+func IndexDataDir(database, table, index string) string {
+	builder := strings.Builder{}
+	builder.WriteString("databases/")
+	builder.WriteString(database)
+	builder.WriteString("/tables/")
+	builder.WriteString(table)
+	builder.WriteString("/indexes/")
+	builder.WriteString(index)
+
+	return builder.String()
+}
+
+// IndexKey Encodes the values of the indexed columns into an index key, the keys of an
+// index sort as the values they were encoded from, see [encodingutils.EncodeOrderedKey]
+func IndexKey(values ...any) string {
+	return encodingutils.EncodeOrderedKey(values...)
+}
+
+// indexesForRow Returns the indexes of the row table, a row of a table that is not in the
+// catalog is only indexed by the constraints of its columns
+func indexesForRow(tx *storage.Tx, row Row) ([]ddl.Index, error) {
+	table, err := ddl.GetTable(tx, row.Database, row.Table)
+	if err == nil {
+		return ddl.TableIndexes(*table), nil
+	}
+
+	if !errors.Is(err, ddl.ErrTableDoesNotExists) {
+		return nil, err
+	}
+
+	definitions := make([]ddl.Column, len(row.Columns))
+	for i, column := range row.Columns {
+		definitions[i] = column.Definition
+	}
+
+	return ddl.ConstraintIndexes(definitions), nil
+}
+
+func indexValuesForRow(row Row, index ddl.Index) ([]any, bool) {
+	values := make([]any, 0, len(index.Columns))
+	for _, name := range index.Columns {
+		i := slices.IndexFunc(row.Columns, func(column Column) bool {
+			return stringutils.EqualsIgnoreCase(column.Definition.Name, name)
+		})
+
+		if i == -1 {
+			return nil, false
+		}
+
+		values = append(values, row.Columns[i].Value)
+	}
+
+	return values, true
+}
+
+// indexEntryKey Returns the key of the entry of the row in the index, which holds its
+// primary key. The rows of an unique index share the entry of their values unless any of
+// them is NULL, so inserting the same values from concurrent transactions conflicts. Any
+// other row has its own entry, keyed by its values followed by its primary key, so rows
+// with the same values are added and removed without rewriting or sharing an entry
+func indexEntryKey(index ddl.Index, values []any, primaryKey string) string {
+	if index.Unique && !slices.Contains(values, nil) {
+		return IndexKey(values...)
+	}
+
+	return IndexKey(append(slices.Clone(values), primaryKey)...)
+}
+
+// IndexEntry Returns the primary keys of the rows indexed under the key, which are every
+// entry starting with it
+func IndexEntry(tx *storage.Tx, database, table, index, key string) ([]string, error) {
+	var primaryKeys []string
+	keyRange := storage.KeyRange{Start: key, End: encodingutils.OrderedKeyPrefixEnd(key)}
+	err := ScanIndex(tx, database, table, index, keyRange, func(_, primaryKey string) error {
+		primaryKeys = append(primaryKeys, primaryKey)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return primaryKeys, nil
+}
+
+// ScanIndex Calls fn with the primary key of the row of each entry in the key range of the
+// index, in the order of the range
+func ScanIndex(tx *storage.Tx, database, table, index string, keyRange storage.KeyRange, fn func(key, primaryKey string) error) error {
+	return tx.ScanRange(IndexDataDir(database, table, index), keyRange, func(key string, buffer []byte) error {
+		primaryKey, err := encodingutils.Decode[string](buffer)
+		if err != nil {
+			return err
+		}
+
+		return fn(key, primaryKey)
+	})
+}
+
+// insertIndexEntry Adds the row to the index. NULLs are never equal, so an unique index
+// can hold many rows with a NULL value
+func insertIndexEntry(tx *storage.Tx, row Row, primaryKey string, index ddl.Index) error {
+	values, ok := indexValuesForRow(row, index)
+	if !ok {
+		return nil
+	}
+
+	dataDir := IndexDataDir(row.Database, row.Table, index.Name)
+	key := indexEntryKey(index, values, primaryKey)
+
+	buffer, err := tx.Get(dataDir, key)
+	switch {
+	case errors.Is(err, gokvstore.ErrKeyNotFound):
+
+	case err != nil:
+		return err
+
+	default:
+		indexed, err := encodingutils.Decode[string](buffer)
+		if err != nil {
+			return err
+		}
+
+		if indexed != primaryKey {
+			return fmt.Errorf("%w: %s", ErrUniqueConstraintViolation, index.Name)
+		}
+
+		return nil
+	}
+
+	buffer, err = encodingutils.Encode(primaryKey)
+	if err != nil {
+		return err
+	}
+
+	return tx.Put(dataDir, key, buffer, false)
+}
+
+// insertIndexEntries Adds the row to every index of its table, failing with
+// [ErrUniqueConstraintViolation] if an unique index already has another row with the
+// same values
+func insertIndexEntries(tx *storage.Tx, row Row, primaryKey string) error {
+	indexes, err := indexesForRow(tx, row)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		if err := insertIndexEntry(tx, row, primaryKey, index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteIndexEntries(tx *storage.Tx, row Row, primaryKey string) error {
+	indexes, err := indexesForRow(tx, row)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		values, ok := indexValuesForRow(row, index)
+		if !ok {
+			continue
+		}
+
+		dataDir := IndexDataDir(row.Database, row.Table, index.Name)
+		err := tx.Delete(dataDir, indexEntryKey(index, values, primaryKey))
+		if err != nil && !errors.Is(err, gokvstore.ErrKeyNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BuildIndex Adds every row of the table to a newly created index, failing with
+// [ErrUniqueConstraintViolation] if an unique index is created over repeated values
+func BuildIndex(tx *storage.Tx, database, table string, index ddl.Index) error {
+	return tx.Scan(RowDataDir(database, table), func(primaryKey string, rowBuffer []byte) error {
+		row, err := encodingutils.Decode[Row](rowBuffer)
+		if err != nil {
+			return err
+		}
+
+		return insertIndexEntry(tx, row, primaryKey, index)
+	})
+}
+
+// DropIndexEntries Removes every entry of a dropped index
+func DropIndexEntries(tx *storage.Tx, database, table, index string) error {
+	return tx.Truncate(IndexDataDir(database, table, index))
+}
@@ -0,0 +1,271 @@
+package dml
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+func testIndexMockRow(id int64, email string) Row {
+	return Row{
+		Database: "FOO_DB",
+		Table:    "FOO_TABLE",
+		Columns: []Column{
+			{
+				Definition: ddl.Column{
+					Name:     "ID",
+					DataType: ddl.ColumnDataTypeInteger,
+					Constraints: []ddl.Constraint{
+						{Type: ddl.ConstraintPrimaryKey, Name: "id_pkey"},
+					},
+				},
+				Value: id,
+			},
+			{
+				Definition: ddl.Column{
+					Name:     "EMAIL",
+					DataType: ddl.ColumnDataTypeText,
+					Constraints: []ddl.Constraint{
+						{Type: ddl.ConstraintUnique, Name: "foo_email_unique"},
+					},
+				},
+				Value: email,
+			},
+		},
+	}
+}
+
+func testIndexMockStorage(t *testing.T, rows ...Row) *storage.Storage {
+	store, err := storage.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	tx := store.Begin()
+	for _, row := range rows {
+		if err := Insert(tx, row); err != nil {
+			t.Fatalf("not expected error when mocking storage, got %s", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	return store
+}
+
+func TestUniqueConstraint(t *testing.T) {
+	testCases := []struct {
+		name                string
+		run                 func(tx *storage.Tx) error
+		expectedError       error
+		expectedPrimaryKeys map[string][]string
+	}{
+		{
+			name: "should not insert a repeated unique value",
+			run: func(tx *storage.Tx) error {
+				return Insert(tx, testIndexMockRow(3, "foo@mail.com"))
+			},
+			expectedError: ErrUniqueConstraintViolation,
+		},
+		{
+			name: "should not update to a repeated unique value",
+			run: func(tx *storage.Tx) error {
+				_, err := Update(tx, testIndexMockRow(2, "bar@mail.com"), map[string]any{"EMAIL": "foo@mail.com"})
+				return err
+			},
+			expectedError: ErrUniqueConstraintViolation,
+		},
+		{
+			name: "should update a row keeping its unique value",
+			run: func(tx *storage.Tx) error {
+				_, err := Update(tx, testIndexMockRow(1, "foo@mail.com"), map[string]any{"ID": int64(4)})
+				return err
+			},
+			expectedPrimaryKeys: map[string][]string{
+				"foo@mail.com": {"4"},
+				"bar@mail.com": {"2"},
+			},
+		},
+		{
+			name: "should release unique values of updated rows",
+			run: func(tx *storage.Tx) error {
+				if _, err := Update(tx, testIndexMockRow(1, "foo@mail.com"), map[string]any{"EMAIL": "baz@mail.com"}); err != nil {
+					return err
+				}
+
+				return Insert(tx, testIndexMockRow(3, "foo@mail.com"))
+			},
+			expectedPrimaryKeys: map[string][]string{
+				"foo@mail.com": {"3"},
+				"bar@mail.com": {"2"},
+				"baz@mail.com": {"1"},
+			},
+		},
+		{
+			name: "should release unique values of deleted rows",
+			run: func(tx *storage.Tx) error {
+				if err := Delete(tx, testIndexMockRow(1, "foo@mail.com")); err != nil {
+					return err
+				}
+
+				return Insert(tx, testIndexMockRow(3, "foo@mail.com"))
+			},
+			expectedPrimaryKeys: map[string][]string{
+				"foo@mail.com": {"3"},
+				"bar@mail.com": {"2"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			store := testIndexMockStorage(t, testIndexMockRow(1, "foo@mail.com"), testIndexMockRow(2, "bar@mail.com"))
+			tx := store.Begin()
+			defer tx.Rollback()
+
+			err := testCase.run(tx)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if err != nil {
+				if !strings.Contains(err.Error(), "foo_email_unique") {
+					t.Errorf("expected error to name the constraint, got %s", err)
+				}
+
+				return
+			}
+
+			for value, expectedPrimaryKeys := range testCase.expectedPrimaryKeys {
+				primaryKeys, err := IndexEntry(tx, "FOO_DB", "FOO_TABLE", "foo_email_unique", IndexKey(value))
+				if err != nil {
+					t.Errorf("not expected error, got %s", err)
+					return
+				}
+
+				if !slices.Equal(primaryKeys, expectedPrimaryKeys) {
+					t.Errorf("expected %s to be indexed to %v, got %v", value, expectedPrimaryKeys, primaryKeys)
+					return
+				}
+			}
+		})
+	}
+}
+
+func testIndexMockIndexedStorage(t *testing.T, rows ...Row) *storage.Storage {
+	store := testIndexMockStorage(t)
+
+	table := ddl.Table{
+		Database: "FOO_DB",
+		Name:     "FOO_TABLE",
+		Columns: []ddl.Column{
+			{Name: "ID", DataType: ddl.ColumnDataTypeInteger, Constraints: []ddl.Constraint{{Type: ddl.ConstraintPrimaryKey, Name: "id_pkey"}}},
+			{Name: "EMAIL", DataType: ddl.ColumnDataTypeText},
+		},
+		Indexes: []ddl.Index{
+			{Name: "foo_email_idx", Columns: []string{"EMAIL"}},
+		},
+	}
+
+	tx := store.Begin()
+	if err := ddl.CreateTable(tx, table, false, false); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	for _, row := range rows {
+		if err := Insert(tx, row); err != nil {
+			t.Fatalf("not expected error when mocking storage, got %s", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	return store
+}
+
+func TestIndexRepeatedValues(t *testing.T) {
+	testCases := []struct {
+		name                string
+		run                 func(store *storage.Storage, tx *storage.Tx) error
+		expectedPrimaryKeys []string
+	}{
+		{
+			name:                "should index every row with the value",
+			run:                 func(*storage.Storage, *storage.Tx) error { return nil },
+			expectedPrimaryKeys: []string{"1", "2"},
+		},
+		{
+			name: "should only remove the deleted row",
+			run: func(_ *storage.Storage, tx *storage.Tx) error {
+				return Delete(tx, testIndexMockRow(1, "foo@mail.com"))
+			},
+			expectedPrimaryKeys: []string{"2"},
+		},
+		{
+			name: "should only move the updated row",
+			run: func(_ *storage.Storage, tx *storage.Tx) error {
+				_, err := Update(tx, testIndexMockRow(2, "foo@mail.com"), map[string]any{"EMAIL": "bar@mail.com"})
+				return err
+			},
+			expectedPrimaryKeys: []string{"1"},
+		},
+		{
+			name: "should not conflict with concurrent transactions adding rows with the value",
+			run: func(store *storage.Storage, tx *storage.Tx) error {
+				concurrent := store.Begin()
+				if err := Insert(concurrent, testIndexMockRow(3, "foo@mail.com")); err != nil {
+					return err
+				}
+
+				if err := Insert(tx, testIndexMockRow(4, "foo@mail.com")); err != nil {
+					return err
+				}
+
+				if err := concurrent.Commit(); err != nil {
+					return err
+				}
+
+				return tx.Commit()
+			},
+			expectedPrimaryKeys: []string{"1", "2", "3", "4"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			store := testIndexMockIndexedStorage(t, testIndexMockRow(1, "foo@mail.com"), testIndexMockRow(2, "foo@mail.com"))
+			tx := store.Begin()
+			defer tx.Rollback()
+
+			if err := testCase.run(store, tx); err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if tx.Closed() {
+				tx = store.Begin()
+				defer tx.Rollback()
+			}
+
+			primaryKeys, err := IndexEntry(tx, "FOO_DB", "FOO_TABLE", "foo_email_idx", IndexKey("foo@mail.com"))
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if !slices.Equal(primaryKeys, testCase.expectedPrimaryKeys) {
+				t.Errorf("expected foo@mail.com to be indexed to %v, got %v", testCase.expectedPrimaryKeys, primaryKeys)
+				return
+			}
+		})
+	}
+}
@@ -2,39 +2,86 @@ package dml
 
 import (
 	"errors"
+	"slices"
 	"strings"
 
-	gokvstore "github.com/gustapinto/go-kv-store"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
 )
 
 var (
 	ErrPrimaryKeyAlreadyExists = errors.New("primary key already exists in database")
 )
 
-func Insert(rootCollection *gokvstore.Collection, row Row) error {
-	primaryKey, err := PrimaryKeyForRow(row)
+func Insert(tx *storage.Tx, row Row) error {
+	row, err := rowWithTableColumns(tx, row)
 	if err != nil {
 		return err
 	}
 
 	for i, column := range row.Columns {
+		value, err := columnValue(column, column.Value)
+		if err != nil {
+			return err
+		}
+
 		row.Columns[i].Definition.Name = strings.ToUpper(column.Definition.Name)
+		row.Columns[i].Value = value
+	}
+
+	primaryKey, err := PrimaryKeyForRow(row)
+	if err != nil {
+		return err
 	}
 
-	rowCollection, err := RowCollection(rootCollection, row.Database, row.Table)
+	dataDir := RowDataDir(row.Database, row.Table)
+
+	exists, err := tx.Exists(dataDir, primaryKey)
 	if err != nil {
 		return err
 	}
 
-	if exists := rowCollection.Exists(primaryKey); exists {
+	if exists {
 		return ErrPrimaryKeyAlreadyExists
 	}
 
+	if err := insertIndexEntries(tx, row, primaryKey); err != nil {
+		return err
+	}
+
 	rowBuffer, err := encodingutils.Encode(row)
 	if err != nil {
 		return err
 	}
 
-	return rowCollection.Put(primaryKey, rowBuffer, false)
+	return tx.Put(dataDir, primaryKey, rowBuffer, false)
+}
+
+// rowWithTableColumns Returns the row with a NULL value for each column of its table that
+// it does not have, a row of a table that is not in the catalog is returned as is
+func rowWithTableColumns(tx *storage.Tx, row Row) (Row, error) {
+	table, err := ddl.GetTable(tx, row.Database, row.Table)
+	if err != nil {
+		if errors.Is(err, ddl.ErrTableDoesNotExists) {
+			return row, nil
+		}
+
+		return Row{}, err
+	}
+
+	columns := slices.Clone(row.Columns)
+	for _, definition := range table.Columns {
+		hasColumn := slices.ContainsFunc(columns, func(column Column) bool {
+			return stringutils.EqualsIgnoreCase(column.Definition.Name, definition.Name)
+		})
+
+		if !hasColumn {
+			columns = append(columns, Column{Definition: definition, Value: nil})
+		}
+	}
+
+	row.Columns = columns
+	return row, nil
 }
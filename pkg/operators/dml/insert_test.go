@@ -2,15 +2,18 @@ package dml
 
 import (
 	"errors"
+	"math"
 	"os"
 	"testing"
+	"time"
 
 	gokvstore "github.com/gustapinto/go-kv-store"
 	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
 )
 
-func testInsertMockRootCollection() (*gokvstore.Collection, error) {
+func testInsertMockStorage() (*storage.Storage, error) {
 	row := Row{
 		Table:    "FOO_TABLE",
 		Database: "FOO_DB",
@@ -36,7 +39,7 @@ func testInsertMockRootCollection() (*gokvstore.Collection, error) {
 		return nil, err
 	}
 
-	rowCollection, err := RowCollection(collection, row.Database, row.Table)
+	store, err := storage.New(collection)
 	if err != nil {
 		return nil, err
 	}
@@ -46,11 +49,16 @@ func testInsertMockRootCollection() (*gokvstore.Collection, error) {
 		return nil, err
 	}
 
-	if err := rowCollection.Put("EXISTING-PRIMARY-KEY", rowBuffer, false); err != nil {
+	tx := store.Begin()
+	if err := tx.Put(RowDataDir(row.Database, row.Table), "EXISTING-PRIMARY-KEY", rowBuffer, false); err != nil {
 		return nil, err
 	}
 
-	return collection, nil
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
 }
 
 func TestInsert(t *testing.T) {
@@ -108,30 +116,114 @@ func TestInsert(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:          "should insert Go integers of any kind into INTEGER columns",
+			primaryKey:    "7",
+			expectedError: nil,
+			row: Row{
+				Database: "FOO_DB",
+				Table:    "FOO_TABLE",
+				Columns: []Column{
+					{
+						Definition: ddl.Column{
+							Name:        "ID",
+							DataType:    ddl.ColumnDataTypeInteger,
+							Constraints: []ddl.Constraint{{Type: ddl.ConstraintPrimaryKey, Name: "id_pk"}},
+						},
+						Value: uint8(7),
+					},
+				},
+			},
+		},
+		{
+			name:          "should key timestamps in UTC",
+			primaryKey:    "2024-01-01T10:00:00.5Z",
+			expectedError: nil,
+			row: Row{
+				Database: "FOO_DB",
+				Table:    "FOO_TABLE",
+				Columns: []Column{
+					{
+						Definition: ddl.Column{
+							Name:        "AT",
+							DataType:    ddl.ColumnDataTypeTimestamp,
+							Constraints: []ddl.Constraint{{Type: ddl.ConstraintPrimaryKey, Name: "at_pk"}},
+						},
+						Value: time.Date(2024, time.January, 1, 12, 0, 0, 5e8, time.FixedZone("", 2*60*60)),
+					},
+				},
+			},
+		},
+		{
+			name:          "should not insert NULL primary keys",
+			primaryKey:    "EXISTING-PRIMARY-KEY",
+			expectedError: ErrNotNullConstraintViolation,
+			row: Row{
+				Database: "FOO_DB",
+				Table:    "FOO_TABLE",
+				Columns: []Column{
+					{
+						Definition: ddl.Column{
+							Name:        "ID",
+							DataType:    ddl.ColumnDataTypeInteger,
+							Constraints: []ddl.Constraint{{Type: ddl.ConstraintPrimaryKey, Name: "id_pk"}},
+						},
+						Value: nil,
+					},
+				},
+			},
+		},
+		{
+			name:          "should not insert integers out of the range of INTEGER columns",
+			primaryKey:    "EXISTING-PRIMARY-KEY",
+			expectedError: ddl.ErrValueOutOfRange,
+			row: Row{
+				Database: "FOO_DB",
+				Table:    "FOO_TABLE",
+				Columns: []Column{
+					{
+						Definition: ddl.Column{
+							Name:        "ID",
+							DataType:    ddl.ColumnDataTypeInteger,
+							Constraints: []ddl.Constraint{{Type: ddl.ConstraintPrimaryKey, Name: "id_pk"}},
+						},
+						Value: uint64(math.MaxUint64),
+					},
+				},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
-		rootCollection, err := testInsertMockRootCollection()
+		store, err := testInsertMockStorage()
 		if err != nil {
-			t.Errorf("not expected error when mocking root collection, got %s", err)
+			t.Errorf("not expected error when mocking storage, got %s", err)
 			return
 		}
-		defer rootCollection.Truncate()
+		defer store.TruncateAll()
 
 		t.Run(testCase.name, func(t *testing.T) {
-			if err := Insert(rootCollection, testCase.row); !errors.Is(err, testCase.expectedError) {
+			tx := store.Begin()
+			if err := Insert(tx, testCase.row); !errors.Is(err, testCase.expectedError) {
 				t.Errorf("expected %s error, got %s", testCase.expectedError.Error(), err.Error())
 				return
 			}
 
-			rowCollection, err := RowCollection(rootCollection, testCase.row.Database, testCase.row.Table)
+			if err := tx.Commit(); err != nil {
+				t.Errorf("not expected error when committing, got %s", err)
+				return
+			}
+
+			tx = store.Begin()
+			defer tx.Rollback()
+
+			exists, err := tx.Exists(RowDataDir(testCase.row.Database, testCase.row.Table), testCase.primaryKey)
 			if err != nil {
-				t.Errorf("not expected error when retrieving row collection, got %s", err)
+				t.Errorf("not expected error when reading row, got %s", err)
 				return
 			}
-			defer rowCollection.Truncate()
 
-			if exists := rowCollection.Exists(testCase.primaryKey); !exists {
+			if !exists {
 				t.Errorf("expected inserted row to exists in the row collection")
 			}
 		})
@@ -5,11 +5,10 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
 	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
-
-	gokvstore "github.com/gustapinto/go-kv-store"
 )
 
 type Column struct {
@@ -24,7 +23,8 @@ type Row struct {
 }
 
 var (
-	ErrRowWithoutPrimaryKey = errors.New("row does not have a primary key")
+	ErrRowWithoutPrimaryKey       = errors.New("row does not have a primary key")
+	ErrNotNullConstraintViolation = errors.New("not null constraint violation")
 )
 
 func AreColumnsEqual(c1, c2 Column) bool {
@@ -46,7 +46,7 @@ func AreRowsEqual(r1, r2 Row) bool {
 	return slices.EqualFunc(r1.Columns, r2.Columns, AreColumnsEqual)
 }
 
-func rowDataDir(database, table string) string {
+func RowDataDir(database, table string) string {
 	builder := strings.Builder{}
 	builder.WriteString("databases/")
 	builder.WriteString(database)
@@ -57,18 +57,38 @@ func rowDataDir(database, table string) string {
 	return builder.String()
 }
 
-func RowCollection(rootCollection *gokvstore.Collection, database, table string) (*gokvstore.Collection, error) {
-	dataDir := rowDataDir(database, table)
-
-	return rootCollection.NewCollection(dataDir)
-}
-
 func PrimaryKeyForRow(row Row) (string, error) {
 	for _, column := range row.Columns {
 		if ddl.ColumnIsPrimaryKey(column.Definition) {
-			return fmt.Sprintf("%v", column.Value), nil
+			return PrimaryKeyForValue(column.Value), nil
 		}
 	}
 
 	return "", ErrRowWithoutPrimaryKey
 }
+
+// PrimaryKeyForValue Returns the key of the row whose primary key has the value.
+// Timestamps are keyed in UTC, so the same instant has the same key in any zone
+func PrimaryKeyForValue(value any) string {
+	if timestamp, isTimestamp := value.(time.Time); isTimestamp {
+		return timestamp.UTC().Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// columnValue Returns the value as a value of the type of the column, see
+// [ddl.ValueForDataType], failing with [ErrNotNullConstraintViolation] for NULL values of
+// columns that can not hold them
+func columnValue(column Column, value any) (any, error) {
+	if value == nil && ddl.ColumnIsNotNull(column.Definition) {
+		return nil, fmt.Errorf("%w: column %s", ErrNotNullConstraintViolation, column.Definition.Name)
+	}
+
+	value, err := ddl.ValueForDataType(value, column.Definition.DataType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: column %s", err, column.Definition.Name)
+	}
+
+	return value, nil
+}
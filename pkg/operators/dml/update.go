@@ -1,38 +1,95 @@
 package dml
 
 import (
+	"errors"
+	"slices"
 	"strings"
 
 	gokvstore "github.com/gustapinto/go-kv-store"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
 )
 
-func Update(rootCollection *gokvstore.Collection, originalRow Row, columnsToBeUpdated map[string]any) (updated bool, err error) {
-	rowCollection, err := RowCollection(rootCollection, originalRow.Database, originalRow.Table)
+func Update(tx *storage.Tx, originalRow Row, columnsToBeUpdated map[string]any) (updated bool, err error) {
+	primaryKey, err := PrimaryKeyForRow(originalRow)
 	if err != nil {
 		return false, err
 	}
 
-	primaryKey, err := PrimaryKeyForRow(originalRow)
+	dataDir := RowDataDir(originalRow.Database, originalRow.Table)
+
+	// The row is read again from the transaction, so updates made earlier in the same
+	// transaction to other columns of the row are not lost
+	rowBuffer, err := tx.Get(dataDir, primaryKey)
+	if err != nil {
+		if errors.Is(err, gokvstore.ErrKeyNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	row, err := encodingutils.Decode[Row](rowBuffer)
 	if err != nil {
 		return false, err
 	}
 
-	for i, column := range originalRow.Columns {
+	if row, err = rowWithTableColumns(tx, row); err != nil {
+		return false, err
+	}
+
+	columns := slices.Clone(row.Columns)
+	for i, column := range columns {
 		value, exists := columnsToBeUpdated[strings.ToUpper(column.Definition.Name)]
+		if !exists {
+			continue
+		}
+
+		if columns[i].Value, err = columnValue(column, value); err != nil {
+			return false, err
+		}
+	}
+
+	if err := deleteIndexEntries(tx, row, primaryKey); err != nil {
+		return false, err
+	}
+
+	row.Columns = columns
+
+	newPrimaryKey, err := PrimaryKeyForRow(row)
+	if err != nil {
+		return false, err
+	}
+
+	if newPrimaryKey != primaryKey {
+		exists, err := tx.Exists(dataDir, newPrimaryKey)
+		if err != nil {
+			return false, err
+		}
+
 		if exists {
-			originalRow.Columns[i].Value = value
+			return false, ErrPrimaryKeyAlreadyExists
 		}
 	}
 
-	newRowBuffer, err := encodingutils.Encode(originalRow)
+	if err := insertIndexEntries(tx, row, newPrimaryKey); err != nil {
+		return false, err
+	}
+
+	newRowBuffer, err := encodingutils.Encode(row)
 	if err != nil {
 		return false, err
 	}
 
-	if err := rowCollection.Put(primaryKey, newRowBuffer, false); err != nil {
+	if err := tx.Put(dataDir, newPrimaryKey, newRowBuffer, false); err != nil {
 		return false, err
 	}
 
+	if newPrimaryKey != primaryKey {
+		if err := tx.Delete(dataDir, primaryKey); err != nil {
+			return false, err
+		}
+	}
+
 	return true, nil
 }
@@ -6,16 +6,17 @@ import (
 
 	gokvstore "github.com/gustapinto/go-kv-store"
 	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
 )
 
-func testUpdateMockRootCollection(row Row) (*gokvstore.Collection, error) {
+func testUpdateMockStorage(row Row) (*storage.Storage, error) {
 	collection, err := gokvstore.NewCollection(gokvstore.NewFsRecordStore(os.TempDir()))
 	if err != nil {
 		return nil, err
 	}
 
-	rowCollection, err := RowCollection(collection, row.Database, row.Table)
+	store, err := storage.New(collection)
 	if err != nil {
 		return nil, err
 	}
@@ -25,11 +26,16 @@ func testUpdateMockRootCollection(row Row) (*gokvstore.Collection, error) {
 		return nil, err
 	}
 
-	if err := rowCollection.Put("FOO", rowBuffer, false); err != nil {
+	tx := store.Begin()
+	if err := tx.Put(RowDataDir(row.Database, row.Table), "FOO", rowBuffer, false); err != nil {
 		return nil, err
 	}
 
-	return collection, nil
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
 }
 
 func TestUpdate(t *testing.T) {
@@ -88,20 +94,26 @@ func TestUpdate(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		rootCollection, err := testUpdateMockRootCollection(mockedOriginalRow)
+		store, err := testUpdateMockStorage(mockedOriginalRow)
 		if err != nil {
-			t.Errorf("not expected error when mocking root collection, got %s", err)
+			t.Errorf("not expected error when mocking storage, got %s", err)
 			return
 		}
-		defer rootCollection.Truncate()
+		defer store.TruncateAll()
 
 		t.Run(testCase.name, func(t *testing.T) {
-			actual, err := Update(rootCollection, testCase.originalRow, testCase.columnsToBeUpdated)
+			tx := store.Begin()
+			actual, err := Update(tx, testCase.originalRow, testCase.columnsToBeUpdated)
 			if err != nil {
 				t.Errorf("expected %s error, got %s", testCase.expectedError.Error(), err.Error())
 				return
 			}
 
+			if err := tx.Commit(); err != nil {
+				t.Errorf("not expected error when committing, got %s", err)
+				return
+			}
+
 			if actual != testCase.expectedValue {
 				t.Errorf("expected value %v, got %v", testCase.expectedValue, actual)
 				return
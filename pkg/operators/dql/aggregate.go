@@ -0,0 +1,357 @@
+package dql
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+)
+
+// AggregateFunction The function an [Aggregate] computes over the rows of a group
+type AggregateFunction string
+
+var (
+	AggregateFunctionCount AggregateFunction = "COUNT"
+	AggregateFunctionSum   AggregateFunction = "SUM"
+	AggregateFunctionAvg   AggregateFunction = "AVG"
+	AggregateFunctionMin   AggregateFunction = "MIN"
+	AggregateFunctionMax   AggregateFunction = "MAX"
+)
+
+// AggregateState The state of a user defined aggregate function in a group, to which each
+// non NULL value of the group is added. Its result is the value of the aggregate
+type AggregateState interface {
+	Add(value any) error
+	Result() (any, error)
+}
+
+// Aggregate An aggregate function over the values of the expression in the rows of a group,
+// its result is the value of Column in the group row. NULL values are ignored, and a nil
+// expression counts every row, as in COUNT(*). With Distinct each value is aggregated once.
+// With NewState the function is user defined, each group having a new state
+type Aggregate struct {
+	Column     ddl.Column
+	Function   AggregateFunction
+	Expression Expression
+	Distinct   bool
+	NewState   func() AggregateState
+}
+
+// Aggregation Groups rows by the values of the GroupBy columns, NULLs being one group, and
+// computes the aggregates of each group. Without GroupBy columns every row is in a single
+// group, even if there are no rows. The groups for which Having is not true are dropped
+type Aggregation struct {
+	GroupBy    []string
+	Aggregates []Aggregate
+	Having     Expression
+}
+
+// Aggregator Streams rows into the accumulators of their groups
+type Aggregator struct {
+	aggregation Aggregation
+	groups      map[string]*aggregateGroup
+}
+
+type aggregateGroup struct {
+	row          dml.Row
+	accumulators []accumulator
+}
+
+// NewAggregator Returns an aggregator without groups
+func NewAggregator(aggregation Aggregation) *Aggregator {
+	return &Aggregator{
+		aggregation: aggregation,
+		groups:      make(map[string]*aggregateGroup),
+	}
+}
+
+// Add Adds the row to the accumulators of its group
+func (a *Aggregator) Add(row dml.Row) error {
+	columns := make([]dml.Column, 0, len(a.aggregation.GroupBy)+len(a.aggregation.Aggregates))
+	values := make([]any, 0, len(a.aggregation.GroupBy))
+	for _, name := range a.aggregation.GroupBy {
+		column, err := rowColumn(row, name)
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, name)
+		}
+
+		columns = append(columns, column)
+		values = append(values, column.Value)
+	}
+
+	key := encodingutils.EncodeOrderedKey(values...)
+	group, exists := a.groups[key]
+	if !exists {
+		group = a.newGroup(dml.Row{Database: row.Database, Table: row.Table, Columns: columns})
+		a.groups[key] = group
+	}
+
+	for i, aggregate := range a.aggregation.Aggregates {
+		if err := accumulate(group.accumulators[i], aggregate.Expression, row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rows Returns a row for each group for which Having is true, sorted by the grouped
+// columns. A group row has the grouped columns followed by the aggregate columns
+func (a *Aggregator) Rows() ([]dml.Row, error) {
+	if len(a.groups) == 0 && len(a.aggregation.GroupBy) == 0 {
+		a.groups[encodingutils.EncodeOrderedKey()] = a.newGroup(dml.Row{})
+	}
+
+	keys := make([]string, 0, len(a.groups))
+	for key := range a.groups {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	rows := make([]dml.Row, 0, len(keys))
+	for _, key := range keys {
+		group := a.groups[key]
+
+		row := group.row
+		row.Columns = slices.Clone(row.Columns)
+		for i, aggregate := range a.aggregation.Aggregates {
+			value, err := group.accumulators[i].result()
+			if err != nil {
+				return nil, err
+			}
+
+			row.Columns = append(row.Columns, dml.Column{Definition: aggregate.Column, Value: value})
+		}
+
+		if a.aggregation.Having != nil {
+			matches, err := Matches(a.aggregation.Having, row)
+			if err != nil {
+				return nil, err
+			}
+
+			if !matches {
+				continue
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func (a *Aggregator) newGroup(row dml.Row) *aggregateGroup {
+	accumulators := make([]accumulator, 0, len(a.aggregation.Aggregates))
+	for _, aggregate := range a.aggregation.Aggregates {
+		accumulators = append(accumulators, newAccumulator(aggregate))
+	}
+
+	return &aggregateGroup{row: row, accumulators: accumulators}
+}
+
+// SelectAggregated Returns the group rows of the rows of the table for which the where
+// condition is true, see [Aggregator.Rows]. The rows are aggregated while they are read,
+// so only the accumulators of each group are kept in memory
+func SelectAggregated(tx *storage.Tx, database, table string, where Expression, aggregation Aggregation) ([]dml.Row, error) {
+	aggregator := NewAggregator(aggregation)
+
+	query := SelectQuery{Database: database, Table: table, Where: where}
+	if err := query.scan(tx, aggregator.Add); err != nil {
+		return nil, err
+	}
+
+	return aggregator.Rows()
+}
+
+// accumulator Accumulates the non NULL values of an aggregate in a group
+type accumulator interface {
+	add(value any) error
+	result() (any, error)
+}
+
+// accumulate Adds the value of the expression for the row to the accumulator, unless it is
+// NULL. A nil expression adds every row, as in COUNT(*)
+func accumulate(acc accumulator, expression Expression, row dml.Row) error {
+	var value any = true
+	if expression != nil {
+		var err error
+		if value, err = expression.Evaluate(row); err != nil {
+			return err
+		}
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	return acc.add(value)
+}
+
+func newAccumulator(aggregate Aggregate) accumulator {
+	var acc accumulator
+	switch {
+	case aggregate.NewState != nil:
+		acc = &stateAccumulator{state: aggregate.NewState()}
+
+	case aggregate.Function == AggregateFunctionCount:
+		acc = &countAccumulator{}
+
+	case aggregate.Function == AggregateFunctionSum:
+		acc = &sumAccumulator{}
+
+	case aggregate.Function == AggregateFunctionAvg:
+		acc = &avgAccumulator{}
+
+	case aggregate.Function == AggregateFunctionMin:
+		acc = &extremeAccumulator{keeps: func(result int) bool { return result < 0 }}
+
+	case aggregate.Function == AggregateFunctionMax:
+		acc = &extremeAccumulator{keeps: func(result int) bool { return result > 0 }}
+
+	default:
+		acc = &invalidAccumulator{function: aggregate.Function}
+	}
+
+	if aggregate.Distinct {
+		return &distinctAccumulator{accumulator: acc, seen: make(map[string]struct{})}
+	}
+
+	return acc
+}
+
+type countAccumulator struct {
+	count int64
+}
+
+func (c *countAccumulator) add(any) error {
+	c.count++
+	return nil
+}
+
+func (c *countAccumulator) result() (any, error) {
+	return c.count, nil
+}
+
+// sumAccumulator Sums integers as integers and any other numbers as floats, it is NULL
+// without values
+type sumAccumulator struct {
+	sum any
+}
+
+func (s *sumAccumulator) add(value any) error {
+	var sum any = int64(0)
+	if s.sum != nil {
+		sum = s.sum
+	}
+
+	sum, err := Arithmetic{Operator: ArithmeticOperatorAdd, Left: Literal{Value: sum}, Right: Literal{Value: value}}.Evaluate(dml.Row{})
+	if err != nil {
+		return err
+	}
+
+	s.sum = sum
+	return nil
+}
+
+func (s *sumAccumulator) result() (any, error) {
+	return s.sum, nil
+}
+
+// avgAccumulator Averages numbers as floats, it is NULL without values
+type avgAccumulator struct {
+	sum   float64
+	count int64
+}
+
+func (a *avgAccumulator) add(value any) error {
+	number, isNumber := numberAsFloat(reflect.ValueOf(value))
+	if !isNumber {
+		return fmt.Errorf("%w: can not average %T", ErrInvalidDataType, value)
+	}
+
+	a.sum += number
+	a.count++
+	return nil
+}
+
+func (a *avgAccumulator) result() (any, error) {
+	if a.count == 0 {
+		return nil, nil
+	}
+
+	return a.sum / float64(a.count), nil
+}
+
+// extremeAccumulator Keeps the value for which keeps is true when compared with the kept
+// value, it is NULL without values
+type extremeAccumulator struct {
+	value any
+	keeps func(result int) bool
+}
+
+func (e *extremeAccumulator) add(value any) error {
+	if e.value == nil {
+		e.value = value
+		return nil
+	}
+
+	result, err := CompareValues(value, e.value)
+	if err != nil {
+		return err
+	}
+
+	if e.keeps(result) {
+		e.value = value
+	}
+
+	return nil
+}
+
+func (e *extremeAccumulator) result() (any, error) {
+	return e.value, nil
+}
+
+// distinctAccumulator Adds each value to the accumulator only once
+type distinctAccumulator struct {
+	accumulator
+	seen map[string]struct{}
+}
+
+func (d *distinctAccumulator) add(value any) error {
+	key := encodingutils.EncodeOrderedKey(value)
+	if _, seen := d.seen[key]; seen {
+		return nil
+	}
+
+	d.seen[key] = struct{}{}
+	return d.accumulator.add(value)
+}
+
+// stateAccumulator Accumulates the values of a user defined aggregate in its state
+type stateAccumulator struct {
+	state AggregateState
+}
+
+func (s *stateAccumulator) add(value any) error {
+	return s.state.Add(value)
+}
+
+func (s *stateAccumulator) result() (any, error) {
+	return s.state.Result()
+}
+
+type invalidAccumulator struct {
+	function AggregateFunction
+}
+
+func (i *invalidAccumulator) add(any) error {
+	return fmt.Errorf("%w: aggregate function %s", ErrInvalidDataType, i.function)
+}
+
+func (i *invalidAccumulator) result() (any, error) {
+	return nil, nil
+}
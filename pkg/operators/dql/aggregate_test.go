@@ -0,0 +1,140 @@
+package dql
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+)
+
+func TestAggregator(t *testing.T) {
+	newRow := func(kind, amount any) dml.Row {
+		return dml.Row{
+			Columns: []dml.Column{
+				{Definition: ddl.Column{Name: "KIND", DataType: ddl.ColumnDataTypeText}, Value: kind},
+				{Definition: ddl.Column{Name: "AMOUNT", DataType: ddl.ColumnDataTypeInteger}, Value: amount},
+			},
+		}
+	}
+
+	mockedRows := []dml.Row{
+		newRow("b", int64(2)),
+		newRow("a", int64(3)),
+		newRow(nil, int64(4)),
+		newRow("b", nil),
+		newRow("b", int64(2)),
+	}
+
+	aggregateColumn := func(name string, dataType ddl.ColumnDataType) ddl.Column {
+		return ddl.Column{Name: name, DataType: dataType}
+	}
+
+	amount := ColumnRef{Name: "amount"}
+	count := Aggregate{Column: aggregateColumn("COUNT", ddl.ColumnDataTypeInteger), Function: AggregateFunctionCount}
+	sum := Aggregate{Column: aggregateColumn("SUM", ddl.ColumnDataTypeInteger), Function: AggregateFunctionSum, Expression: amount}
+
+	testCases := []struct {
+		name          string
+		rows          []dml.Row
+		aggregation   Aggregation
+		expectedRows  [][]any
+		expectedError error
+	}{
+		{
+			name: "should aggregate every row into a single group without GROUP BY",
+			rows: mockedRows,
+			aggregation: Aggregation{
+				Aggregates: []Aggregate{
+					count,
+					{Column: aggregateColumn("AMOUNTS", ddl.ColumnDataTypeInteger), Function: AggregateFunctionCount, Expression: amount},
+					{Column: aggregateColumn("DISTINCT_AMOUNTS", ddl.ColumnDataTypeInteger), Function: AggregateFunctionCount, Expression: amount, Distinct: true},
+					sum,
+					{Column: aggregateColumn("AVG", ddl.ColumnDataTypeFloat), Function: AggregateFunctionAvg, Expression: amount},
+					{Column: aggregateColumn("MIN", ddl.ColumnDataTypeText), Function: AggregateFunctionMin, Expression: ColumnRef{Name: "kind"}},
+					{Column: aggregateColumn("MAX", ddl.ColumnDataTypeInteger), Function: AggregateFunctionMax, Expression: amount},
+				},
+			},
+			expectedRows: [][]any{{int64(5), int64(4), int64(3), int64(11), 2.75, "a", int64(4)}},
+		},
+		{
+			name:         "should aggregate a single group without rows",
+			aggregation:  Aggregation{Aggregates: []Aggregate{count, sum}},
+			expectedRows: [][]any{{int64(0), nil}},
+		},
+		{
+			name:         "should not return groups without rows when grouping",
+			aggregation:  Aggregation{GroupBy: []string{"kind"}, Aggregates: []Aggregate{count}},
+			expectedRows: nil,
+		},
+		{
+			name:         "should group by columns sorted, with NULLs as a group",
+			rows:         mockedRows,
+			aggregation:  Aggregation{GroupBy: []string{"kind"}, Aggregates: []Aggregate{count, sum}},
+			expectedRows: [][]any{{nil, int64(1), int64(4)}, {"a", int64(1), int64(3)}, {"b", int64(3), int64(4)}},
+		},
+		{
+			name: "should drop the groups for which HAVING is not true",
+			rows: mockedRows,
+			aggregation: Aggregation{
+				GroupBy:    []string{"kind"},
+				Aggregates: []Aggregate{count},
+				Having:     Comparison{Comparison: FilterComparisonLess, Left: ColumnRef{Name: "count"}, Right: Literal{Value: int64(2)}},
+			},
+			expectedRows: [][]any{{nil, int64(1)}, {"a", int64(1)}},
+		},
+		{
+			name: "should return ErrInvalidDataType when summing texts",
+			rows: mockedRows,
+			aggregation: Aggregation{
+				Aggregates: []Aggregate{{Column: aggregateColumn("SUM", ddl.ColumnDataTypeText), Function: AggregateFunctionSum, Expression: ColumnRef{Name: "kind"}}},
+			},
+			expectedError: ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrColumnNotFound when grouping by unknown columns",
+			rows:          mockedRows,
+			aggregation:   Aggregation{GroupBy: []string{"foo"}},
+			expectedError: ErrColumnNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			aggregator := NewAggregator(testCase.aggregation)
+
+			var err error
+			for _, row := range testCase.rows {
+				if err = aggregator.Add(row); err != nil {
+					break
+				}
+			}
+
+			var rows []dml.Row
+			if err == nil {
+				rows, err = aggregator.Rows()
+			}
+
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			var values [][]any
+			for _, row := range rows {
+				var rowValues []any
+				for _, column := range row.Columns {
+					rowValues = append(rowValues, column.Value)
+				}
+
+				values = append(values, rowValues)
+			}
+
+			if !slices.EqualFunc(values, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, values)
+				return
+			}
+		})
+	}
+}
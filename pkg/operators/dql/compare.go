@@ -0,0 +1,78 @@
+package dql
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// CompareValues Compares two values of a column, returning a negative number if a is lower
+// than b, zero if they are equal and a positive number otherwise. Integers and floats are
+// compared as numbers and NULLs are lower than any other value, values of any other pair
+// of types can not be compared and fail with [ErrInvalidDataType]
+func CompareValues(a, b any) (int, error) {
+	if a == nil || b == nil {
+		switch {
+		case a == b:
+			return 0, nil
+
+		case a == nil:
+			return -1, nil
+		}
+
+		return 1, nil
+	}
+
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), nil
+		}
+
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b), nil
+		}
+
+	default:
+		if result, ok := compareNumbers(a, b); ok {
+			return result, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: can not compare %T with %T", ErrInvalidDataType, a, b)
+}
+
+func compareNumbers(a, b any) (int, bool) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+
+	if va.CanInt() && vb.CanInt() {
+		return cmp.Compare(va.Int(), vb.Int()), true
+	}
+
+	fa, ok := numberAsFloat(va)
+	if !ok {
+		return 0, false
+	}
+
+	fb, ok := numberAsFloat(vb)
+	if !ok {
+		return 0, false
+	}
+
+	return cmp.Compare(fa, fb), true
+}
+
+func numberAsFloat(value reflect.Value) (float64, bool) {
+	switch {
+	case value.CanInt():
+		return float64(value.Int()), true
+
+	case value.CanFloat():
+		return value.Float(), true
+	}
+
+	return 0, false
+}
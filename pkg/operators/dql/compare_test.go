@@ -0,0 +1,69 @@
+package dql
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompareValues(t *testing.T) {
+	testCases := []struct {
+		name           string
+		a              any
+		b              any
+		expectedResult int
+		expectedError  error
+	}{
+		{
+			name:           "should compare integers as numbers",
+			a:              int64(9),
+			b:              int64(10),
+			expectedResult: -1,
+		},
+		{
+			name:           "should compare integers with floats",
+			a:              int64(2),
+			b:              1.5,
+			expectedResult: 1,
+		},
+		{
+			name:           "should compare equal strings",
+			a:              "foo",
+			b:              "foo",
+			expectedResult: 0,
+		},
+		{
+			name:           "should compare times",
+			a:              time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			b:              time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedResult: 1,
+		},
+		{
+			name:           "should compare NULL as lower than any value",
+			a:              nil,
+			b:              int64(-10),
+			expectedResult: -1,
+		},
+		{
+			name:          "should not compare strings with numbers",
+			a:             "10",
+			b:             int64(10),
+			expectedError: ErrInvalidDataType,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := CompareValues(testCase.a, testCase.b)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if result != testCase.expectedResult {
+				t.Errorf("expected %d, got %d", testCase.expectedResult, result)
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,490 @@
+package dql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+)
+
+var (
+	ErrDivisionByZero = errors.New("division by zero")
+)
+
+// ArithmeticOperator The operation of an [Arithmetic] expression
+type ArithmeticOperator string
+
+var (
+	ArithmeticOperatorAdd      ArithmeticOperator = "+"
+	ArithmeticOperatorSubtract ArithmeticOperator = "-"
+	ArithmeticOperatorMultiply ArithmeticOperator = "*"
+	ArithmeticOperatorDivide   ArithmeticOperator = "/"
+	ArithmeticOperatorModulo   ArithmeticOperator = "%"
+	ArithmeticOperatorConcat   ArithmeticOperator = "||"
+)
+
+// Expression A node of an expression tree evaluated against a row. Conditions evaluate to
+// true, false or nil, which is the unknown result of a condition over a NULL value
+type Expression interface {
+	Evaluate(row dml.Row) (any, error)
+}
+
+// ColumnRef Evaluates to the value of the column in the row
+type ColumnRef struct {
+	Name string
+}
+
+func (c ColumnRef) Evaluate(row dml.Row) (any, error) {
+	column, err := rowColumn(row, c.Name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, c.Name)
+	}
+
+	return column.Value, nil
+}
+
+// Literal Evaluates to its value
+type Literal struct {
+	Value any
+}
+
+func (l Literal) Evaluate(dml.Row) (any, error) {
+	return l.Value, nil
+}
+
+// And Is false if any side is false, unknown if any side is unknown and true otherwise
+type And struct {
+	Left  Expression
+	Right Expression
+}
+
+func (a And) Evaluate(row dml.Row) (any, error) {
+	left, right, err := evaluateConditions(row, a.Left, a.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case left == false || right == false:
+		return false, nil
+
+	case left == nil || right == nil:
+		return nil, nil
+	}
+
+	return true, nil
+}
+
+// Or Is true if any side is true, unknown if any side is unknown and false otherwise
+type Or struct {
+	Left  Expression
+	Right Expression
+}
+
+func (o Or) Evaluate(row dml.Row) (any, error) {
+	left, right, err := evaluateConditions(row, o.Left, o.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case left == true || right == true:
+		return true, nil
+
+	case left == nil || right == nil:
+		return nil, nil
+	}
+
+	return false, nil
+}
+
+// Not Negates the condition, the negation of unknown is unknown
+type Not struct {
+	Expression Expression
+}
+
+func (n Not) Evaluate(row dml.Row) (any, error) {
+	value, err := evaluateCondition(row, n.Expression)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	return !value.(bool), nil
+}
+
+// Comparison Compares both sides with one of =, <>, <, <=, > and >=, see [CompareValues]
+type Comparison struct {
+	Comparison FilterComparison
+	Left       Expression
+	Right      Expression
+}
+
+func (c Comparison) Evaluate(row dml.Row) (any, error) {
+	left, right, err := evaluateOperands(row, c.Left, c.Right)
+	if err != nil || left == nil || right == nil {
+		return nil, err
+	}
+
+	result, err := CompareValues(left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Comparison {
+	case FilterComparisonEquals:
+		return result == 0, nil
+
+	case FilterComparisonNotEquals:
+		return result != 0, nil
+
+	case FilterComparisonLess:
+		return result < 0, nil
+
+	case FilterComparisonLessOrEqual:
+		return result <= 0, nil
+
+	case FilterComparisonGreater:
+		return result > 0, nil
+
+	case FilterComparisonGreaterOrEqual:
+		return result >= 0, nil
+	}
+
+	return nil, fmt.Errorf("%w: comparison %s", ErrInvalidDataType, c.Comparison)
+}
+
+// Between Checks if the value is between the bounds, both bounds included
+type Between struct {
+	Expression Expression
+	Low        Expression
+	High       Expression
+}
+
+func (b Between) Evaluate(row dml.Row) (any, error) {
+	return And{
+		Left:  Comparison{Comparison: FilterComparisonGreaterOrEqual, Left: b.Expression, Right: b.Low},
+		Right: Comparison{Comparison: FilterComparisonLessOrEqual, Left: b.Expression, Right: b.High},
+	}.Evaluate(row)
+}
+
+// In Is true if the value is equal to any of the values, unknown if none is equal but the
+// value or any of the values is NULL, and false otherwise
+type In struct {
+	Expression Expression
+	Values     []Expression
+}
+
+func (i In) Evaluate(row dml.Row) (any, error) {
+	var result any = false
+	for _, value := range i.Values {
+		isEqual, err := Comparison{Comparison: FilterComparisonEquals, Left: i.Expression, Right: value}.Evaluate(row)
+		if err != nil {
+			return nil, err
+		}
+
+		switch isEqual {
+		case true:
+			return true, nil
+
+		case nil:
+			result = nil
+		}
+	}
+
+	return result, nil
+}
+
+// Like Matches the value against the pattern, where "%" matches any sequence of
+// characters and "_" matches a single character
+type Like struct {
+	Expression Expression
+	Pattern    Expression
+	IgnoreCase bool
+}
+
+func (l Like) Evaluate(row dml.Row) (any, error) {
+	value, pattern, err := evaluateOperands(row, l.Expression, l.Pattern)
+	if err != nil || value == nil || pattern == nil {
+		return nil, err
+	}
+
+	text, isText := value.(string)
+	patternText, isPatternText := pattern.(string)
+	if !isText || !isPatternText {
+		return nil, fmt.Errorf("%w: can not match %T with %T", ErrInvalidDataType, value, pattern)
+	}
+
+	if l.IgnoreCase {
+		text, patternText = strings.ToLower(text), strings.ToLower(patternText)
+	}
+
+	return matchesLikePattern([]rune(text), []rune(patternText)), nil
+}
+
+// IsNull Checks if the value is NULL, it is never unknown
+type IsNull struct {
+	Expression Expression
+}
+
+func (i IsNull) Evaluate(row dml.Row) (any, error) {
+	value, err := i.Expression.Evaluate(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return value == nil, nil
+}
+
+// Arithmetic Applies the operator to both sides, integers result in integers and any other
+// pair of numbers in floats, while || concatenates texts. It is NULL if any side is NULL
+type Arithmetic struct {
+	Operator ArithmeticOperator
+	Left     Expression
+	Right    Expression
+}
+
+func (a Arithmetic) Evaluate(row dml.Row) (any, error) {
+	left, right, err := evaluateOperands(row, a.Left, a.Right)
+	if err != nil || left == nil || right == nil {
+		return nil, err
+	}
+
+	if a.Operator == ArithmeticOperatorConcat {
+		leftText, isLeftText := left.(string)
+		rightText, isRightText := right.(string)
+		if !isLeftText || !isRightText {
+			return nil, fmt.Errorf("%w: can not concatenate %T with %T", ErrInvalidDataType, left, right)
+		}
+
+		return leftText + rightText, nil
+	}
+
+	leftValue, rightValue := reflect.ValueOf(left), reflect.ValueOf(right)
+	if leftValue.CanInt() && rightValue.CanInt() {
+		return integerArithmetic(a.Operator, leftValue.Int(), rightValue.Int())
+	}
+
+	leftFloat, isLeftNumber := numberAsFloat(leftValue)
+	rightFloat, isRightNumber := numberAsFloat(rightValue)
+	if !isLeftNumber || !isRightNumber {
+		return nil, fmt.Errorf("%w: can not apply %s to %T and %T", ErrInvalidDataType, a.Operator, left, right)
+	}
+
+	return floatArithmetic(a.Operator, leftFloat, rightFloat)
+}
+
+// integerArithmetic Computes the operation over two INTEGER values, failing with
+// [ddl.ErrValueOutOfRange] when the result does not fit in an INTEGER instead of wrapping
+func integerArithmetic(operator ArithmeticOperator, left, right int64) (any, error) {
+	overflow := fmt.Errorf("%w: %d %s %d", ddl.ErrValueOutOfRange, left, operator, right)
+
+	switch operator {
+	case ArithmeticOperatorAdd:
+		sum := left + right
+		if (left >= 0) == (right >= 0) && (sum >= 0) != (left >= 0) {
+			return nil, overflow
+		}
+
+		return sum, nil
+
+	case ArithmeticOperatorSubtract:
+		difference := left - right
+		if (left >= 0) != (right >= 0) && (difference >= 0) != (left >= 0) {
+			return nil, overflow
+		}
+
+		return difference, nil
+
+	case ArithmeticOperatorMultiply:
+		product := left * right
+		if left != 0 && (product/left != right || (left == -1 && right == math.MinInt64)) {
+			return nil, overflow
+		}
+
+		return product, nil
+
+	case ArithmeticOperatorDivide, ArithmeticOperatorModulo:
+		if right == 0 {
+			return nil, ErrDivisionByZero
+		}
+
+		if operator == ArithmeticOperatorDivide {
+			if left == math.MinInt64 && right == -1 {
+				return nil, overflow
+			}
+
+			return left / right, nil
+		}
+
+		return left % right, nil
+	}
+
+	return nil, fmt.Errorf("%w: operator %s", ErrInvalidDataType, operator)
+}
+
+func floatArithmetic(operator ArithmeticOperator, left, right float64) (any, error) {
+	switch operator {
+	case ArithmeticOperatorAdd:
+		return left + right, nil
+
+	case ArithmeticOperatorSubtract:
+		return left - right, nil
+
+	case ArithmeticOperatorMultiply:
+		return left * right, nil
+
+	case ArithmeticOperatorDivide, ArithmeticOperatorModulo:
+		if right == 0 {
+			return nil, ErrDivisionByZero
+		}
+
+		if operator == ArithmeticOperatorDivide {
+			return left / right, nil
+		}
+
+		return math.Mod(left, right), nil
+	}
+
+	return nil, fmt.Errorf("%w: operator %s", ErrInvalidDataType, operator)
+}
+
+// Negation Negates a number, it is NULL if the number is NULL
+type Negation struct {
+	Expression Expression
+}
+
+func (n Negation) Evaluate(row dml.Row) (any, error) {
+	value, err := n.Expression.Evaluate(row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	return Arithmetic{Operator: ArithmeticOperatorSubtract, Left: Literal{Value: int64(0)}, Right: Literal{Value: value}}.Evaluate(row)
+}
+
+// FilterExpression Evaluates a [Filter], ignoring its operand. Unless the filter is an IS
+// NULL comparison, it is unknown when the column is NULL or when it compares the column
+// with a NULL value, so NOT does not turn it into a match
+type FilterExpression struct {
+	Filter Filter
+}
+
+func (f FilterExpression) Evaluate(row dml.Row) (any, error) {
+	if f.Filter.Comparison != FilterComparisonIsNull {
+		if column, err := rowColumn(row, f.Filter.Column); err == nil && column.Value == nil {
+			return nil, nil
+		}
+
+		if f.Filter.Value == nil && filterComparesWithValue(f.Filter.Comparison) {
+			return nil, nil
+		}
+	}
+
+	return f.Filter.Where(row, f.Filter.Column, f.Filter.Value)
+}
+
+func filterComparesWithValue(comparison FilterComparison) bool {
+	switch comparison {
+	case FilterComparisonEquals,
+		FilterComparisonNotEquals,
+		FilterComparisonLess,
+		FilterComparisonLessOrEqual,
+		FilterComparisonGreater,
+		FilterComparisonGreaterOrEqual:
+		return true
+	}
+
+	return false
+}
+
+// FiltersExpression Returns the expression of a filter list, which is evaluated from left
+// to right
+func FiltersExpression(filters []Filter) Expression {
+	var expression Expression
+	for _, filter := range filters {
+		var condition Expression = FilterExpression{Filter: filter}
+		if filter.Operand == FilterOperandAndNot || filter.Operand == FilterOperandOrNot {
+			condition = Not{Expression: condition}
+		}
+
+		isOr := filter.Operand == FilterOperandOr || filter.Operand == FilterOperandOrNot
+
+		switch {
+		case expression == nil && !isOr:
+			expression = condition
+
+		case expression == nil:
+			expression = Or{Left: Literal{Value: true}, Right: condition}
+
+		case isOr:
+			expression = Or{Left: expression, Right: condition}
+
+		default:
+			expression = And{Left: expression, Right: condition}
+		}
+	}
+
+	if expression == nil {
+		return Literal{Value: true}
+	}
+
+	return expression
+}
+
+// Matches Checks if the condition is true for the row, an unknown condition does not match
+func Matches(where Expression, row dml.Row) (bool, error) {
+	value, err := evaluateCondition(row, where)
+	if err != nil {
+		return false, err
+	}
+
+	return value == true, nil
+}
+
+// evaluateCondition Evaluates an expression that must result in true, false or unknown
+func evaluateCondition(row dml.Row, expression Expression) (any, error) {
+	value, err := expression.Evaluate(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := value.(bool); !ok && value != nil {
+		return nil, fmt.Errorf("%w: expected a condition, got %T", ErrInvalidDataType, value)
+	}
+
+	return value, nil
+}
+
+// evaluateConditions Evaluates both conditions, without short circuiting, so an invalid
+// condition always fails
+func evaluateConditions(row dml.Row, left, right Expression) (any, any, error) {
+	leftValue, err := evaluateCondition(row, left)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rightValue, err := evaluateCondition(row, right)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return leftValue, rightValue, nil
+}
+
+func evaluateOperands(row dml.Row, left, right Expression) (any, any, error) {
+	leftValue, err := left.Evaluate(row)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rightValue, err := right.Evaluate(row)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return leftValue, rightValue, nil
+}
@@ -0,0 +1,268 @@
+package dql
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+)
+
+func TestExpressionEvaluate(t *testing.T) {
+	mockedRow := dml.Row{
+		Columns: []dml.Column{
+			{
+				Definition: ddl.Column{Name: "ID", DataType: ddl.ColumnDataTypeInteger},
+				Value:      int64(1),
+			},
+			{
+				Definition: ddl.Column{Name: "NAME", DataType: ddl.ColumnDataTypeText},
+				Value:      "Foo",
+			},
+			{
+				Definition: ddl.Column{Name: "PRICE", DataType: ddl.ColumnDataTypeFloat},
+				Value:      nil,
+			},
+		},
+	}
+
+	isOne := Comparison{Comparison: FilterComparisonEquals, Left: ColumnRef{Name: "id"}, Right: Literal{Value: int64(1)}}
+	isTwo := Comparison{Comparison: FilterComparisonEquals, Left: ColumnRef{Name: "id"}, Right: Literal{Value: int64(2)}}
+	isCheap := Comparison{Comparison: FilterComparisonLess, Left: ColumnRef{Name: "price"}, Right: Literal{Value: 5.0}}
+
+	testCases := []struct {
+		name          string
+		expression    Expression
+		expectedValue any
+		expectedError error
+	}{
+		{
+			name:          "should compare a column with a literal",
+			expression:    isOne,
+			expectedValue: true,
+		},
+		{
+			name:          "should compare integers with floats as numbers",
+			expression:    Comparison{Comparison: FilterComparisonLess, Left: ColumnRef{Name: "id"}, Right: Literal{Value: 1.5}},
+			expectedValue: true,
+		},
+		{
+			name:          "should be unknown when comparing NULL",
+			expression:    isCheap,
+			expectedValue: nil,
+		},
+		{
+			name:          "should be false when AND has a false side, even if the other is unknown",
+			expression:    And{Left: isCheap, Right: isTwo},
+			expectedValue: false,
+		},
+		{
+			name:          "should be unknown when AND has an unknown side and no false one",
+			expression:    And{Left: isOne, Right: isCheap},
+			expectedValue: nil,
+		},
+		{
+			name:          "should be true when OR has a true side, even if the other is unknown",
+			expression:    Or{Left: isCheap, Right: isOne},
+			expectedValue: true,
+		},
+		{
+			name:          "should be unknown when OR has an unknown side and no true one",
+			expression:    Or{Left: isTwo, Right: isCheap},
+			expectedValue: nil,
+		},
+		{
+			name:          "should keep unknown when negated",
+			expression:    Not{Expression: isCheap},
+			expectedValue: nil,
+		},
+		{
+			name:          "should negate known conditions",
+			expression:    Not{Expression: isTwo},
+			expectedValue: true,
+		},
+		{
+			name:          "should be unknown when NOT IN has a NULL value",
+			expression:    Not{Expression: In{Expression: ColumnRef{Name: "id"}, Values: []Expression{Literal{Value: int64(2)}, Literal{}}}},
+			expectedValue: nil,
+		},
+		{
+			name:          "should be true when IN has an equal value besides a NULL one",
+			expression:    In{Expression: ColumnRef{Name: "id"}, Values: []Expression{Literal{}, Literal{Value: int64(1)}}},
+			expectedValue: true,
+		},
+		{
+			name:          "should check NULL without being unknown",
+			expression:    IsNull{Expression: ColumnRef{Name: "price"}},
+			expectedValue: true,
+		},
+		{
+			name:          "should match LIKE patterns ignoring case",
+			expression:    Like{Expression: ColumnRef{Name: "name"}, Pattern: Literal{Value: "f%"}, IgnoreCase: true},
+			expectedValue: true,
+		},
+		{
+			name:          "should check BETWEEN with both bounds included",
+			expression:    Between{Expression: ColumnRef{Name: "id"}, Low: Literal{Value: int64(0)}, High: Literal{Value: int64(1)}},
+			expectedValue: true,
+		},
+		{
+			name:          "should compute integers as integers",
+			expression:    Arithmetic{Operator: ArithmeticOperatorDivide, Left: Literal{Value: int64(7)}, Right: ColumnRef{Name: "id"}},
+			expectedValue: int64(7),
+		},
+		{
+			name:          "should compute integers at the edge of the INTEGER range",
+			expression:    Arithmetic{Operator: ArithmeticOperatorMultiply, Left: Literal{Value: int64(math.MinInt64 / 2)}, Right: Literal{Value: int64(2)}},
+			expectedValue: int64(math.MinInt64),
+		},
+		{
+			name:          "should compute integers with floats as floats",
+			expression:    Arithmetic{Operator: ArithmeticOperatorAdd, Left: ColumnRef{Name: "id"}, Right: Literal{Value: 0.5}},
+			expectedValue: 1.5,
+		},
+		{
+			name:          "should be NULL when computing NULL",
+			expression:    Negation{Expression: Arithmetic{Operator: ArithmeticOperatorMultiply, Left: ColumnRef{Name: "price"}, Right: Literal{Value: 2.0}}},
+			expectedValue: nil,
+		},
+		{
+			name:          "should return ErrDivisionByZero when dividing by zero",
+			expression:    Arithmetic{Operator: ArithmeticOperatorModulo, Left: ColumnRef{Name: "id"}, Right: Literal{Value: int64(0)}},
+			expectedError: ErrDivisionByZero,
+		},
+		{
+			name:          "should return ErrValueOutOfRange when adding past the INTEGER range",
+			expression:    Arithmetic{Operator: ArithmeticOperatorAdd, Left: Literal{Value: int64(math.MaxInt64)}, Right: ColumnRef{Name: "id"}},
+			expectedError: ddl.ErrValueOutOfRange,
+		},
+		{
+			name:          "should return ErrValueOutOfRange when subtracting past the INTEGER range",
+			expression:    Arithmetic{Operator: ArithmeticOperatorSubtract, Left: Literal{Value: int64(math.MinInt64)}, Right: ColumnRef{Name: "id"}},
+			expectedError: ddl.ErrValueOutOfRange,
+		},
+		{
+			name:          "should return ErrValueOutOfRange when multiplying past the INTEGER range",
+			expression:    Arithmetic{Operator: ArithmeticOperatorMultiply, Left: Literal{Value: int64(math.MinInt64)}, Right: Literal{Value: int64(-1)}},
+			expectedError: ddl.ErrValueOutOfRange,
+		},
+		{
+			name:          "should return ErrValueOutOfRange when dividing past the INTEGER range",
+			expression:    Arithmetic{Operator: ArithmeticOperatorDivide, Left: Literal{Value: int64(math.MinInt64)}, Right: Literal{Value: int64(-1)}},
+			expectedError: ddl.ErrValueOutOfRange,
+		},
+		{
+			name:          "should return ErrColumnNotFound for unknown columns",
+			expression:    Or{Left: isOne, Right: Comparison{Comparison: FilterComparisonEquals, Left: ColumnRef{Name: "foo"}, Right: Literal{Value: int64(1)}}},
+			expectedError: ErrColumnNotFound,
+		},
+		{
+			name:          "should return ErrInvalidDataType when comparing values of another type",
+			expression:    Comparison{Comparison: FilterComparisonEquals, Left: ColumnRef{Name: "name"}, Right: Literal{Value: int64(1)}},
+			expectedError: ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType when combining values that are not conditions",
+			expression:    And{Left: isOne, Right: ColumnRef{Name: "name"}},
+			expectedError: ErrInvalidDataType,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			value, err := testCase.expression.Evaluate(mockedRow)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if value != testCase.expectedValue {
+				t.Errorf("expected value %v, got %v", testCase.expectedValue, value)
+				return
+			}
+		})
+	}
+}
+
+func TestFiltersExpression(t *testing.T) {
+	mockedRow := dml.Row{
+		Columns: []dml.Column{
+			{
+				Definition: ddl.Column{Name: "NAME", DataType: ddl.ColumnDataTypeText},
+				Value:      "Foo",
+			},
+			{
+				Definition: ddl.Column{Name: "PRICE", DataType: ddl.ColumnDataTypeFloat},
+				Value:      nil,
+			},
+		},
+	}
+
+	isFoo := Filter{Column: "name", Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "Foo"}
+	isBar := Filter{Column: "name", Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "Bar"}
+	isNull := Filter{Column: "name", Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: nil}
+	isCheap := Filter{Column: "price", Comparison: FilterComparisonLess, Where: WhereColumnLess, Value: 10.0}
+	priceIsNull := Filter{Column: "price", Comparison: FilterComparisonIsNull, Where: WhereColumnIsNull}
+
+	withOperand := func(filter Filter, operand FilterOperand) Filter {
+		filter.Operand = operand
+		return filter
+	}
+
+	testCases := []struct {
+		name          string
+		filters       []Filter
+		expectedValue bool
+	}{
+		{
+			name:          "should match without filters",
+			expectedValue: true,
+		},
+		{
+			name:          "should evaluate the filters from left to right",
+			filters:       []Filter{withOperand(isBar, FilterOperandAnd), withOperand(isBar, FilterOperandAnd), withOperand(isFoo, FilterOperandOr)},
+			expectedValue: true,
+		},
+		{
+			name:          "should not give AND precedence over a previous OR",
+			filters:       []Filter{withOperand(isFoo, FilterOperandAnd), withOperand(isFoo, FilterOperandOr), withOperand(isBar, FilterOperandAnd)},
+			expectedValue: false,
+		},
+		{
+			name:          "should negate AND NOT and OR NOT filters",
+			filters:       []Filter{withOperand(isFoo, FilterOperandAndNot), withOperand(isBar, FilterOperandOrNot)},
+			expectedValue: true,
+		},
+		{
+			name:          "should not match negated filters over NULL columns",
+			filters:       []Filter{withOperand(isCheap, FilterOperandAndNot)},
+			expectedValue: false,
+		},
+		{
+			name:          "should not match negated comparisons with NULL",
+			filters:       []Filter{withOperand(isNull, FilterOperandAndNot)},
+			expectedValue: false,
+		},
+		{
+			name:          "should match NULL columns with IS NULL filters",
+			filters:       []Filter{withOperand(priceIsNull, FilterOperandAnd), withOperand(isFoo, FilterOperandAnd)},
+			expectedValue: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			value, err := Matches(FiltersExpression(testCase.filters), mockedRow)
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if value != testCase.expectedValue {
+				t.Errorf("expected value %v, got %v", testCase.expectedValue, value)
+				return
+			}
+		})
+	}
+}
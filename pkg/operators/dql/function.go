@@ -0,0 +1,574 @@
+package dql
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+)
+
+// ScalarFunction A function called with the values of its arguments for each row. Unless
+// CallsOnNull, a call with a NULL argument is NULL without calling it
+type ScalarFunction struct {
+	Call        func(arguments []any) (any, error)
+	CallsOnNull bool
+}
+
+// BuiltinFunctions The scalar functions callable in every expression, by their name.
+// Timestamps are truncated and split into fields in their own zone
+var BuiltinFunctions = map[string]ScalarFunction{
+	"UPPER":      {Call: textFunction(strings.ToUpper)},
+	"LOWER":      {Call: textFunction(strings.ToLower)},
+	"TRIM":       {Call: trim},
+	"SUBSTR":     {Call: substr},
+	"LENGTH":     {Call: length},
+	"CONCAT":     {Call: concat, CallsOnNull: true},
+	"REPLACE":    {Call: replace},
+	"ABS":        {Call: abs},
+	"ROUND":      {Call: round},
+	"FLOOR":      {Call: numberFunction(func(i int64) int64 { return i }, math.Floor)},
+	"CEIL":       {Call: numberFunction(func(i int64) int64 { return i }, math.Ceil)},
+	"MOD":        {Call: mod},
+	"NOW":        {Call: now},
+	"DATE_TRUNC": {Call: dateTrunc},
+	"EXTRACT":    {Call: extract},
+	"COALESCE":   {Call: coalesce, CallsOnNull: true},
+	"NULLIF":     {Call: nullIf, CallsOnNull: true},
+}
+
+// FunctionCall Calls the function with the values of the arguments
+type FunctionCall struct {
+	Name      string
+	Function  ScalarFunction
+	Arguments []Expression
+}
+
+func (f FunctionCall) Evaluate(row dml.Row) (any, error) {
+	arguments := make([]any, 0, len(f.Arguments))
+	for _, argument := range f.Arguments {
+		value, err := argument.Evaluate(row)
+		if err != nil {
+			return nil, err
+		}
+
+		if value == nil && !f.Function.CallsOnNull {
+			return nil, nil
+		}
+
+		arguments = append(arguments, value)
+	}
+
+	return f.Function.Call(arguments)
+}
+
+// When A condition of a [Case] and its result
+type When struct {
+	Condition Expression
+	Result    Expression
+}
+
+// Case Evaluates to the result of the first when whose condition is true, or to Else if
+// there is none, NULL without Else
+type Case struct {
+	Whens []When
+	Else  Expression
+}
+
+func (c Case) Evaluate(row dml.Row) (any, error) {
+	for _, when := range c.Whens {
+		matches, err := Matches(when.Condition, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			return when.Result.Evaluate(row)
+		}
+	}
+
+	if c.Else == nil {
+		return nil, nil
+	}
+
+	return c.Else.Evaluate(row)
+}
+
+// Widen Converts integers to floats, for INTEGER values used as FLOAT values, failing
+// with [ddl.ErrValueOutOfRange] for integers that are not exactly a float
+type Widen struct {
+	Expression Expression
+}
+
+func (w Widen) Evaluate(row dml.Row) (any, error) {
+	value, err := w.Expression.Evaluate(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if number := reflect.ValueOf(value); number.CanInt() {
+		widened, err := ddl.ValueForDataType(number.Int(), ddl.ColumnDataTypeFloat)
+		if err != nil {
+			return nil, err
+		}
+
+		return widened, nil
+	}
+
+	return value, nil
+}
+
+// Cast Converts the value of the expression to a value of the type. Numbers are rounded
+// half away from zero to INTEGER, INTEGER values become FLOAT, any value can become TEXT
+// and TEXT values are parsed as any type, see [ddl.ParseTimestamp]. NULL stays NULL
+type Cast struct {
+	Expression Expression
+	DataType   ddl.ColumnDataType
+}
+
+func (c Cast) Evaluate(row dml.Row) (any, error) {
+	value, err := c.Expression.Evaluate(row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	text, isText := value.(string)
+	if isText && c.DataType != ddl.ColumnDataTypeText {
+		return parsedValue(strings.TrimSpace(text), c.DataType)
+	}
+
+	switch c.DataType {
+	case ddl.ColumnDataTypeText:
+		return textValue(value)
+
+	case ddl.ColumnDataTypeInteger:
+		if number, isFloat := value.(float64); isFloat {
+			rounded := math.Round(number)
+			if !(rounded >= math.MinInt64 && rounded < math.MaxInt64) {
+				return nil, fmt.Errorf("%w: %v is out of range for %s", ErrInvalidDataType, number, c.DataType)
+			}
+
+			return int64(rounded), nil
+		}
+
+	case ddl.ColumnDataTypeFloat:
+		if number, isInteger := value.(int64); isInteger {
+			return float64(number), nil
+		}
+	}
+
+	if value, err := ddl.ValueForDataType(value, c.DataType); err == nil {
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("%w: can not cast %T to %s", ErrInvalidDataType, value, c.DataType)
+}
+
+// parsedValue Parses a text as a value of the type
+func parsedValue(text string, dataType ddl.ColumnDataType) (any, error) {
+	var value any
+	var err error
+
+	switch dataType {
+	case ddl.ColumnDataTypeInteger:
+		value, err = strconv.ParseInt(text, 10, 64)
+
+	case ddl.ColumnDataTypeFloat:
+		value, err = strconv.ParseFloat(text, 64)
+
+	case ddl.ColumnDataTypeTimestamp:
+		value, err = ddl.ParseTimestamp(text)
+
+	default:
+		return nil, fmt.Errorf("%w: can not cast TEXT to %s", ErrInvalidDataType, dataType)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDataType, err.Error())
+	}
+
+	return value, nil
+}
+
+// textValue Formats a value as a text, timestamps as RFC 3339 timestamps with nanoseconds
+func textValue(value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+
+	case time.Time:
+		return value.Format(time.RFC3339Nano), nil
+	}
+
+	return "", fmt.Errorf("%w: can not format %T as a text", ErrInvalidDataType, value)
+}
+
+func textFunction(fn func(text string) string) func(arguments []any) (any, error) {
+	return func(arguments []any) (any, error) {
+		text, err := textArgument(arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(text), nil
+	}
+}
+
+// trim Removes the spaces, or the characters of its second argument, around a text
+func trim(arguments []any) (any, error) {
+	text, err := textArgument(arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(arguments) < 2 {
+		return strings.TrimSpace(text), nil
+	}
+
+	characters, err := textArgument(arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Trim(text, characters), nil
+}
+
+// substr Returns the characters of a text from a position, starting at 1, up to a length
+// if given. The positions before the first character count towards the length
+func substr(arguments []any) (any, error) {
+	text, err := textArgument(arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := integerArgument(arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	characters := []rune(text)
+	end := int64(len(characters))
+	if len(arguments) > 2 {
+		length, err := integerArgument(arguments, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		if length < 0 {
+			return nil, fmt.Errorf("%w: negative substring length %d", ErrInvalidDataType, length)
+		}
+
+		end = min(start-1+length, end)
+	}
+
+	start = min(max(start-1, 0), int64(len(characters)))
+	if end <= start {
+		return "", nil
+	}
+
+	return string(characters[start:end]), nil
+}
+
+// length Returns the number of characters of a text
+func length(arguments []any) (any, error) {
+	text, err := textArgument(arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return int64(utf8.RuneCountInString(text)), nil
+}
+
+// concat Concatenates the texts of its arguments, ignoring NULLs, see [Cast]
+func concat(arguments []any) (any, error) {
+	builder := strings.Builder{}
+	for _, argument := range arguments {
+		if argument == nil {
+			continue
+		}
+
+		text, err := textValue(argument)
+		if err != nil {
+			return nil, err
+		}
+
+		builder.WriteString(text)
+	}
+
+	return builder.String(), nil
+}
+
+// replace Replaces every occurrence of a text in a text by another one
+func replace(arguments []any) (any, error) {
+	texts := make([]string, 0, 3)
+	for i := range 3 {
+		text, err := textArgument(arguments, i)
+		if err != nil {
+			return nil, err
+		}
+
+		texts = append(texts, text)
+	}
+
+	return strings.ReplaceAll(texts[0], texts[1], texts[2]), nil
+}
+
+func numberFunction(integerFn func(int64) int64, floatFn func(float64) float64) func(arguments []any) (any, error) {
+	return func(arguments []any) (any, error) {
+		switch value := argumentAt(arguments, 0).(type) {
+		case int64:
+			return integerFn(value), nil
+
+		case float64:
+			return floatFn(value), nil
+		}
+
+		return nil, fmt.Errorf("%w: expected a number, got %T", ErrInvalidDataType, argumentAt(arguments, 0))
+	}
+}
+
+// abs Returns the absolute value of a number, failing for the smallest INTEGER, whose
+// absolute value does not fit in an INTEGER
+func abs(arguments []any) (any, error) {
+	if value, isInteger := argumentAt(arguments, 0).(int64); isInteger && value == math.MinInt64 {
+		return nil, fmt.Errorf("%w: ABS(%d)", ddl.ErrValueOutOfRange, value)
+	}
+
+	return numberFunction(func(i int64) int64 { return max(i, -i) }, math.Abs)(arguments)
+}
+
+// round Rounds a number half away from zero to a number of decimal places, 0 if not given,
+// which can be negative to round to tens, hundreds and so on
+func round(arguments []any) (any, error) {
+	places := int64(0)
+	if len(arguments) > 1 {
+		var err error
+		if places, err = integerArgument(arguments, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	switch value := argumentAt(arguments, 0).(type) {
+	case int64:
+		switch {
+		case places >= 0:
+			return value, nil
+
+		case places < -18:
+			return int64(0), nil
+		}
+
+		// Integers are rounded without converting them to floats, which would lose the
+		// digits past 2^53
+		scale := int64(math.Pow10(int(-places)))
+		rounded, remainder := value/scale, value%scale
+		switch {
+		case remainder*2 >= scale:
+			rounded++
+
+		case remainder*2 <= -scale:
+			rounded--
+		}
+
+		return integerArithmetic(ArithmeticOperatorMultiply, rounded, scale)
+
+	case float64:
+		scale := math.Pow10(int(places))
+		return math.Round(value*scale) / scale, nil
+	}
+
+	return nil, fmt.Errorf("%w: expected a number, got %T", ErrInvalidDataType, argumentAt(arguments, 0))
+}
+
+// mod Returns the remainder of the division of two numbers, see [Arithmetic]
+func mod(arguments []any) (any, error) {
+	return Arithmetic{
+		Operator: ArithmeticOperatorModulo,
+		Left:     Literal{Value: argumentAt(arguments, 0)},
+		Right:    Literal{Value: argumentAt(arguments, 1)},
+	}.Evaluate(dml.Row{})
+}
+
+// now Returns the current time, in UTC
+func now([]any) (any, error) {
+	return time.Now().UTC(), nil
+}
+
+// dateTrunc Truncates a timestamp to the start of its second, minute, hour, day, week,
+// month, quarter or year
+func dateTrunc(arguments []any) (any, error) {
+	unit, err := textArgument(arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := timestampArgument(arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	year, month, day := timestamp.Date()
+	hour, minute, second := timestamp.Clock()
+	zone := timestamp.Location()
+
+	switch strings.ToUpper(unit) {
+	case "SECOND":
+		return time.Date(year, month, day, hour, minute, second, 0, zone), nil
+
+	case "MINUTE":
+		return time.Date(year, month, day, hour, minute, 0, 0, zone), nil
+
+	case "HOUR":
+		return time.Date(year, month, day, hour, 0, 0, 0, zone), nil
+
+	case "DAY":
+		return time.Date(year, month, day, 0, 0, 0, 0, zone), nil
+
+	case "WEEK":
+		daysSinceMonday := (int(timestamp.Weekday()) + 6) % 7
+		return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, zone), nil
+
+	case "MONTH":
+		return time.Date(year, month, 1, 0, 0, 0, 0, zone), nil
+
+	case "QUARTER":
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, zone), nil
+
+	case "YEAR":
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, zone), nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown unit %s", ErrInvalidDataType, unit)
+}
+
+// extract Returns a field of a timestamp, which is its YEAR, QUARTER, MONTH, WEEK of the
+// ISO year, DAY, DOW (the day of the week from Sunday as 0), DOY (the day of the year),
+// HOUR, MINUTE, SECOND or EPOCH (the seconds since 1970-01-01)
+func extract(arguments []any) (any, error) {
+	field, err := textArgument(arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := timestampArgument(arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	var value int
+	switch strings.ToUpper(field) {
+	case "YEAR":
+		value = timestamp.Year()
+
+	case "QUARTER":
+		value = (int(timestamp.Month())-1)/3 + 1
+
+	case "MONTH":
+		value = int(timestamp.Month())
+
+	case "WEEK":
+		_, value = timestamp.ISOWeek()
+
+	case "DAY":
+		value = timestamp.Day()
+
+	case "DOW":
+		value = int(timestamp.Weekday())
+
+	case "DOY":
+		value = timestamp.YearDay()
+
+	case "HOUR":
+		value = timestamp.Hour()
+
+	case "MINUTE":
+		value = timestamp.Minute()
+
+	case "SECOND":
+		value = timestamp.Second()
+
+	case "EPOCH":
+		return timestamp.Unix(), nil
+
+	default:
+		return nil, fmt.Errorf("%w: unknown field %s", ErrInvalidDataType, field)
+	}
+
+	return int64(value), nil
+}
+
+// coalesce Returns its first argument that is not NULL
+func coalesce(arguments []any) (any, error) {
+	for _, argument := range arguments {
+		if argument != nil {
+			return argument, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// nullIf Returns NULL if its arguments are equal, or its first argument otherwise
+func nullIf(arguments []any) (any, error) {
+	value, other := argumentAt(arguments, 0), argumentAt(arguments, 1)
+	if value == nil || other == nil {
+		return value, nil
+	}
+
+	result, err := CompareValues(value, other)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == 0 {
+		return nil, nil
+	}
+
+	return value, nil
+}
+
+func argumentAt(arguments []any, i int) any {
+	if i >= len(arguments) {
+		return nil
+	}
+
+	return arguments[i]
+}
+
+func textArgument(arguments []any, i int) (string, error) {
+	text, isText := argumentAt(arguments, i).(string)
+	if !isText {
+		return "", fmt.Errorf("%w: expected a text, got %T", ErrInvalidDataType, argumentAt(arguments, i))
+	}
+
+	return text, nil
+}
+
+func integerArgument(arguments []any, i int) (int64, error) {
+	integer, isInteger := argumentAt(arguments, i).(int64)
+	if !isInteger {
+		return 0, fmt.Errorf("%w: expected an integer, got %T", ErrInvalidDataType, argumentAt(arguments, i))
+	}
+
+	return integer, nil
+}
+
+func timestampArgument(arguments []any, i int) (time.Time, error) {
+	timestamp, isTimestamp := argumentAt(arguments, i).(time.Time)
+	if !isTimestamp {
+		return time.Time{}, fmt.Errorf("%w: expected a timestamp, got %T", ErrInvalidDataType, argumentAt(arguments, i))
+	}
+
+	return timestamp, nil
+}
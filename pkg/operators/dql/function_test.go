@@ -0,0 +1,273 @@
+package dql
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+)
+
+func TestFunctionCall(t *testing.T) {
+	mockedRow := dml.Row{
+		Columns: []dml.Column{
+			{
+				Definition: ddl.Column{Name: "NAME", DataType: ddl.ColumnDataTypeText},
+				Value:      "  Café ",
+			},
+			{
+				Definition: ddl.Column{Name: "CREATED_AT", DataType: ddl.ColumnDataTypeTimestamp},
+				Value:      time.Date(2024, time.February, 29, 13, 45, 30, 0, time.UTC),
+			},
+			{
+				Definition: ddl.Column{Name: "PRICE", DataType: ddl.ColumnDataTypeFloat},
+				Value:      nil,
+			},
+		},
+	}
+
+	call := func(name string, arguments ...Expression) FunctionCall {
+		return FunctionCall{Name: name, Function: BuiltinFunctions[name], Arguments: arguments}
+	}
+
+	india := time.FixedZone("IST", 5*60*60+30*60)
+	name := ColumnRef{Name: "name"}
+	createdAt := ColumnRef{Name: "created_at"}
+	price := ColumnRef{Name: "price"}
+
+	testCases := []struct {
+		name          string
+		expression    Expression
+		expectedValue any
+		expectedError error
+	}{
+		{
+			name:          "should trim and upper case texts",
+			expression:    call("UPPER", call("TRIM", name)),
+			expectedValue: "CAFÉ",
+		},
+		{
+			name:          "should count characters instead of bytes",
+			expression:    call("LENGTH", call("TRIM", name)),
+			expectedValue: int64(4),
+		},
+		{
+			name:          "should take substrings from positions before the first character",
+			expression:    call("SUBSTR", Literal{Value: "abcdef"}, Literal{Value: int64(-1)}, Literal{Value: int64(4)}),
+			expectedValue: "ab",
+		},
+		{
+			name:          "should concatenate numbers and ignore NULLs",
+			expression:    call("CONCAT", Literal{Value: "n"}, price, Literal{Value: int64(1)}, Literal{Value: 2.5}),
+			expectedValue: "n12.5",
+		},
+		{
+			name:          "should replace every occurrence of a text",
+			expression:    call("REPLACE", Literal{Value: "a-b-c"}, Literal{Value: "-"}, Literal{Value: "+"}),
+			expectedValue: "a+b+c",
+		},
+		{
+			name:          "should round half away from zero",
+			expression:    call("ROUND", Literal{Value: -2.345}, Literal{Value: int64(2)}),
+			expectedValue: -2.35,
+		},
+		{
+			name:          "should round integers to tens",
+			expression:    call("ROUND", Literal{Value: int64(125)}, Literal{Value: int64(-1)}),
+			expectedValue: int64(130),
+		},
+		{
+			name:          "should round negative integers half away from zero",
+			expression:    call("ROUND", Literal{Value: int64(-125)}, Literal{Value: int64(-1)}),
+			expectedValue: int64(-130),
+		},
+		{
+			name:          "should round integers past 2^53 without losing digits",
+			expression:    call("ROUND", Literal{Value: int64(9007199254740993)}, Literal{Value: int64(-1)}),
+			expectedValue: int64(9007199254740990),
+		},
+		{
+			name:          "should keep the sign of the dividend in MOD",
+			expression:    call("MOD", Literal{Value: int64(-7)}, Literal{Value: int64(3)}),
+			expectedValue: int64(-1),
+		},
+		{
+			name:          "should truncate timestamps to the monday of their week",
+			expression:    call("DATE_TRUNC", Literal{Value: "week"}, createdAt),
+			expectedValue: time.Date(2024, time.February, 26, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "should truncate timestamps in their own zone",
+			expression:    call("DATE_TRUNC", Literal{Value: "day"}, Literal{Value: time.Date(2024, time.March, 1, 1, 30, 0, 0, india)}),
+			expectedValue: time.Date(2024, time.March, 1, 0, 0, 0, 0, india),
+		},
+		{
+			name:          "should extract the day of the year",
+			expression:    call("EXTRACT", Literal{Value: "DOY"}, createdAt),
+			expectedValue: int64(60),
+		},
+		{
+			name:          "should be NULL when an argument is NULL",
+			expression:    call("ABS", price),
+			expectedValue: nil,
+		},
+		{
+			name:          "should return the first argument that is not NULL",
+			expression:    call("COALESCE", price, Widen{Expression: Literal{Value: int64(0)}}),
+			expectedValue: 0.0,
+		},
+		{
+			name:          "should be NULL when the arguments of NULLIF are equal",
+			expression:    call("NULLIF", Literal{Value: int64(1)}, Literal{Value: 1.0}),
+			expectedValue: nil,
+		},
+		{
+			name: "should evaluate to the result of the first matching WHEN",
+			expression: Case{
+				Whens: []When{
+					{Condition: Comparison{Comparison: FilterComparisonGreater, Left: price, Right: Literal{Value: 1.0}}, Result: Literal{Value: "expensive"}},
+					{Condition: Comparison{Comparison: FilterComparisonNotEquals, Left: name, Right: Literal{Value: ""}}, Result: Literal{Value: "named"}},
+				},
+				Else: Literal{Value: "other"},
+			},
+			expectedValue: "named",
+		},
+		{
+			name:          "should evaluate to NULL without a matching WHEN nor ELSE",
+			expression:    Case{Whens: []When{{Condition: Literal{Value: false}, Result: Literal{Value: int64(1)}}}},
+			expectedValue: nil,
+		},
+		{
+			name:          "should return ErrInvalidDataType for unknown units",
+			expression:    call("DATE_TRUNC", Literal{Value: "fortnight"}, createdAt),
+			expectedError: ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for arguments of another type",
+			expression:    call("UPPER", Literal{Value: int64(1)}),
+			expectedError: ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrValueOutOfRange when widening integers that are not exactly a float",
+			expression:    Widen{Expression: Literal{Value: int64(1<<53 + 1)}},
+			expectedError: ddl.ErrValueOutOfRange,
+		},
+		{
+			name:          "should return ErrValueOutOfRange for the absolute value of the smallest INTEGER",
+			expression:    call("ABS", Literal{Value: int64(math.MinInt64)}),
+			expectedError: ddl.ErrValueOutOfRange,
+		},
+		{
+			name:          "should return ErrValueOutOfRange when rounding past the INTEGER range",
+			expression:    call("ROUND", Literal{Value: int64(math.MaxInt64)}, Literal{Value: int64(-1)}),
+			expectedError: ddl.ErrValueOutOfRange,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			value, err := testCase.expression.Evaluate(mockedRow)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if value != testCase.expectedValue {
+				t.Errorf("expected value %v, got %v", testCase.expectedValue, value)
+				return
+			}
+		})
+	}
+}
+
+func TestCast(t *testing.T) {
+	timestamp := time.Date(2024, time.February, 29, 13, 45, 30, 500, time.FixedZone("", -3*60*60))
+
+	testCases := []struct {
+		name          string
+		value         any
+		dataType      ddl.ColumnDataType
+		expectedValue any
+		expectedError error
+	}{
+		{
+			name:          "should round floats half away from zero to integers",
+			value:         -2.5,
+			dataType:      ddl.ColumnDataTypeInteger,
+			expectedValue: int64(-3),
+		},
+		{
+			name:          "should widen integers to floats",
+			value:         int64(3),
+			dataType:      ddl.ColumnDataTypeFloat,
+			expectedValue: float64(3),
+		},
+		{
+			name:          "should parse texts around spaces",
+			value:         " 42 ",
+			dataType:      ddl.ColumnDataTypeInteger,
+			expectedValue: int64(42),
+		},
+		{
+			name:          "should parse timestamps with nanoseconds and zones",
+			value:         "2024-02-29T13:45:30.000000500-03:00",
+			dataType:      ddl.ColumnDataTypeTimestamp,
+			expectedValue: timestamp,
+		},
+		{
+			name:          "should format timestamps as RFC 3339 texts",
+			value:         timestamp,
+			dataType:      ddl.ColumnDataTypeText,
+			expectedValue: "2024-02-29T13:45:30.0000005-03:00",
+		},
+		{
+			name:          "should keep NULL",
+			value:         nil,
+			dataType:      ddl.ColumnDataTypeTimestamp,
+			expectedValue: nil,
+		},
+		{
+			name:          "should return ErrInvalidDataType for texts that are not numbers",
+			value:         "4 2",
+			dataType:      ddl.ColumnDataTypeInteger,
+			expectedError: ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for floats out of the range of integers",
+			value:         1e19,
+			dataType:      ddl.ColumnDataTypeInteger,
+			expectedError: ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for timestamps cast to integers",
+			value:         timestamp,
+			dataType:      ddl.ColumnDataTypeInteger,
+			expectedError: ErrInvalidDataType,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			value, err := Cast{Expression: Literal{Value: testCase.value}, DataType: testCase.dataType}.Evaluate(dml.Row{})
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if timestamp, isTimestamp := value.(time.Time); isTimestamp {
+				if !timestamp.Equal(testCase.expectedValue.(time.Time)) || timestamp.Format(time.RFC3339Nano) != testCase.expectedValue.(time.Time).Format(time.RFC3339Nano) {
+					t.Errorf("expected value %v, got %v", testCase.expectedValue, value)
+				}
+
+				return
+			}
+
+			if value != testCase.expectedValue {
+				t.Errorf("expected value %v, got %v", testCase.expectedValue, value)
+				return
+			}
+		})
+	}
+}
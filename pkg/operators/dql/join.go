@@ -0,0 +1,420 @@
+package dql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
+)
+
+// JoinType How a [Join] combines rows
+type JoinType string
+
+var (
+	JoinTypeInner JoinType = "INNER"
+	JoinTypeLeft  JoinType = "LEFT"
+	JoinTypeRight JoinType = "RIGHT"
+	JoinTypeCross JoinType = "CROSS"
+)
+
+// Join A table joined to the rows of the tables before it. INNER joins each row with the
+// rows of the table for which On is true, LEFT also keeps the rows without any, with NULL
+// columns for the table, and RIGHT also keeps the rows of the table without any, with NULL
+// columns for the tables before it. CROSS joins each row with every row of the table. The
+// columns of joined rows are named ALIAS.COLUMN, with the alias of their table. The table
+// is the Source common table if any
+type Join struct {
+	Type     JoinType
+	Database string
+	Table    string
+	Source   *CommonTable
+	Alias    string
+	On       Expression
+}
+
+// joinedRows The rows joined so far, with the columns of every joined table
+type joinedRows struct {
+	columns []ddl.Column
+	rows    []dml.Row
+}
+
+// joinCandidate A row of the joined table that may match a row, with its position in the
+// rows of the table if they were all read
+type joinCandidate struct {
+	row   dml.Row
+	index int
+}
+
+// scanJoinedRows Calls fn with each row of the table joined with the tables of the joins
+// for which the where condition is true. Each join finds the rows of its table that may
+// match through the primary key or an index if its condition requires a column of the
+// table to be equal to a column of the tables before it, otherwise it reads the rows of
+// its table once, indexing them by that column in a hash table if there is one, or
+// comparing every pair of rows if there is none
+func scanJoinedRows(tx *storage.Tx, database, table string, source *CommonTable, alias string, joins []Join, where Expression, fn func(row dml.Row) error) error {
+	joined, err := selectQualifiedRows(tx, database, table, source, alias)
+	if err != nil {
+		return err
+	}
+
+	for _, join := range joins {
+		if joined, err = joinRows(tx, joined, join); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range joined.rows {
+		matches, err := Matches(where, row)
+		if err != nil {
+			return err
+		}
+
+		if !matches {
+			continue
+		}
+
+		if err := fn(row); err != nil {
+			if errors.Is(err, storage.ErrStopScan) {
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func joinRows(tx *storage.Tx, left joinedRows, join Join) (joinedRows, error) {
+	definition, err := joinedTable(tx, join.Database, join.Table, join.Source)
+	if err != nil {
+		return joinedRows{}, err
+	}
+
+	rightColumns := qualifiedColumns(definition.Columns, join.Alias)
+	joined := joinedRows{columns: append(slices.Clone(left.columns), rightColumns...)}
+
+	candidates, rightRows, err := joinCandidates(tx, definition, join)
+	if err != nil {
+		return joinedRows{}, err
+	}
+
+	matchedRight := make([]bool, len(rightRows))
+	for _, leftRow := range left.rows {
+		rowCandidates, err := candidates(leftRow)
+		if err != nil {
+			return joinedRows{}, err
+		}
+
+		matched := false
+		for _, candidate := range rowCandidates {
+			row := combinedRow(leftRow, candidate.row)
+			if join.On != nil {
+				matches, err := Matches(join.On, row)
+				if err != nil {
+					return joinedRows{}, err
+				}
+
+				if !matches {
+					continue
+				}
+			}
+
+			joined.rows = append(joined.rows, row)
+			matched = true
+			if candidate.index >= 0 {
+				matchedRight[candidate.index] = true
+			}
+		}
+
+		if !matched && join.Type == JoinTypeLeft {
+			joined.rows = append(joined.rows, combinedRow(leftRow, nullRow(rightColumns)))
+		}
+	}
+
+	if join.Type == JoinTypeRight {
+		for i, rightRow := range rightRows {
+			if !matchedRight[i] {
+				joined.rows = append(joined.rows, combinedRow(nullRow(left.columns), rightRow))
+			}
+		}
+	}
+
+	return joined, nil
+}
+
+// joinCandidates Returns a function that finds the rows of the joined table that may match
+// a row, along with every row of the joined table if they had to be read
+func joinCandidates(tx *storage.Tx, definition *ddl.Table, join Join) (func(row dml.Row) ([]joinCandidate, error), []dml.Row, error) {
+	leftKey, rightColumnName, hasEquality := joinEquality(join.On, join.Alias)
+
+	var rightColumn ddl.Column
+	if hasEquality {
+		index := slices.IndexFunc(definition.Columns, func(column ddl.Column) bool {
+			return stringutils.EqualsIgnoreCase(column.Name, rightColumnName)
+		})
+		if index < 0 {
+			return nil, nil, fmt.Errorf("%w: %s.%s", ErrColumnNotFound, join.Alias, rightColumnName)
+		}
+
+		rightColumn = definition.Columns[index]
+	}
+
+	if hasEquality && join.Type != JoinTypeRight && hasLookupForColumn(definition, rightColumn.Name) {
+		return func(row dml.Row) ([]joinCandidate, error) {
+			return lookupJoinCandidates(tx, definition, join.Alias, leftKey, rightColumn, row)
+		}, nil, nil
+	}
+
+	joined, err := selectQualifiedRows(tx, join.Database, join.Table, join.Source, join.Alias)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	every := make([]joinCandidate, 0, len(joined.rows))
+	for i, row := range joined.rows {
+		every = append(every, joinCandidate{row: row, index: i})
+	}
+
+	if !hasEquality {
+		return func(dml.Row) ([]joinCandidate, error) { return every, nil }, joined.rows, nil
+	}
+
+	hashed := make(map[string][]joinCandidate)
+	for _, candidate := range every {
+		value, err := ColumnRef{Name: join.Alias + "." + rightColumn.Name}.Evaluate(candidate.row)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if value != nil {
+			key := joinKey(value)
+			hashed[key] = append(hashed[key], candidate)
+		}
+	}
+
+	return func(row dml.Row) ([]joinCandidate, error) {
+		value, err := leftKey.Evaluate(row)
+		if err != nil || value == nil {
+			return nil, err
+		}
+
+		return hashed[joinKey(value)], nil
+	}, joined.rows, nil
+}
+
+// lookupJoinCandidates Finds the rows of the joined table with the column equal to the
+// key of the row through the primary key or an index
+func lookupJoinCandidates(tx *storage.Tx, definition *ddl.Table, alias string, leftKey Expression, rightColumn ddl.Column, row dml.Row) ([]joinCandidate, error) {
+	value, err := leftKey.Evaluate(row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	value, ok := valueForDataType(value, rightColumn.DataType)
+	if !ok {
+		return nil, nil
+	}
+
+	primaryKeys, _, err := lookupPrimaryKeys(tx, definition, []Filter{{
+		Column:     rightColumn.Name,
+		Comparison: FilterComparisonEquals,
+		Value:      value,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []joinCandidate
+	for _, primaryKey := range primaryKeys {
+		candidate, found, err := selectMatchingRow(tx, definition.Database, definition.Name, primaryKey, Literal{Value: true})
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			candidates = append(candidates, joinCandidate{row: qualifiedRow(candidate, alias), index: -1})
+		}
+	}
+
+	return candidates, nil
+}
+
+// joinEquality Returns the sides of an equality required by the condition between a
+// column of the joined table, qualified by the alias, and a column of the tables before it
+func joinEquality(on Expression, alias string) (Expression, string, bool) {
+	switch on := on.(type) {
+	case And:
+		if left, column, found := joinEquality(on.Left, alias); found {
+			return left, column, found
+		}
+
+		return joinEquality(on.Right, alias)
+
+	case Comparison:
+		if on.Comparison != FilterComparisonEquals {
+			break
+		}
+
+		left, isLeftColumn := on.Left.(ColumnRef)
+		right, isRightColumn := on.Right.(ColumnRef)
+		if !isLeftColumn || !isRightColumn {
+			break
+		}
+
+		leftName, isLeftJoined := unqualifiedName(left.Name, alias)
+		rightName, isRightJoined := unqualifiedName(right.Name, alias)
+
+		switch {
+		case isRightJoined && !isLeftJoined:
+			return left, rightName, true
+
+		case isLeftJoined && !isRightJoined:
+			return right, leftName, true
+		}
+	}
+
+	return nil, "", false
+}
+
+func unqualifiedName(name, alias string) (string, bool) {
+	prefix := alias + "."
+	if len(name) <= len(prefix) || !stringutils.EqualsIgnoreCase(name[:len(prefix)], prefix) {
+		return name, false
+	}
+
+	return name[len(prefix):], true
+}
+
+// hasLookupForColumn Checks if the rows with a value in the column can be found through
+// the primary key or an index over the column alone
+func hasLookupForColumn(definition *ddl.Table, column string) bool {
+	for _, c := range definition.Columns {
+		if ddl.ColumnIsPrimaryKey(c) && stringutils.EqualsIgnoreCase(c.Name, column) {
+			return true
+		}
+	}
+
+	for _, index := range ddl.TableIndexes(*definition) {
+		if len(index.Columns) == 1 && stringutils.EqualsIgnoreCase(index.Columns[0], column) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// valueForDataType Converts a number to the type of a column, as integers and floats
+// with the same value are equal but have different keys
+func valueForDataType(value any, dataType ddl.ColumnDataType) (any, bool) {
+	number := reflect.ValueOf(value)
+
+	switch {
+	case dataType == ddl.ColumnDataTypeFloat && number.CanInt():
+		return float64(number.Int()), true
+
+	case dataType == ddl.ColumnDataTypeInteger && number.CanFloat():
+		float := number.Float()
+		if float != math.Trunc(float) {
+			return nil, false
+		}
+
+		return int64(float), true
+	}
+
+	return value, true
+}
+
+// joinKey Returns the key of a value in a hash join, numbers with the same value having
+// the same key
+func joinKey(value any) string {
+	if number, isNumber := numberAsFloat(reflect.ValueOf(value)); isNumber {
+		return encodingutils.EncodeOrderedKey(number)
+	}
+
+	return encodingutils.EncodeOrderedKey(value)
+}
+
+func joinedTable(tx *storage.Tx, database, table string, source *CommonTable) (*ddl.Table, error) {
+	if source != nil {
+		return source.Definition(), nil
+	}
+
+	definition, err := catalogTable(tx, database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	if definition == nil {
+		return nil, fmt.Errorf("%w: %s.%s", ddl.ErrTableDoesNotExists, database, table)
+	}
+
+	return definition, nil
+}
+
+// selectQualifiedRows Returns every row of the table, with its columns qualified by the alias
+func selectQualifiedRows(tx *storage.Tx, database, table string, source *CommonTable, alias string) (joinedRows, error) {
+	definition, err := joinedTable(tx, database, table, source)
+	if err != nil {
+		return joinedRows{}, err
+	}
+
+	joined := joinedRows{columns: qualifiedColumns(definition.Columns, alias)}
+	collect := func(row dml.Row) error {
+		joined.rows = append(joined.rows, qualifiedRow(row, alias))
+		return nil
+	}
+
+	if source != nil {
+		err = source.scan(tx, Literal{Value: true}, collect)
+	} else {
+		err = scanRows(tx, definition, database, table, Literal{Value: true}, collect)
+	}
+	if err != nil {
+		return joinedRows{}, err
+	}
+
+	return joined, nil
+}
+
+func qualifiedColumns(columns []ddl.Column, alias string) []ddl.Column {
+	qualified := make([]ddl.Column, 0, len(columns))
+	for _, column := range columns {
+		column.Name = strings.ToUpper(alias) + "." + column.Name
+		qualified = append(qualified, column)
+	}
+
+	return qualified
+}
+
+func qualifiedRow(row dml.Row, alias string) dml.Row {
+	qualified := dml.Row{Columns: make([]dml.Column, 0, len(row.Columns))}
+	for _, column := range row.Columns {
+		column.Definition.Name = strings.ToUpper(alias) + "." + column.Definition.Name
+		qualified.Columns = append(qualified.Columns, column)
+	}
+
+	return qualified
+}
+
+func combinedRow(left, right dml.Row) dml.Row {
+	columns := make([]dml.Column, 0, len(left.Columns)+len(right.Columns))
+	return dml.Row{Columns: append(append(columns, left.Columns...), right.Columns...)}
+}
+
+func nullRow(columns []ddl.Column) dml.Row {
+	row := dml.Row{Columns: make([]dml.Column, 0, len(columns))}
+	for _, column := range columns {
+		row.Columns = append(row.Columns, dml.Column{Definition: column})
+	}
+
+	return row
+}
@@ -0,0 +1,78 @@
+package dql
+
+import "testing"
+
+func TestJoinEquality(t *testing.T) {
+	customerID := ColumnRef{Name: "O.CUSTOMER_ID"}
+	id := ColumnRef{Name: "C.ID"}
+
+	testCases := []struct {
+		name           string
+		on             Expression
+		alias          string
+		expectedKey    Expression
+		expectedColumn string
+		expectedFound  bool
+	}{
+		{
+			name:           "should find the column of the joined table on the right",
+			on:             Comparison{Comparison: FilterComparisonEquals, Left: customerID, Right: id},
+			alias:          "C",
+			expectedKey:    customerID,
+			expectedColumn: "ID",
+			expectedFound:  true,
+		},
+		{
+			name:           "should find the column of the joined table on the left",
+			on:             Comparison{Comparison: FilterComparisonEquals, Left: customerID, Right: id},
+			alias:          "o",
+			expectedKey:    id,
+			expectedColumn: "CUSTOMER_ID",
+			expectedFound:  true,
+		},
+		{
+			name: "should find equalities inside AND",
+			on: And{
+				Left:  Comparison{Comparison: FilterComparisonGreater, Left: customerID, Right: Literal{Value: int64(1)}},
+				Right: Comparison{Comparison: FilterComparisonEquals, Left: id, Right: customerID},
+			},
+			alias:          "C",
+			expectedKey:    customerID,
+			expectedColumn: "ID",
+			expectedFound:  true,
+		},
+		{
+			name: "should not find equalities inside OR",
+			on: Or{
+				Left:  Comparison{Comparison: FilterComparisonEquals, Left: customerID, Right: id},
+				Right: Literal{Value: true},
+			},
+			alias: "C",
+		},
+		{
+			name:  "should not find equalities between columns of the same table",
+			on:    Comparison{Comparison: FilterComparisonEquals, Left: id, Right: ColumnRef{Name: "C.OTHER_ID"}},
+			alias: "C",
+		},
+		{
+			name:  "should not find equalities with literals",
+			on:    Comparison{Comparison: FilterComparisonEquals, Left: id, Right: Literal{Value: int64(1)}},
+			alias: "C",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			key, column, found := joinEquality(testCase.on, testCase.alias)
+			if found != testCase.expectedFound {
+				t.Errorf("expected found %v, got %v", testCase.expectedFound, found)
+				return
+			}
+
+			if key != testCase.expectedKey || column != testCase.expectedColumn {
+				t.Errorf("expected key %v and column %s, got %v and %s", testCase.expectedKey, testCase.expectedColumn, key, column)
+				return
+			}
+		})
+	}
+}
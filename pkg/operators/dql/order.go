@@ -0,0 +1,314 @@
+package dql
+
+import (
+	"container/heap"
+	"slices"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
+)
+
+// NullsOrder Where NULLs are placed when sorting
+type NullsOrder string
+
+var (
+	NullsOrderDefault NullsOrder = ""
+	NullsOrderFirst   NullsOrder = "FIRST"
+	NullsOrderLast    NullsOrder = "LAST"
+)
+
+// Order A column the rows of a select are sorted by. By default NULLs are lower than any
+// other value, so they come first in ascending order and last in descending order
+type Order struct {
+	Column     string
+	Descending bool
+	Nulls      NullsOrder
+}
+
+func (o Order) nullsFirst() bool {
+	switch o.Nulls {
+	case NullsOrderFirst:
+		return true
+
+	case NullsOrderLast:
+		return false
+	}
+
+	return !o.Descending
+}
+
+// SelectOrdered Returns the rows of the table for which the where condition is true sorted
+// by the orders, skipping the first offset rows and returning up to limit rows if limit is
+// positive. If the rows are sorted by a single column, an index starts with that column
+// and no index can find the rows by equality, the index is scanned in the order, limited
+// to the range required by the condition, and the scan stops once enough rows matched.
+// Otherwise, with a limit, only the limit plus offset lowest rows are kept while reading
+// the rows, and without a limit every row is sorted in memory
+func SelectOrdered(tx *storage.Tx, database, table string, where Expression, orders []Order, limit, offset int) ([]dml.Row, error) {
+	definition, err := catalogTable(tx, database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	bound := 0
+	if limit > 0 {
+		bound = limit + max(offset, 0)
+	}
+
+	var rows []dml.Row
+	switch index, isOrdered := orderedIndex(definition, where, orders); {
+	case len(orders) == 0 || isOrdered:
+		rows, err = collectRows(bound, func(fn func(row dml.Row) error) error {
+			if !isOrdered {
+				return scanRows(tx, definition, database, table, where, fn)
+			}
+
+			keyRange, _ := keyRangeForColumn(requiredFilters(definition, where), orders[0].Column)
+			keyRange.Reverse = orders[0].Descending
+
+			return scanThroughIndex(tx, database, table, index, keyRange, where, fn)
+		})
+
+	default:
+		rows, err = sortedRows(orders, bound, func(fn func(row dml.Row) error) error {
+			return scanRows(tx, definition, database, table, where, fn)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return Page(rows, limit, offset), nil
+}
+
+// Page Returns the rows after the first offset rows, up to limit rows if limit is positive
+func Page[T any](rows []T, limit, offset int) []T {
+	if offset >= len(rows) {
+		return nil
+	}
+
+	rows = rows[max(offset, 0):]
+	if limit > 0 && limit < len(rows) {
+		return rows[:limit]
+	}
+
+	return rows
+}
+
+// collectRows Collects the rows given by scan, stopping it once bound rows were collected
+// if bound is positive
+func collectRows(bound int, scan func(fn func(row dml.Row) error) error) ([]dml.Row, error) {
+	var rows []dml.Row
+	err := scan(func(row dml.Row) error {
+		rows = append(rows, row)
+		if bound > 0 && len(rows) == bound {
+			return storage.ErrStopScan
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// orderedIndex Returns the index that holds the rows in the orders, which must be a single
+// column with its NULLs placed as in the index
+func orderedIndex(definition *ddl.Table, where Expression, orders []Order) (ddl.Index, bool) {
+	if definition == nil || len(orders) != 1 || orders[0].nullsFirst() == orders[0].Descending {
+		return ddl.Index{}, false
+	}
+
+	index, ok := orderedIndexForColumn(definition, orders[0].Column)
+	if !ok || hasEqualityIndex(definition, requiredFilters(definition, where)) {
+		return ddl.Index{}, false
+	}
+
+	return index, true
+}
+
+// SortRows Sorts the rows by the orders, each order only sorts the rows that are equal by
+// the previous ones, see [CompareValues]
+func SortRows(rows []dml.Row, orders ...Order) error {
+	var err error
+	slices.SortStableFunc(rows, func(r1, r2 dml.Row) int {
+		result, compareErr := compareRows(r1, r2, orders)
+		if compareErr != nil {
+			err = compareErr
+		}
+
+		return result
+	})
+
+	return err
+}
+
+func compareRows(r1, r2 dml.Row, orders []Order) (int, error) {
+	for _, order := range orders {
+		c1, err := rowColumn(r1, order.Column)
+		if err != nil {
+			return 0, err
+		}
+
+		c2, err := rowColumn(r2, order.Column)
+		if err != nil {
+			return 0, err
+		}
+
+		result, err := compareOrderedValues(c1.Value, c2.Value, order)
+		if err != nil || result != 0 {
+			return result, err
+		}
+	}
+
+	return 0, nil
+}
+
+func compareOrderedValues(v1, v2 any, order Order) (int, error) {
+	if v1 == nil || v2 == nil {
+		switch {
+		case v1 == v2:
+			return 0, nil
+
+		case (v1 == nil) == order.nullsFirst():
+			return -1, nil
+		}
+
+		return 1, nil
+	}
+
+	result, err := CompareValues(v1, v2)
+	if order.Descending {
+		return -result, err
+	}
+
+	return result, err
+}
+
+// sortedRows Returns the rows given by scan sorted by the orders, or only the bound lowest
+// of them if bound is positive, keeping at most bound rows in memory while reading them
+func sortedRows(orders []Order, bound int, scan func(fn func(row dml.Row) error) error) ([]dml.Row, error) {
+	if bound <= 0 {
+		rows, err := collectRows(0, scan)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := SortRows(rows, orders...); err != nil {
+			return nil, err
+		}
+
+		return rows, nil
+	}
+
+	top := &topRows{orders: orders}
+	err := scan(func(row dml.Row) error {
+		top.offer(row, bound)
+		return top.err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return top.sorted()
+}
+
+type rankedRow struct {
+	row dml.Row
+	// sequence The position of the row in the scan, so rows that are equal by the orders
+	// keep the order they were read in
+	sequence int
+}
+
+// topRows A max heap of the lowest rows read so far, the highest of them is on top so it
+// can be replaced by a lower row
+type topRows struct {
+	rows   []rankedRow
+	orders []Order
+	read   int
+	err    error
+}
+
+func (t *topRows) compare(r1, r2 rankedRow) int {
+	result, err := compareRows(r1.row, r2.row, t.orders)
+	if err != nil {
+		t.err = err
+	}
+
+	if result == 0 {
+		return r1.sequence - r2.sequence
+	}
+
+	return result
+}
+
+func (t *topRows) Len() int           { return len(t.rows) }
+func (t *topRows) Less(i, j int) bool { return t.compare(t.rows[i], t.rows[j]) > 0 }
+func (t *topRows) Swap(i, j int)      { t.rows[i], t.rows[j] = t.rows[j], t.rows[i] }
+func (t *topRows) Push(x any)         { t.rows = append(t.rows, x.(rankedRow)) }
+
+func (t *topRows) Pop() any {
+	last := t.rows[len(t.rows)-1]
+	t.rows = t.rows[:len(t.rows)-1]
+	return last
+}
+
+func (t *topRows) offer(row dml.Row, bound int) {
+	ranked := rankedRow{row: row, sequence: t.read}
+	t.read++
+
+	if len(t.rows) < bound {
+		heap.Push(t, ranked)
+		return
+	}
+
+	if t.compare(ranked, t.rows[0]) < 0 {
+		t.rows[0] = ranked
+		heap.Fix(t, 0)
+	}
+}
+
+func (t *topRows) sorted() ([]dml.Row, error) {
+	slices.SortFunc(t.rows, t.compare)
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	rows := make([]dml.Row, 0, len(t.rows))
+	for _, ranked := range t.rows {
+		rows = append(rows, ranked.row)
+	}
+
+	return rows, nil
+}
+
+func orderedIndexForColumn(table *ddl.Table, column string) (ddl.Index, bool) {
+	for _, index := range ddl.TableIndexes(*table) {
+		if stringutils.EqualsIgnoreCase(index.Columns[0], column) {
+			return index, true
+		}
+	}
+
+	return ddl.Index{}, false
+}
+
+func hasEqualityIndex(table *ddl.Table, required []Filter) bool {
+	for _, index := range ddl.TableIndexes(*table) {
+		covered := true
+		for _, column := range index.Columns {
+			covered = covered && slices.ContainsFunc(required, func(filter Filter) bool {
+				return filter.Comparison == FilterComparisonEquals && stringutils.EqualsIgnoreCase(filter.Column, column)
+			})
+		}
+
+		if covered {
+			return true
+		}
+	}
+
+	return false
+}
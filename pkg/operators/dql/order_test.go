@@ -0,0 +1,343 @@
+package dql
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var testOrderMockTable = ddl.Table{
+	Database: "FOO_DB",
+	Name:     "FOO_TABLE",
+	Columns: []ddl.Column{
+		{
+			Name:        "ID",
+			DataType:    ddl.ColumnDataTypeInteger,
+			Constraints: []ddl.Constraint{{Type: ddl.ConstraintPrimaryKey, Name: "id_pkey"}},
+		},
+		{
+			Name:     "NAME",
+			DataType: ddl.ColumnDataTypeText,
+		},
+		{
+			Name:     "SCORE",
+			DataType: ddl.ColumnDataTypeFloat,
+		},
+	},
+	Indexes: []ddl.Index{
+		{Name: "foo_score_idx", Columns: []string{"SCORE"}},
+	},
+}
+
+// testOrderMockStorage Mocks rows with ids from 1 to 12, so sorting them as strings gives
+// a different order, and scores that decrease as the ids increase
+func testOrderMockStorage(t *testing.T) *storage.Storage {
+	store, err := storage.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	tx := store.Begin()
+	if err := ddl.CreateTable(tx, testOrderMockTable, false, false); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	for id := range int64(12) {
+		row := dml.Row{
+			Database: testOrderMockTable.Database,
+			Table:    testOrderMockTable.Name,
+			Columns: []dml.Column{
+				{Definition: testOrderMockTable.Columns[0], Value: id + 1},
+				{Definition: testOrderMockTable.Columns[1], Value: fmt.Sprintf("name %c", 'a'+(id*5)%12)},
+				{Definition: testOrderMockTable.Columns[2], Value: float64(12-id) / 4},
+			},
+		}
+
+		if err := dml.Insert(tx, row); err != nil {
+			t.Fatalf("not expected error when mocking storage, got %s", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	return store
+}
+
+func TestSelectOrdered(t *testing.T) {
+	testCases := []struct {
+		name           string
+		where          Expression
+		orders         []Order
+		limit          int
+		offset         int
+		dropPrimaryKey int64
+		expectedIDs    []int64
+		expectedError  error
+	}{
+		{
+			name:        "should select the first rows by primary key",
+			orders:      []Order{{Column: "ID"}},
+			limit:       3,
+			expectedIDs: []int64{1, 2, 3},
+		},
+		{
+			name:        "should select the last rows by primary key",
+			orders:      []Order{{Column: "ID", Descending: true}},
+			limit:       2,
+			expectedIDs: []int64{12, 11},
+		},
+		{
+			name: "should select the rows in a range of the primary key",
+			where: And{
+				Left:  Comparison{Comparison: FilterComparisonGreaterOrEqual, Left: ColumnRef{Name: "ID"}, Right: Literal{Value: int64(9)}},
+				Right: Comparison{Comparison: FilterComparisonGreater, Left: Literal{Value: int64(11)}, Right: ColumnRef{Name: "ID"}},
+			},
+			orders:      []Order{{Column: "ID", Descending: true}},
+			expectedIDs: []int64{10, 9},
+		},
+		{
+			name:        "should select the rows between the bounds of an index",
+			where:       Between{Expression: ColumnRef{Name: "SCORE"}, Low: Literal{Value: 0.5}, High: Literal{Value: 1.25}},
+			orders:      []Order{{Column: "SCORE"}},
+			expectedIDs: []int64{11, 10, 9, 8},
+		},
+		{
+			name: "should apply the other filters to the rows of the index",
+			where: FiltersExpression([]Filter{
+				{Column: "SCORE", Operand: FilterOperandAnd, Comparison: FilterComparisonGreater, Where: WhereColumnGreater, Value: 2.0},
+				{Column: "NAME", Operand: FilterOperandAndNot, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "name f"},
+			}),
+			orders:      []Order{{Column: "ID"}},
+			limit:       2,
+			expectedIDs: []int64{1, 3},
+		},
+		{
+			name:        "should sort the rows by a column without index",
+			orders:      []Order{{Column: "NAME"}},
+			limit:       4,
+			expectedIDs: []int64{1, 6, 11, 4},
+		},
+		{
+			name:           "should only read the rows found through the index",
+			orders:         []Order{{Column: "ID"}},
+			limit:          2,
+			dropPrimaryKey: 1,
+			expectedIDs:    []int64{2, 3},
+		},
+		{
+			name:           "should only read the rows in the range of the index required by the condition",
+			where:          And{Left: Comparison{Comparison: FilterComparisonGreater, Left: Literal{Value: int64(3)}, Right: ColumnRef{Name: "ID"}}, Right: Literal{Value: true}},
+			orders:         []Order{{Column: "NAME"}},
+			dropPrimaryKey: 1,
+			expectedIDs:    []int64{2},
+		},
+		{
+			name:        "should skip the offset rows of the index",
+			orders:      []Order{{Column: "ID"}},
+			limit:       2,
+			offset:      3,
+			expectedIDs: []int64{4, 5},
+		},
+		{
+			name:        "should skip the offset rows sorted in memory",
+			orders:      []Order{{Column: "NAME"}},
+			limit:       2,
+			offset:      1,
+			expectedIDs: []int64{6, 11},
+		},
+		{
+			name:        "should not select rows past the offset",
+			orders:      []Order{{Column: "SCORE"}},
+			offset:      12,
+			expectedIDs: nil,
+		},
+		{
+			name:          "should not sort by an unknown column",
+			orders:        []Order{{Column: "FOO"}},
+			expectedError: ErrColumnNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			store := testOrderMockStorage(t)
+			defer store.Close()
+
+			tx := store.Begin()
+			defer tx.Rollback()
+
+			if testCase.dropPrimaryKey != 0 {
+				dataDir := dml.IndexDataDir(testOrderMockTable.Database, testOrderMockTable.Name, "id_pkey")
+				if err := tx.Delete(dataDir, dml.IndexKey(testCase.dropPrimaryKey)); err != nil {
+					t.Errorf("not expected error, got %s", err)
+					return
+				}
+			}
+
+			var where Expression = Literal{Value: true}
+			if testCase.where != nil {
+				where = testCase.where
+			}
+
+			rows, err := SelectOrdered(tx, testOrderMockTable.Database, testOrderMockTable.Name, where, testCase.orders, testCase.limit, testCase.offset)
+			if testCase.expectedError != nil {
+				if err == nil || !errors.Is(err, testCase.expectedError) {
+					t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			var ids []int64
+			for _, row := range rows {
+				ids = append(ids, row.Columns[0].Value.(int64))
+			}
+
+			if !slices.Equal(ids, testCase.expectedIDs) {
+				t.Errorf("expected rows with ids %v, got %v", testCase.expectedIDs, ids)
+				return
+			}
+		})
+	}
+}
+
+var testOrderNullsMockTable = ddl.Table{
+	Database: "FOO_DB",
+	Name:     "BAR_TABLE",
+	Columns: []ddl.Column{
+		{
+			Name:        "ID",
+			DataType:    ddl.ColumnDataTypeInteger,
+			Constraints: []ddl.Constraint{{Type: ddl.ConstraintPrimaryKey, Name: "bar_pkey"}},
+		},
+		{
+			Name:     "KIND",
+			DataType: ddl.ColumnDataTypeText,
+		},
+		{
+			Name:     "RANK",
+			DataType: ddl.ColumnDataTypeInteger,
+		},
+	},
+}
+
+func testOrderNullsMockStorage(t *testing.T) *storage.Storage {
+	store, err := storage.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	tx := store.Begin()
+	if err := ddl.CreateTable(tx, testOrderNullsMockTable, false, false); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	values := [][]any{
+		{int64(1), "a", int64(3)},
+		{int64(2), "b", nil},
+		{int64(3), "a", int64(1)},
+		{int64(4), "b", int64(2)},
+		{int64(5), "a", nil},
+		{int64(6), nil, int64(1)},
+	}
+
+	for _, rowValues := range values {
+		row := dml.Row{
+			Database: testOrderNullsMockTable.Database,
+			Table:    testOrderNullsMockTable.Name,
+		}
+
+		for i, value := range rowValues {
+			row.Columns = append(row.Columns, dml.Column{Definition: testOrderNullsMockTable.Columns[i], Value: value})
+		}
+
+		if err := dml.Insert(tx, row); err != nil {
+			t.Fatalf("not expected error when mocking storage, got %s", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	return store
+}
+
+func TestSelectOrderedByColumns(t *testing.T) {
+	testCases := []struct {
+		name        string
+		orders      []Order
+		limit       int
+		offset      int
+		expectedIDs []int64
+	}{
+		{
+			name:        "should sort by each column in turn, with NULLs lower than other values",
+			orders:      []Order{{Column: "KIND"}, {Column: "RANK", Descending: true}},
+			expectedIDs: []int64{6, 1, 3, 5, 4, 2},
+		},
+		{
+			name:        "should keep the lowest rows when limited",
+			orders:      []Order{{Column: "KIND"}, {Column: "RANK", Descending: true}},
+			limit:       4,
+			expectedIDs: []int64{6, 1, 3, 5},
+		},
+		{
+			name:        "should place NULLs last",
+			orders:      []Order{{Column: "KIND", Nulls: NullsOrderLast}, {Column: "RANK", Nulls: NullsOrderLast}},
+			limit:       3,
+			expectedIDs: []int64{3, 1, 5},
+		},
+		{
+			name:        "should place NULLs first when descending",
+			orders:      []Order{{Column: "RANK", Descending: true, Nulls: NullsOrderFirst}, {Column: "ID"}},
+			limit:       3,
+			offset:      1,
+			expectedIDs: []int64{5, 1, 4},
+		},
+		{
+			name:        "should sort by an indexed column with NULLs placed out of the index order",
+			orders:      []Order{{Column: "ID", Descending: true, Nulls: NullsOrderFirst}},
+			limit:       2,
+			expectedIDs: []int64{6, 5},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			store := testOrderNullsMockStorage(t)
+			defer store.Close()
+
+			tx := store.Begin()
+			defer tx.Rollback()
+
+			rows, err := SelectOrdered(tx, testOrderNullsMockTable.Database, testOrderNullsMockTable.Name, Literal{Value: true}, testCase.orders, testCase.limit, testCase.offset)
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			var ids []int64
+			for _, row := range rows {
+				ids = append(ids, row.Columns[0].Value.(int64))
+			}
+
+			if !slices.Equal(ids, testCase.expectedIDs) {
+				t.Errorf("expected rows with ids %v, got %v", testCase.expectedIDs, ids)
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package dql
+
+import (
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+// ResultColumn The name and type of a column of a [ResultSet]
+type ResultColumn struct {
+	Name     string
+	DataType ddl.ColumnDataType
+}
+
+// ResultSet The rows returned by a select, with the values of each row in the order of
+// the columns
+type ResultSet struct {
+	Columns []ResultColumn
+	Rows    [][]any
+}
+
+// Projection An item of a select list, the expression evaluated for each row and the
+// column its values are returned as
+type Projection struct {
+	Column     ResultColumn
+	Expression Expression
+}
+
+func (r ResultSet) ColumnNames() []string {
+	names := make([]string, 0, len(r.Columns))
+	for _, column := range r.Columns {
+		names = append(names, column.Name)
+	}
+
+	return names
+}
+
+func (r ResultSet) ColumnDataTypes() []ddl.ColumnDataType {
+	dataTypes := make([]ddl.ColumnDataType, 0, len(r.Columns))
+	for _, column := range r.Columns {
+		dataTypes = append(dataTypes, column.DataType)
+	}
+
+	return dataTypes
+}
+
+// SelectProjection Returns the projections of the rows of the table for which the where
+// condition is true, see [SelectWhere]
+func SelectProjection(tx *storage.Tx, database, table string, where Expression, projections []Projection) (ResultSet, error) {
+	rows, err := SelectWhere(tx, database, table, where)
+	if err != nil {
+		return ResultSet{}, err
+	}
+
+	return Project(rows, projections)
+}
+
+// Project Evaluates the projections for each row
+func Project(rows []dml.Row, projections []Projection) (ResultSet, error) {
+	resultSet := ResultSet{
+		Columns: make([]ResultColumn, 0, len(projections)),
+		Rows:    make([][]any, 0, len(rows)),
+	}
+
+	for _, projection := range projections {
+		resultSet.Columns = append(resultSet.Columns, projection.Column)
+	}
+
+	for _, row := range rows {
+		values := make([]any, 0, len(projections))
+		for _, projection := range projections {
+			value, err := projection.Expression.Evaluate(row)
+			if err != nil {
+				return ResultSet{}, err
+			}
+
+			values = append(values, value)
+		}
+
+		resultSet.Rows = append(resultSet.Rows, values)
+	}
+
+	return resultSet, nil
+}
@@ -0,0 +1,310 @@
+package dql
+
+import (
+	"reflect"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+)
+
+// SetOperator The operation of a [SetOperation]
+type SetOperator string
+
+var (
+	SetOperatorUnion     SetOperator = "UNION"
+	SetOperatorIntersect SetOperator = "INTERSECT"
+	SetOperatorExcept    SetOperator = "EXCEPT"
+)
+
+// Query A query that results in a [ResultSet], with the columns it returns known before
+// running it
+type Query interface {
+	Columns() []ResultColumn
+	Run(tx *storage.Tx) (ResultSet, error)
+}
+
+// SelectQuery Selects the rows of a table, or of the Source common table if any, joined
+// with the tables of the joins if any, in which case their columns are named ALIAS.COLUMN,
+// see [Join]. The common tables of With are computed again in each run. It keeps the rows
+// for which the where condition is true, groups them by the aggregation, if any, computes
+// the windows, sorts the rows by the orders and projects them. With Distinct only the
+// first of the rows with equal projected values is returned. The first Offset rows are
+// skipped and up to Limit rows are returned, a negative Limit meaning there is no limit
+// and a zero Limit returning no rows. With a limit only the Limit plus Offset lowest rows
+// are kept while reading them, except for windows, which are computed over every row
+// before sorting
+type SelectQuery struct {
+	With        []*CommonTable
+	Database    string
+	Table       string
+	Source      *CommonTable
+	Alias       string
+	Joins       []Join
+	Where       Expression
+	Aggregation *Aggregation
+	Windows     []Window
+	Projections []Projection
+	Distinct    bool
+	Orders      []Order
+	Limit       int
+	Offset      int
+}
+
+func (s SelectQuery) Columns() []ResultColumn {
+	columns := make([]ResultColumn, 0, len(s.Projections))
+	for _, projection := range s.Projections {
+		columns = append(columns, projection.Column)
+	}
+
+	return columns
+}
+
+func (s SelectQuery) Run(tx *storage.Tx) (ResultSet, error) {
+	resetCommonTables(s.With)
+
+	if s.Limit == 0 {
+		return Project(nil, s.Projections)
+	}
+
+	// Distinct rows are only known once projected, so every row is read before paging
+	limit, offset := max(s.Limit, 0), s.Offset
+	if s.Distinct {
+		limit, offset = 0, 0
+	}
+
+	bound := 0
+	if limit > 0 {
+		bound = limit + max(offset, 0)
+	}
+
+	scan := func(fn func(row dml.Row) error) error {
+		return s.scan(tx, fn)
+	}
+
+	var rows []dml.Row
+	var err error
+	switch {
+	case s.Aggregation != nil:
+		aggregator := NewAggregator(*s.Aggregation)
+		if err = s.scan(tx, aggregator.Add); err == nil {
+			rows, err = aggregator.Rows()
+		}
+
+	case len(s.Windows) > 0:
+		// Windows are computed over every row, so they are all kept before sorting them
+		rows, err = collectRows(0, scan)
+
+	case len(s.Joins) > 0 || s.Source != nil:
+		rows, err = sortedRows(s.Orders, bound, scan)
+
+	default:
+		rows, err = SelectOrdered(tx, s.Database, s.Table, s.Where, s.Orders, limit, offset)
+	}
+	if err == nil && len(s.Windows) > 0 {
+		rows, err = ComputeWindows(rows, s.Windows)
+	}
+	if err == nil && (s.Aggregation != nil || len(s.Windows) > 0) {
+		rows, err = sortedRows(s.Orders, bound, func(fn func(row dml.Row) error) error {
+			for _, row := range rows {
+				if err := fn(row); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+	if err != nil {
+		return ResultSet{}, err
+	}
+
+	if s.Aggregation != nil || len(s.Joins) > 0 || s.Source != nil || len(s.Windows) > 0 {
+		rows = Page(rows, limit, offset)
+	}
+
+	resultSet, err := Project(rows, s.Projections)
+	if err != nil {
+		return ResultSet{}, err
+	}
+
+	if s.Distinct {
+		resultSet.Rows = Page(distinctValues(resultSet.Rows), max(s.Limit, 0), s.Offset)
+	}
+
+	return resultSet, nil
+}
+
+// scan Calls fn with each of the rows selected by the query, before grouping them
+func (s SelectQuery) scan(tx *storage.Tx, fn func(row dml.Row) error) error {
+	if len(s.Joins) > 0 {
+		return scanJoinedRows(tx, s.Database, s.Table, s.Source, s.Alias, s.Joins, s.Where, fn)
+	}
+
+	if s.Source != nil {
+		return s.Source.scan(tx, s.Where, fn)
+	}
+
+	definition, err := catalogTable(tx, s.Database, s.Table)
+	if err != nil {
+		return err
+	}
+
+	return scanRows(tx, definition, s.Database, s.Table, s.Where, fn)
+}
+
+// SetOperation Combines the rows of two queries with the same number of columns, with
+// values of the same type or numbers. UNION returns the rows of both queries, INTERSECT
+// the rows of the left query that are in the right one and EXCEPT the rows of the left
+// query that are not in the right one. Rows are compared by all their values, NULLs being
+// equal, and without All only distinct rows are returned, while with All each row of the
+// right query matches a single row of the left one. The columns are named as the columns
+// of the left query, and are FLOAT if the columns of any query are. The rows are sorted
+// and paged as in a [SelectQuery], by the columns of the result
+type SetOperation struct {
+	With     []*CommonTable
+	Operator SetOperator
+	All      bool
+	Left     Query
+	Right    Query
+	Orders   []Order
+	Limit    int
+	Offset   int
+}
+
+func (s SetOperation) Columns() []ResultColumn {
+	columns := s.Left.Columns()
+	for i, column := range s.Right.Columns() {
+		if i < len(columns) && column.DataType == ddl.ColumnDataTypeFloat {
+			columns[i].DataType = ddl.ColumnDataTypeFloat
+		}
+	}
+
+	return columns
+}
+
+func (s SetOperation) Run(tx *storage.Tx) (ResultSet, error) {
+	resetCommonTables(s.With)
+
+	left, err := s.Left.Run(tx)
+	if err != nil {
+		return ResultSet{}, err
+	}
+
+	right, err := s.Right.Run(tx)
+	if err != nil {
+		return ResultSet{}, err
+	}
+
+	columns := s.Columns()
+	leftRows, rightRows := widenValues(left.Rows, columns), widenValues(right.Rows, columns)
+
+	rows := make([][]any, 0, len(leftRows))
+	switch s.Operator {
+	case SetOperatorUnion:
+		rows = append(append(rows, leftRows...), rightRows...)
+		if !s.All {
+			rows = distinctValues(rows)
+		}
+
+	case SetOperatorIntersect, SetOperatorExcept:
+		counts := make(map[string]int, len(rightRows))
+		for _, values := range rightRows {
+			counts[encodingutils.EncodeOrderedKey(values...)]++
+		}
+
+		seen := make(map[string]struct{})
+		for _, values := range leftRows {
+			key := encodingutils.EncodeOrderedKey(values...)
+			isInRight := counts[key] > 0
+			if isInRight && s.All {
+				counts[key]--
+			}
+
+			if isInRight != (s.Operator == SetOperatorIntersect) {
+				continue
+			}
+
+			if !s.All {
+				if _, isSeen := seen[key]; isSeen {
+					continue
+				}
+
+				seen[key] = struct{}{}
+			}
+
+			rows = append(rows, values)
+		}
+	}
+
+	resultSet := ResultSet{Columns: columns, Rows: rows}
+	if err := sortValues(resultSet, s.Orders); err != nil {
+		return ResultSet{}, err
+	}
+
+	if s.Limit == 0 {
+		resultSet.Rows = resultSet.Rows[:0]
+	}
+
+	resultSet.Rows = Page(resultSet.Rows, max(s.Limit, 0), s.Offset)
+	return resultSet, nil
+}
+
+// distinctValues Returns the first of the rows with equal values, keeping their order
+func distinctValues(rows [][]any) [][]any {
+	return unseenValues(rows, make(map[string]struct{}, len(rows)))
+}
+
+// widenValues Converts the integers of FLOAT columns to floats, so they are equal to the
+// floats with the same value
+func widenValues(rows [][]any, columns []ResultColumn) [][]any {
+	for _, values := range rows {
+		for i, value := range values {
+			if i >= len(columns) || columns[i].DataType != ddl.ColumnDataTypeFloat {
+				continue
+			}
+
+			if number := reflect.ValueOf(value); number.CanInt() {
+				values[i] = float64(number.Int())
+			}
+		}
+	}
+
+	return rows
+}
+
+// sortValues Sorts the rows of the result set by the orders, over its columns
+func sortValues(resultSet ResultSet, orders []Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	rows := make([]dml.Row, 0, len(resultSet.Rows))
+	for _, values := range resultSet.Rows {
+		row := dml.Row{Columns: make([]dml.Column, 0, len(values))}
+		for i, value := range values {
+			row.Columns = append(row.Columns, dml.Column{
+				Definition: ddl.Column{Name: resultSet.Columns[i].Name, DataType: resultSet.Columns[i].DataType},
+				Value:      value,
+			})
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := SortRows(rows, orders...); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		values := make([]any, 0, len(row.Columns))
+		for _, column := range row.Columns {
+			values = append(values, column.Value)
+		}
+
+		resultSet.Rows[i] = values
+	}
+
+	return nil
+}
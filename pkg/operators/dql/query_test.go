@@ -0,0 +1,134 @@
+package dql
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+// valuesQuery A query that results in its rows
+type valuesQuery struct {
+	columns []ResultColumn
+	rows    [][]any
+}
+
+func (v valuesQuery) Columns() []ResultColumn {
+	return slices.Clone(v.columns)
+}
+
+func (v valuesQuery) Run(*storage.Tx) (ResultSet, error) {
+	rows := make([][]any, 0, len(v.rows))
+	for _, values := range v.rows {
+		rows = append(rows, slices.Clone(values))
+	}
+
+	return ResultSet{Columns: v.Columns(), Rows: rows}, nil
+}
+
+func TestSetOperation(t *testing.T) {
+	integers := valuesQuery{
+		columns: []ResultColumn{{Name: "ID", DataType: ddl.ColumnDataTypeInteger}},
+		rows:    [][]any{{int64(3)}, {int64(1)}, {int64(1)}, {nil}, {int64(2)}, {int64(1)}},
+	}
+
+	floats := valuesQuery{
+		columns: []ResultColumn{{Name: "PRICE", DataType: ddl.ColumnDataTypeFloat}},
+		rows:    [][]any{{1.0}, {nil}, {1.0}, {4.5}},
+	}
+
+	testCases := []struct {
+		name            string
+		operation       SetOperation
+		expectedColumns []ResultColumn
+		expectedRows    [][]any
+		expectedError   error
+	}{
+		{
+			name:            "should return the distinct rows of both queries with UNION, as the wider type",
+			operation:       SetOperation{Operator: SetOperatorUnion, Left: integers, Right: floats, Limit: -1},
+			expectedColumns: []ResultColumn{{Name: "ID", DataType: ddl.ColumnDataTypeFloat}},
+			expectedRows:    [][]any{{3.0}, {1.0}, {nil}, {2.0}, {4.5}},
+		},
+		{
+			name:            "should keep every row with UNION ALL",
+			operation:       SetOperation{Operator: SetOperatorUnion, All: true, Left: floats, Right: floats, Limit: -1},
+			expectedColumns: []ResultColumn{{Name: "PRICE", DataType: ddl.ColumnDataTypeFloat}},
+			expectedRows:    [][]any{{1.0}, {nil}, {1.0}, {4.5}, {1.0}, {nil}, {1.0}, {4.5}},
+		},
+		{
+			name:            "should match NULLs with INTERSECT",
+			operation:       SetOperation{Operator: SetOperatorIntersect, Left: integers, Right: floats, Limit: -1},
+			expectedColumns: []ResultColumn{{Name: "ID", DataType: ddl.ColumnDataTypeFloat}},
+			expectedRows:    [][]any{{1.0}, {nil}},
+		},
+		{
+			name:            "should match each right row once with INTERSECT ALL",
+			operation:       SetOperation{Operator: SetOperatorIntersect, All: true, Left: integers, Right: floats, Limit: -1},
+			expectedColumns: []ResultColumn{{Name: "ID", DataType: ddl.ColumnDataTypeFloat}},
+			expectedRows:    [][]any{{1.0}, {1.0}, {nil}},
+		},
+		{
+			name:            "should remove every matching row with EXCEPT",
+			operation:       SetOperation{Operator: SetOperatorExcept, Left: integers, Right: floats, Limit: -1},
+			expectedColumns: []ResultColumn{{Name: "ID", DataType: ddl.ColumnDataTypeFloat}},
+			expectedRows:    [][]any{{3.0}, {2.0}},
+		},
+		{
+			name:            "should remove a row for each matching row with EXCEPT ALL",
+			operation:       SetOperation{Operator: SetOperatorExcept, All: true, Left: integers, Right: floats, Limit: -1},
+			expectedColumns: []ResultColumn{{Name: "ID", DataType: ddl.ColumnDataTypeFloat}},
+			expectedRows:    [][]any{{3.0}, {2.0}, {1.0}},
+		},
+		{
+			name: "should sort and page the result",
+			operation: SetOperation{
+				Operator: SetOperatorUnion,
+				Left:     integers,
+				Right:    floats,
+				Orders:   []Order{{Column: "ID", Descending: true}},
+				Limit:    2,
+				Offset:   1,
+			},
+			expectedColumns: []ResultColumn{{Name: "ID", DataType: ddl.ColumnDataTypeFloat}},
+			expectedRows:    [][]any{{3.0}, {2.0}},
+		},
+		{
+			name:            "should return no rows with a zero limit",
+			operation:       SetOperation{Operator: SetOperatorUnion, Left: integers, Right: floats},
+			expectedColumns: []ResultColumn{{Name: "ID", DataType: ddl.ColumnDataTypeFloat}},
+			expectedRows:    nil,
+		},
+		{
+			name:          "should return ErrColumnNotFound when sorting by unknown columns",
+			operation:     SetOperation{Operator: SetOperatorUnion, Left: integers, Right: floats, Orders: []Order{{Column: "PRICE"}}, Limit: -1},
+			expectedError: ErrColumnNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resultSet, err := testCase.operation.Run(nil)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if !slices.Equal(resultSet.Columns, testCase.expectedColumns) {
+				t.Errorf("expected columns %v, got %v", testCase.expectedColumns, resultSet.Columns)
+				return
+			}
+
+			if !slices.EqualFunc(resultSet.Rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, resultSet.Rows)
+				return
+			}
+		})
+	}
+}
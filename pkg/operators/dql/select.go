@@ -1,56 +1,359 @@
 package dql
 
 import (
+	"errors"
+	"strings"
+
 	gokvstore "github.com/gustapinto/go-kv-store"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
 	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
 )
 
-func Select(rootCollection *gokvstore.Collection, database, table string, filters []Filter) (rows []dml.Row, err error) {
-	rowCollection, err := dml.RowCollection(rootCollection, database, table)
+// Select Returns the rows of the table matched by the filters, see [SelectWhere]
+func Select(tx *storage.Tx, database, table string, filters []Filter) ([]dml.Row, error) {
+	return SelectWhere(tx, database, table, FiltersExpression(filters))
+}
+
+// SelectWhere Returns the rows of the table for which the where condition is true. When
+// the condition requires a column to be equal to a value and that column is the primary
+// key or is covered by an index, only the rows found through it are read. Otherwise, when
+// it requires the first column of an index to be in a range, only the rows in that range
+// of the index are read, and if none of them apply the whole table is scanned
+func SelectWhere(tx *storage.Tx, database, table string, where Expression) ([]dml.Row, error) {
+	definition, err := catalogTable(tx, database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectRows(tx, definition, database, table, where)
+}
+
+func SelectByPrimaryKey(tx *storage.Tx, database, table, primaryKey string) (*dml.Row, error) {
+	rowBuffer, err := tx.Get(dml.RowDataDir(database, table), primaryKey)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := encodingutils.Decode[dml.Row](rowBuffer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &row, nil
+}
+
+// catalogTable Returns the definition of the table, or nil if it is not in the catalog, in
+// which case its rows can only be scanned
+func catalogTable(tx *storage.Tx, database, table string) (*ddl.Table, error) {
+	definition, err := ddl.GetTable(tx, database, table)
+	if err != nil {
+		if errors.Is(err, ddl.ErrTableDoesNotExists) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+func selectRows(tx *storage.Tx, definition *ddl.Table, database, table string, where Expression) ([]dml.Row, error) {
+	var rows []dml.Row
+	err := scanRows(tx, definition, database, table, where, func(row dml.Row) error {
+		rows = append(rows, row)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	for key := range rowCollection.Keys() {
-		rowBuffer, err := rowCollection.Get(key)
+	return rows, nil
+}
+
+// scanRows Calls fn with each row of the table for which the where condition is true, the
+// rows are read as described in [SelectWhere]. The scan stops without errors if fn
+// returns [storage.ErrStopScan]
+func scanRows(tx *storage.Tx, definition *ddl.Table, database, table string, where Expression, fn func(row dml.Row) error) error {
+	if definition != nil {
+		required := requiredFilters(definition, where)
+
+		primaryKeys, found, err := lookupPrimaryKeys(tx, definition, required)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		if found {
+			return scanPrimaryKeys(tx, database, table, primaryKeys, where, fn)
+		}
+
+		for _, index := range ddl.TableIndexes(*definition) {
+			if keyRange, found := keyRangeForColumn(required, index.Columns[0]); found {
+				return scanThroughIndex(tx, database, table, index, keyRange, where, fn)
+			}
 		}
+	}
 
+	return tx.Scan(dml.RowDataDir(database, table), func(_ string, rowBuffer []byte) error {
 		row, err := encodingutils.Decode[dml.Row](rowBuffer)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		shouldSelectRow, err := Matches(where, row)
+		if err != nil || !shouldSelectRow {
+			return err
 		}
 
-		shouldSelectRow, err := ShouldDoActionOnRow(row, filters...)
+		return fn(row)
+	})
+}
+
+func scanPrimaryKeys(tx *storage.Tx, database, table string, primaryKeys []string, where Expression, fn func(row dml.Row) error) error {
+	for _, primaryKey := range primaryKeys {
+		row, matches, err := selectMatchingRow(tx, database, table, primaryKey, where)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		if !matches {
+			continue
 		}
 
-		if shouldSelectRow {
-			rows = append(rows, row)
+		if err := fn(row); err != nil {
+			if errors.Is(err, storage.ErrStopScan) {
+				return nil
+			}
+
+			return err
 		}
 	}
 
-	return rows, nil
+	return nil
 }
 
-func SelectByPrimaryKey(rootCollection *gokvstore.Collection, database, table, primaryKey string) (*dml.Row, error) {
-	rowCollection, err := dml.RowCollection(rootCollection, database, table)
+func selectMatchingRow(tx *storage.Tx, database, table, primaryKey string, where Expression) (dml.Row, bool, error) {
+	row, err := SelectByPrimaryKey(tx, database, table, primaryKey)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, gokvstore.ErrKeyNotFound) {
+			return dml.Row{}, false, nil
+		}
+
+		return dml.Row{}, false, err
 	}
 
-	rowBuffer, err := rowCollection.Get(primaryKey)
+	matches, err := Matches(where, *row)
 	if err != nil {
-		return nil, err
+		return dml.Row{}, false, err
 	}
 
-	row, err := encodingutils.Decode[dml.Row](rowBuffer)
+	return *row, matches, nil
+}
+
+// scanThroughIndex Reads the rows in the key range of the index, in its order
+func scanThroughIndex(tx *storage.Tx, database, table string, index ddl.Index, keyRange storage.KeyRange, where Expression, fn func(row dml.Row) error) error {
+	return dml.ScanIndex(tx, database, table, index.Name, keyRange, func(_, primaryKey string) error {
+		row, matches, err := selectMatchingRow(tx, database, table, primaryKey, where)
+		if err != nil || !matches {
+			return err
+		}
+
+		return fn(row)
+	})
+}
+
+// requiredFilters Returns the filters that every row matched by the where condition must
+// match, which are its conjunctions of filters, comparisons and BETWEENs of a column with
+// values of its type
+func requiredFilters(table *ddl.Table, where Expression) []Filter {
+	switch where := where.(type) {
+	case And:
+		return append(requiredFilters(table, where.Left), requiredFilters(table, where.Right)...)
+
+	case FilterExpression:
+		return []Filter{where.Filter}
+
+	case Comparison:
+		column, value, ok := columnComparedWithValue(table, where.Left, where.Right)
+		comparison := where.Comparison
+		if !ok {
+			column, value, ok = columnComparedWithValue(table, where.Right, where.Left)
+			comparison = mirroredComparison(comparison)
+		}
+
+		if ok {
+			return []Filter{{Column: column, Operand: FilterOperandAnd, Comparison: comparison, Value: value}}
+		}
+
+	case Between:
+		column, low, isLowValue := columnComparedWithValue(table, where.Expression, where.Low)
+		_, high, isHighValue := columnComparedWithValue(table, where.Expression, where.High)
+
+		if isLowValue && isHighValue {
+			return []Filter{{Column: column, Operand: FilterOperandAnd, Comparison: FilterComparisonBetween, Value: BetweenBounds{Low: low, High: high}}}
+		}
+	}
+
+	return nil
+}
+
+// columnComparedWithValue Checks if the expressions are a column of the table and a non
+// NULL value of its type
+func columnComparedWithValue(table *ddl.Table, columnExpression, valueExpression Expression) (string, any, bool) {
+	columnRef, isColumn := columnExpression.(ColumnRef)
+	literal, isLiteral := valueExpression.(Literal)
+	if !isColumn || !isLiteral || literal.Value == nil {
+		return "", nil, false
+	}
+
+	for _, column := range table.Columns {
+		if stringutils.EqualsIgnoreCase(column.Name, columnRef.Name) {
+			value, err := ddl.ValueForDataType(literal.Value, column.DataType)
+			return column.Name, value, err == nil
+		}
+	}
+
+	return "", nil, false
+}
+
+// mirroredComparison Returns the comparison with its sides swapped, so 1 < foo is foo > 1
+func mirroredComparison(comparison FilterComparison) FilterComparison {
+	switch comparison {
+	case FilterComparisonLess:
+		return FilterComparisonGreater
+
+	case FilterComparisonLessOrEqual:
+		return FilterComparisonGreaterOrEqual
+
+	case FilterComparisonGreater:
+		return FilterComparisonLess
+
+	case FilterComparisonGreaterOrEqual:
+		return FilterComparisonLessOrEqual
+	}
+
+	return comparison
+}
+
+// lookupPrimaryKeys Finds the primary keys of the rows that may match the filters using the
+// primary key or the best index covered by their equalities, preferring unique indexes
+// and then the ones with more columns
+func lookupPrimaryKeys(tx *storage.Tx, table *ddl.Table, required []Filter) ([]string, bool, error) {
+	values := map[string]any{}
+	for _, filter := range required {
+		if filter.Comparison == FilterComparisonEquals {
+			values[strings.ToUpper(filter.Column)] = filter.Value
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, false, nil
+	}
+
+	for _, column := range table.Columns {
+		if !ddl.ColumnIsPrimaryKey(column) {
+			continue
+		}
+
+		if value, ok := values[strings.ToUpper(column.Name)]; ok {
+			return []string{dml.PrimaryKeyForValue(value)}, true, nil
+		}
+	}
+
+	var best *ddl.Index
+	var bestKey string
+	for _, index := range ddl.TableIndexes(*table) {
+		indexValues := make([]any, 0, len(index.Columns))
+		for _, column := range index.Columns {
+			value, ok := values[strings.ToUpper(column)]
+			if !ok {
+				break
+			}
+
+			indexValues = append(indexValues, value)
+		}
+
+		if len(indexValues) != len(index.Columns) {
+			continue
+		}
+
+		isBetter := best == nil ||
+			(index.Unique && !best.Unique) ||
+			(index.Unique == best.Unique && len(index.Columns) > len(best.Columns))
+		if isBetter {
+			best = &index
+			bestKey = dml.IndexKey(indexValues...)
+		}
+	}
+
+	if best == nil {
+		return nil, false, nil
+	}
+
+	primaryKeys, err := dml.IndexEntry(tx, table.Database, table.Name, best.Name, bestKey)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return &row, nil
+	return primaryKeys, true, nil
+}
+
+// keyRangeForColumn Returns the range of the keys of an index over the column that holds
+// every value allowed by the required filters, which is open if none of them restricts
+// the column
+func keyRangeForColumn(required []Filter, column string) (keyRange storage.KeyRange, found bool) {
+	restrictStart := func(start string) {
+		if keyRange.Start == "" || start > keyRange.Start {
+			keyRange.Start = start
+		}
+	}
+
+	restrictEnd := func(end string) {
+		if keyRange.End == "" || end < keyRange.End {
+			keyRange.End = end
+		}
+	}
+
+	for _, filter := range required {
+		if !stringutils.EqualsIgnoreCase(filter.Column, column) {
+			continue
+		}
+
+		switch filter.Comparison {
+		case FilterComparisonEquals:
+			key := dml.IndexKey(filter.Value)
+			restrictStart(key)
+			restrictEnd(encodingutils.OrderedKeyPrefixEnd(key))
+
+		case FilterComparisonLess:
+			restrictEnd(dml.IndexKey(filter.Value))
+
+		case FilterComparisonLessOrEqual:
+			restrictEnd(encodingutils.OrderedKeyPrefixEnd(dml.IndexKey(filter.Value)))
+
+		case FilterComparisonGreater:
+			restrictStart(encodingutils.OrderedKeyPrefixEnd(dml.IndexKey(filter.Value)))
+
+		case FilterComparisonGreaterOrEqual:
+			restrictStart(dml.IndexKey(filter.Value))
+
+		case FilterComparisonBetween:
+			bounds, ok := filter.Value.(BetweenBounds)
+			if !ok {
+				continue
+			}
+
+			restrictStart(dml.IndexKey(bounds.Low))
+			restrictEnd(encodingutils.OrderedKeyPrefixEnd(dml.IndexKey(bounds.High)))
+
+		default:
+			continue
+		}
+
+		found = true
+	}
+
+	return keyRange, found
 }
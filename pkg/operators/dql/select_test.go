@@ -3,11 +3,13 @@ package dql
 import (
 	"errors"
 	"os"
+	"slices"
 	"testing"
 
 	gokvstore "github.com/gustapinto/go-kv-store"
 	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
 	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
 	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
 )
 
@@ -80,18 +82,19 @@ var testSelectMockedRows = []dml.Row{
 	},
 }
 
-func testSelectMockRootCollection() (*gokvstore.Collection, error) {
+func testSelectMockStorage() (*storage.Storage, error) {
 	collection, err := gokvstore.NewCollection(gokvstore.NewFsRecordStore(os.TempDir()))
 	if err != nil {
 		return nil, err
 	}
 
-	for _, row := range testSelectMockedRows {
-		rowCollection, err := dml.RowCollection(collection, row.Database, row.Table)
-		if err != nil {
-			return nil, err
-		}
+	store, err := storage.New(collection)
+	if err != nil {
+		return nil, err
+	}
 
+	tx := store.Begin()
+	for _, row := range testSelectMockedRows {
 		rowBuffer, err := encodingutils.Encode(row)
 		if err != nil {
 			return nil, err
@@ -102,12 +105,16 @@ func testSelectMockRootCollection() (*gokvstore.Collection, error) {
 			return nil, err
 		}
 
-		if err := rowCollection.Put(primaryKey, rowBuffer, false); err != nil {
+		if err := tx.Put(dml.RowDataDir(row.Database, row.Table), primaryKey, rowBuffer, false); err != nil {
 			return nil, err
 		}
 	}
 
-	return collection, nil
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
 }
 
 func TestSelect(t *testing.T) {
@@ -126,15 +133,18 @@ func TestSelect(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		rootCollection, err := testSelectMockRootCollection()
+		store, err := testSelectMockStorage()
 		if err != nil {
-			t.Errorf("not expected error when mocking root collection, got %s", err)
+			t.Errorf("not expected error when mocking storage, got %s", err)
 			return
 		}
-		defer rootCollection.Truncate()
+		defer store.TruncateAll()
 
 		t.Run(testCase.name, func(t *testing.T) {
-			actual, err := Select(rootCollection, testSelectMockedRows[0].Database, testSelectMockedRows[0].Table, testCase.filters)
+			tx := store.Begin()
+			defer tx.Rollback()
+
+			actual, err := Select(tx, testSelectMockedRows[0].Database, testSelectMockedRows[0].Table, testCase.filters)
 			if !errors.Is(err, testCase.expectedError) {
 				t.Errorf("expected %s error, got %s", testCase.expectedError.Error(), err.Error())
 				return
@@ -147,3 +157,131 @@ func TestSelect(t *testing.T) {
 		})
 	}
 }
+
+func testSelectMockIndexedStorage(t *testing.T) *storage.Storage {
+	store, err := storage.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	table := ddl.Table{
+		Database: testSelectMockedRows[0].Database,
+		Name:     testSelectMockedRows[0].Table,
+		Indexes: []ddl.Index{
+			{Name: "foo_name_idx", Columns: []string{"NAME"}},
+		},
+	}
+	for _, column := range testSelectMockedRows[0].Columns {
+		table.Columns = append(table.Columns, column.Definition)
+	}
+
+	tx := store.Begin()
+	if err := ddl.CreateTable(tx, table, false, false); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	for _, row := range testSelectMockedRows {
+		if err := dml.Insert(tx, row); err != nil {
+			t.Fatalf("not expected error when mocking storage, got %s", err)
+		}
+	}
+
+	// Drops the index entry of the first row, so it can only be found by scanning the table
+	indexDataDir := dml.IndexDataDir(table.Database, table.Name, "foo_name_idx")
+	primaryKey, err := dml.PrimaryKeyForRow(testSelectMockedRows[0])
+	if err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	if err := tx.Delete(indexDataDir, dml.IndexKey("FOO", primaryKey)); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	return store
+}
+
+func TestSelectWithIndex(t *testing.T) {
+	testCases := []struct {
+		name        string
+		filters     []Filter
+		expectedIDs []int64
+	}{
+		{
+			name: "should find rows through the index",
+			filters: []Filter{
+				{Column: "NAME", Operand: FilterOperandAnd, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "FOO2"},
+			},
+			expectedIDs: []int64{2},
+		},
+		{
+			name: "should only read the rows found through the index",
+			filters: []Filter{
+				{Column: "NAME", Operand: FilterOperandAnd, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "FOO"},
+			},
+			expectedIDs: nil,
+		},
+		{
+			name: "should apply the other filters to the rows found through the index",
+			filters: []Filter{
+				{Column: "NAME", Operand: FilterOperandAnd, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "FOO2"},
+				{Column: "DESCRIPTION", Operand: FilterOperandAnd, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "BAR"},
+			},
+			expectedIDs: nil,
+		},
+		{
+			name: "should find rows through the primary key",
+			filters: []Filter{
+				{Column: "ID", Operand: FilterOperandAnd, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: int64(1)},
+			},
+			expectedIDs: []int64{1},
+		},
+		{
+			name: "should scan the table when a filter is combined with OR",
+			filters: []Filter{
+				{Column: "NAME", Operand: FilterOperandAnd, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "FOO"},
+				{Column: "DESCRIPTION", Operand: FilterOperandOr, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "BAR2"},
+			},
+			expectedIDs: []int64{1, 2},
+		},
+		{
+			name: "should use the index for the filters after the last OR",
+			filters: []Filter{
+				{Column: "DESCRIPTION", Operand: FilterOperandAnd, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "BAR"},
+				{Column: "DESCRIPTION", Operand: FilterOperandOr, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "BAR2"},
+				{Column: "NAME", Operand: FilterOperandAnd, Comparison: FilterComparisonEquals, Where: WhereColumnEquals, Value: "FOO"},
+			},
+			expectedIDs: nil,
+		},
+	}
+
+	store := testSelectMockIndexedStorage(t)
+	defer store.Close()
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			tx := store.Begin()
+			defer tx.Rollback()
+
+			rows, err := Select(tx, testSelectMockedRows[0].Database, testSelectMockedRows[0].Table, testCase.filters)
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			var ids []int64
+			for _, row := range rows {
+				ids = append(ids, row.Columns[0].Value.(int64))
+			}
+			slices.Sort(ids)
+
+			if !slices.Equal(ids, testCase.expectedIDs) {
+				t.Errorf("expected rows with ids %v, got %v", testCase.expectedIDs, ids)
+				return
+			}
+		})
+	}
+}
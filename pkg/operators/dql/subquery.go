@@ -0,0 +1,137 @@
+package dql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var ErrSubqueryReturnedManyRows = errors.New("more than one row returned by a subquery used as an expression")
+
+// OuterRow The row of the outer query a correlated [Subquery] is running for
+type OuterRow struct {
+	row *dml.Row
+}
+
+// OuterRef A column of the row of the outer query of a correlated [Subquery]
+type OuterRef struct {
+	Name string
+	Row  *OuterRow
+}
+
+func (o OuterRef) Evaluate(dml.Row) (any, error) {
+	if o.Row == nil || o.Row.row == nil {
+		return nil, fmt.Errorf("%w: %s outside of its subquery", ErrColumnNotFound, o.Name)
+	}
+
+	return ColumnRef{Name: o.Name}.Evaluate(*o.Row.row)
+}
+
+// Subquery A query run by the expressions of another query, in the transaction the query
+// was planned in. A correlated subquery reads the row it runs for through the [OuterRef]s
+// to its outer row, so it runs again for every row, while a subquery that is not correlated
+// runs only once and its result is reused
+type Subquery struct {
+	tx     *storage.Tx
+	query  Query
+	outer  *OuterRow
+	result *ResultSet
+}
+
+// NewSubquery Returns a subquery for the query, which is correlated if it has an outer row
+func NewSubquery(tx *storage.Tx, query Query, outer *OuterRow) *Subquery {
+	return &Subquery{tx: tx, query: query, outer: outer}
+}
+
+func (s *Subquery) Columns() []ResultColumn {
+	return s.query.Columns()
+}
+
+// Run Returns the result of the subquery for the row of the outer query
+func (s *Subquery) Run(row dml.Row) (ResultSet, error) {
+	if s.outer == nil {
+		if s.result == nil {
+			result, err := s.query.Run(s.tx)
+			if err != nil {
+				return ResultSet{}, err
+			}
+
+			s.result = &result
+		}
+
+		return *s.result, nil
+	}
+
+	// Subqueries of the subquery may run it again for other rows, so the outer row is
+	// restored once it is done
+	previous := s.outer.row
+	s.outer.row = &row
+	defer func() { s.outer.row = previous }()
+
+	return s.query.Run(s.tx)
+}
+
+// ScalarSubquery The value of the single column of the single row returned by the subquery,
+// or NULL if it returns no rows
+type ScalarSubquery struct {
+	Subquery *Subquery
+}
+
+func (s ScalarSubquery) Evaluate(row dml.Row) (any, error) {
+	result, err := s.Subquery.Run(row)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(result.Rows) {
+	case 0:
+		return nil, nil
+
+	case 1:
+		return result.Rows[0][0], nil
+	}
+
+	return nil, ErrSubqueryReturnedManyRows
+}
+
+// Exists Checks if the subquery returns any row
+type Exists struct {
+	Subquery *Subquery
+}
+
+func (e Exists) Evaluate(row dml.Row) (any, error) {
+	result, err := e.Subquery.Run(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return len(result.Rows) > 0, nil
+}
+
+// InSubquery Checks if the value is equal to the value of the single column of any row
+// returned by the subquery, with the NULL logic of [In]
+type InSubquery struct {
+	Expression Expression
+	Subquery   *Subquery
+}
+
+func (i InSubquery) Evaluate(row dml.Row) (any, error) {
+	value, err := i.Expression.Evaluate(row)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := i.Subquery.Run(row)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]Expression, 0, len(result.Rows))
+	for _, resultRow := range result.Rows {
+		values = append(values, Literal{Value: resultRow[0]})
+	}
+
+	return In{Expression: Literal{Value: value}, Values: values}.Evaluate(row)
+}
@@ -0,0 +1,152 @@
+package dql
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+// countedQuery A query that counts how many times it runs
+type countedQuery struct {
+	Query
+	runs *int
+}
+
+func (c countedQuery) Run(tx *storage.Tx) (ResultSet, error) {
+	*c.runs++
+	return c.Query.Run(tx)
+}
+
+// outerValueQuery A query that results in the value of a column of its outer row
+type outerValueQuery struct {
+	value OuterRef
+}
+
+func (o outerValueQuery) Columns() []ResultColumn {
+	return []ResultColumn{{Name: o.value.Name, DataType: ddl.ColumnDataTypeInteger}}
+}
+
+func (o outerValueQuery) Run(*storage.Tx) (ResultSet, error) {
+	value, err := o.value.Evaluate(dml.Row{})
+	if err != nil {
+		return ResultSet{}, err
+	}
+
+	return ResultSet{Columns: o.Columns(), Rows: [][]any{{value}}}, nil
+}
+
+func TestSubquery(t *testing.T) {
+	newRow := func(id any) dml.Row {
+		return dml.Row{Columns: []dml.Column{{Definition: ddl.Column{Name: "ID", DataType: ddl.ColumnDataTypeInteger}, Value: id}}}
+	}
+
+	mockedRows := []dml.Row{newRow(int64(1)), newRow(int64(2)), newRow(nil)}
+
+	values := func(rows ...any) valuesQuery {
+		query := valuesQuery{columns: []ResultColumn{{Name: "ID", DataType: ddl.ColumnDataTypeInteger}}}
+		for _, value := range rows {
+			query.rows = append(query.rows, []any{value})
+		}
+
+		return query
+	}
+
+	testCases := []struct {
+		name           string
+		newExpression  func(runs *int) Expression
+		expectedValues []any
+		expectedRuns   int
+		expectedError  error
+	}{
+		{
+			name: "should run subqueries that are not correlated once",
+			newExpression: func(runs *int) Expression {
+				return ScalarSubquery{Subquery: NewSubquery(nil, countedQuery{Query: values(int64(7)), runs: runs}, nil)}
+			},
+			expectedValues: []any{int64(7), int64(7), int64(7)},
+			expectedRuns:   1,
+		},
+		{
+			name: "should run correlated subqueries for every row of the outer query",
+			newExpression: func(runs *int) Expression {
+				outer := &OuterRow{}
+				query := countedQuery{Query: outerValueQuery{value: OuterRef{Name: "ID", Row: outer}}, runs: runs}
+				return ScalarSubquery{Subquery: NewSubquery(nil, query, outer)}
+			},
+			expectedValues: []any{int64(1), int64(2), nil},
+			expectedRuns:   3,
+		},
+		{
+			name: "should evaluate scalar subqueries without rows to NULL",
+			newExpression: func(runs *int) Expression {
+				return ScalarSubquery{Subquery: NewSubquery(nil, countedQuery{Query: values(), runs: runs}, nil)}
+			},
+			expectedValues: []any{nil, nil, nil},
+			expectedRuns:   1,
+		},
+		{
+			name: "should return ErrSubqueryReturnedManyRows for scalar subqueries with many rows",
+			newExpression: func(runs *int) Expression {
+				return ScalarSubquery{Subquery: NewSubquery(nil, countedQuery{Query: values(int64(1), int64(2)), runs: runs}, nil)}
+			},
+			expectedRuns:  1,
+			expectedError: ErrSubqueryReturnedManyRows,
+		},
+		{
+			name: "should check if subqueries return rows with EXISTS",
+			newExpression: func(runs *int) Expression {
+				outer := &OuterRow{}
+				query := countedQuery{Query: outerValueQuery{value: OuterRef{Name: "ID", Row: outer}}, runs: runs}
+				return Not{Expression: Exists{Subquery: NewSubquery(nil, query, outer)}}
+			},
+			expectedValues: []any{false, false, false},
+			expectedRuns:   3,
+		},
+		{
+			name: "should compare with the values of subqueries with IN, with NULL logic",
+			newExpression: func(runs *int) Expression {
+				query := countedQuery{Query: values(int64(1), nil), runs: runs}
+				return InSubquery{Expression: ColumnRef{Name: "ID"}, Subquery: NewSubquery(nil, query, nil)}
+			},
+			expectedValues: []any{true, nil, nil},
+			expectedRuns:   1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			runs := 0
+			expression := testCase.newExpression(&runs)
+
+			var evaluated []any
+			var err error
+			for _, row := range mockedRows {
+				var value any
+				if value, err = expression.Evaluate(row); err != nil {
+					break
+				}
+
+				evaluated = append(evaluated, value)
+			}
+
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if !slices.Equal(evaluated, testCase.expectedValues) {
+				t.Errorf("expected values %v, got %v", testCase.expectedValues, evaluated)
+				return
+			}
+
+			if runs != testCase.expectedRuns {
+				t.Errorf("expected %d runs, got %d", testCase.expectedRuns, runs)
+				return
+			}
+		})
+	}
+}
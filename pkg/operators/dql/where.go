@@ -2,7 +2,7 @@ package dql
 
 import (
 	"errors"
-	"fmt"
+	"strings"
 
 	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
 	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
@@ -13,11 +13,16 @@ type WhereFunc func(row dml.Row, column string, value any) (bool, error)
 
 type FilterOperand string
 
+// FilterComparison The comparison made by the filter WhereFunc, used to pick an index that
+// can answer it
+type FilterComparison string
+
 type Filter struct {
-	Column  string
-	Operand FilterOperand
-	Where   WhereFunc
-	Value   any
+	Column     string
+	Operand    FilterOperand
+	Comparison FilterComparison
+	Where      WhereFunc
+	Value      any
 }
 
 var (
@@ -27,55 +32,203 @@ var (
 	FilterOperandAndNot FilterOperand = "AND NOT"
 )
 
+var (
+	FilterComparisonEquals         FilterComparison = "="
+	FilterComparisonNotEquals      FilterComparison = "<>"
+	FilterComparisonLess           FilterComparison = "<"
+	FilterComparisonLessOrEqual    FilterComparison = "<="
+	FilterComparisonGreater        FilterComparison = ">"
+	FilterComparisonGreaterOrEqual FilterComparison = ">="
+	FilterComparisonBetween        FilterComparison = "BETWEEN"
+	FilterComparisonIn             FilterComparison = "IN"
+	FilterComparisonLike           FilterComparison = "LIKE"
+	FilterComparisonILike          FilterComparison = "ILIKE"
+	FilterComparisonIsNull         FilterComparison = "IS NULL"
+)
+
+// BetweenBounds The value of a BETWEEN filter, both bounds are included
+type BetweenBounds struct {
+	Low  any
+	High any
+}
+
 var (
 	ErrColumnNotFound  = errors.New("column not found")
 	ErrInvalidDataType = errors.New("invalid data type")
 )
 
-func WhereColumnEquals(row dml.Row, column string, value any) (bool, error) {
+func rowColumn(row dml.Row, column string) (dml.Column, error) {
 	for _, c := range row.Columns {
-		if !stringutils.EqualsIgnoreCase(c.Definition.Name, column) {
-			continue
+		if stringutils.EqualsIgnoreCase(c.Definition.Name, column) {
+			return c, nil
 		}
+	}
 
-		if !ddl.ValueHasCorrectTypeForColumn(value, c.Definition) {
-			return false, ErrInvalidDataType
-		}
+	return dml.Column{}, ErrColumnNotFound
+}
+
+// whereColumnCompares Compares the column value with the value, which must have the type
+// of the column, and checks the result with matches. NULL never matches, either as the
+// column value or as the value
+func whereColumnCompares(row dml.Row, column string, value any, matches func(result int) bool) (bool, error) {
+	c, err := rowColumn(row, column)
+	if err != nil {
+		return false, err
+	}
+
+	value, err = ddl.ValueForDataType(value, c.Definition.DataType)
+	if err != nil {
+		return false, ErrInvalidDataType
+	}
+
+	if c.Value == nil || value == nil {
+		return false, nil
+	}
 
-		return fmt.Sprint(value) == fmt.Sprint(c.Value), nil
+	result, err := CompareValues(c.Value, value)
+	if err != nil {
+		return false, err
 	}
 
-	return false, ErrColumnNotFound
+	return matches(result), nil
 }
 
-func ShouldDoActionOnRow(row dml.Row, filters ...Filter) (bool, error) {
-	shouldDoAction := true
-	for _, filter := range filters {
-		isMatch, err := filter.Where(row, filter.Column, filter.Value)
-		if err != nil {
-			return false, err
-		}
+func WhereColumnEquals(row dml.Row, column string, value any) (bool, error) {
+	return whereColumnCompares(row, column, value, func(result int) bool { return result == 0 })
+}
 
-		if filter.Operand == FilterOperandAnd {
-			shouldDoAction = shouldDoAction && isMatch
-			continue
-		}
+func WhereColumnNotEquals(row dml.Row, column string, value any) (bool, error) {
+	return whereColumnCompares(row, column, value, func(result int) bool { return result != 0 })
+}
 
-		if filter.Operand == FilterOperandAndNot {
-			shouldDoAction = shouldDoAction && !isMatch
-			continue
-		}
+func WhereColumnLess(row dml.Row, column string, value any) (bool, error) {
+	return whereColumnCompares(row, column, value, func(result int) bool { return result < 0 })
+}
+
+func WhereColumnLessOrEqual(row dml.Row, column string, value any) (bool, error) {
+	return whereColumnCompares(row, column, value, func(result int) bool { return result <= 0 })
+}
+
+func WhereColumnGreater(row dml.Row, column string, value any) (bool, error) {
+	return whereColumnCompares(row, column, value, func(result int) bool { return result > 0 })
+}
+
+func WhereColumnGreaterOrEqual(row dml.Row, column string, value any) (bool, error) {
+	return whereColumnCompares(row, column, value, func(result int) bool { return result >= 0 })
+}
+
+// WhereColumnBetween Checks if the column value is between the [BetweenBounds] value,
+// both bounds included
+func WhereColumnBetween(row dml.Row, column string, value any) (bool, error) {
+	bounds, ok := value.(BetweenBounds)
+	if !ok {
+		return false, ErrInvalidDataType
+	}
+
+	isAboveLow, err := WhereColumnGreaterOrEqual(row, column, bounds.Low)
+	if err != nil || !isAboveLow {
+		return false, err
+	}
 
-		if filter.Operand == FilterOperandOr {
-			shouldDoAction = shouldDoAction || isMatch
-			continue
+	return WhereColumnLessOrEqual(row, column, bounds.High)
+}
+
+// WhereColumnIn Checks if the column value is equal to any value of the []any value
+func WhereColumnIn(row dml.Row, column string, value any) (bool, error) {
+	values, ok := value.([]any)
+	if !ok {
+		return false, ErrInvalidDataType
+	}
+
+	for _, v := range values {
+		isMatch, err := WhereColumnEquals(row, column, v)
+		if err != nil || isMatch {
+			return isMatch, err
 		}
+	}
+
+	return false, nil
+}
+
+// WhereColumnLike Matches the column value against the pattern value, where "%" matches
+// any sequence of characters and "_" matches a single character
+func WhereColumnLike(row dml.Row, column string, value any) (bool, error) {
+	return whereColumnMatches(row, column, value, false)
+}
+
+// WhereColumnILike Is a case insensitive [WhereColumnLike]
+func WhereColumnILike(row dml.Row, column string, value any) (bool, error) {
+	return whereColumnMatches(row, column, value, true)
+}
+
+func whereColumnMatches(row dml.Row, column string, value any, ignoreCase bool) (bool, error) {
+	c, err := rowColumn(row, column)
+	if err != nil {
+		return false, err
+	}
+
+	pattern, ok := value.(string)
+	if !ok || c.Definition.DataType != ddl.ColumnDataTypeText {
+		return false, ErrInvalidDataType
+	}
+
+	text, ok := c.Value.(string)
+	if !ok {
+		return false, nil
+	}
 
-		if filter.Operand == FilterOperandOrNot {
-			shouldDoAction = shouldDoAction || !isMatch
-			continue
+	if ignoreCase {
+		text, pattern = strings.ToLower(text), strings.ToLower(pattern)
+	}
+
+	return matchesLikePattern([]rune(text), []rune(pattern)), nil
+}
+
+// matchesLikePattern Matches the text against the pattern, when a "%" is followed by a
+// mismatch the match is retried with it consuming one more character
+func matchesLikePattern(text, pattern []rune) bool {
+	t, p := 0, 0
+	wildcard, wildcardText := -1, 0
+
+	for t < len(text) {
+		switch {
+		case p < len(pattern) && pattern[p] == '%':
+			wildcard, wildcardText = p, t
+			p++
+
+		case p < len(pattern) && (pattern[p] == '_' || pattern[p] == text[t]):
+			t++
+			p++
+
+		case wildcard != -1:
+			wildcardText++
+			t, p = wildcardText, wildcard+1
+
+		default:
+			return false
 		}
 	}
 
-	return shouldDoAction, nil
+	for p < len(pattern) && pattern[p] == '%' {
+		p++
+	}
+
+	return p == len(pattern)
+}
+
+// WhereColumnIsNull Checks if the column value is NULL. Filters with it must have the
+// [FilterComparisonIsNull] comparison, or they are unknown for NULL values, see
+// [FilterExpression]
+func WhereColumnIsNull(row dml.Row, column string, _ any) (bool, error) {
+	c, err := rowColumn(row, column)
+	if err != nil {
+		return false, err
+	}
+
+	return c.Value == nil, nil
+}
+
+// ShouldDoActionOnRow Checks if the row matches the filters, see [FiltersExpression]
+func ShouldDoActionOnRow(row dml.Row, filters ...Filter) (bool, error) {
+	return Matches(FiltersExpression(filters), row)
 }
@@ -109,10 +109,28 @@ func TestWhereColumnEquals(t *testing.T) {
 			expectedValue: false,
 			expectedError: ErrInvalidDataType,
 		},
+		{
+			name:   "should return true for the same instant in another zone",
+			column: "timestamp",
+			value:  time.Date(2024, time.March, 1, 10, 0, 0, 0, time.FixedZone("", 2*60*60)),
+			row: dml.Row{
+				Columns: []dml.Column{
+					{
+						Definition: ddl.Column{
+							Name:     "TIMESTAMP",
+							DataType: ddl.ColumnDataTypeTimestamp,
+						},
+						Value: time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+			expectedValue: true,
+			expectedError: nil,
+		},
 		{
 			name:   "should return ErrInvalidDataType when value type is not supported",
 			column: "timestamp",
-			value:  time.Now(),
+			value:  time.Now().UnixMilli(),
 			row: dml.Row{
 				Columns: []dml.Column{
 					{
@@ -120,7 +138,7 @@ func TestWhereColumnEquals(t *testing.T) {
 							Name:     "TIMESTAMP",
 							DataType: ddl.ColumnDataTypeTimestamp,
 						},
-						Value: time.Now().UnixMilli(),
+						Value: time.Now(),
 					},
 				},
 			},
@@ -145,6 +163,135 @@ func TestWhereColumnEquals(t *testing.T) {
 	}
 }
 
+func TestWhereColumnComparisons(t *testing.T) {
+	mockedRow := dml.Row{
+		Columns: []dml.Column{
+			{
+				Definition: ddl.Column{
+					Name:     "ID",
+					DataType: ddl.ColumnDataTypeInteger,
+				},
+				Value: int64(9),
+			},
+			{
+				Definition: ddl.Column{
+					Name:     "NAME",
+					DataType: ddl.ColumnDataTypeText,
+				},
+				Value: "Foo%Bar",
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		where         WhereFunc
+		column        string
+		value         any
+		expectedValue bool
+		expectedError error
+	}{
+		{
+			name:          "should compare integers as numbers instead of strings",
+			where:         WhereColumnLess,
+			column:        "id",
+			value:         int64(10),
+			expectedValue: true,
+		},
+		{
+			name:          "should match values different from the column value",
+			where:         WhereColumnNotEquals,
+			column:        "id",
+			value:         int64(9),
+			expectedValue: false,
+		},
+		{
+			name:          "should match values between inclusive bounds",
+			where:         WhereColumnBetween,
+			column:        "id",
+			value:         BetweenBounds{Low: int64(1), High: int64(9)},
+			expectedValue: true,
+		},
+		{
+			name:          "should match values in a list",
+			where:         WhereColumnIn,
+			column:        "id",
+			value:         []any{int64(1), int64(9)},
+			expectedValue: true,
+		},
+		{
+			name:          "should match patterns with wildcards",
+			where:         WhereColumnLike,
+			column:        "name",
+			value:         "F_o%r",
+			expectedValue: true,
+		},
+		{
+			name:          "should match patterns with a wildcard that must backtrack",
+			where:         WhereColumnLike,
+			column:        "name",
+			value:         "%o%Ba_",
+			expectedValue: true,
+		},
+		{
+			name:          "should match patterns case sensitively",
+			where:         WhereColumnLike,
+			column:        "name",
+			value:         "foo%",
+			expectedValue: false,
+		},
+		{
+			name:          "should match patterns case insensitively",
+			where:         WhereColumnILike,
+			column:        "name",
+			value:         "foo%bar",
+			expectedValue: true,
+		},
+		{
+			name:          "should not match a NOT NULL value as NULL",
+			where:         WhereColumnIsNull,
+			column:        "name",
+			expectedValue: false,
+		},
+		{
+			name:          "should return ErrInvalidDataType when comparing with another type",
+			where:         WhereColumnGreater,
+			column:        "id",
+			value:         "9",
+			expectedError: ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType when a list value has another type",
+			where:         WhereColumnIn,
+			column:        "id",
+			value:         []any{"9"},
+			expectedError: ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType when matching patterns against other types",
+			where:         WhereColumnLike,
+			column:        "id",
+			value:         "9%",
+			expectedError: ErrInvalidDataType,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			value, err := testCase.where(mockedRow, testCase.column, testCase.value)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected to error with %s, got %s", testCase.expectedError, err)
+				return
+			}
+
+			if value != testCase.expectedValue {
+				t.Errorf("expected value %v, got %v", testCase.expectedValue, value)
+				return
+			}
+		})
+	}
+}
+
 func TestShouldDoActionOnRow(t *testing.T) {
 	mockedRow := dml.Row{
 		Columns: []dml.Column{
@@ -0,0 +1,300 @@
+package dql
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+)
+
+// WindowFunction The function a [Window] computes for each row of its partition, which is
+// an aggregate function if it is named as one
+type WindowFunction string
+
+var (
+	WindowFunctionRowNumber WindowFunction = "ROW_NUMBER"
+	WindowFunctionRank      WindowFunction = "RANK"
+	WindowFunctionDenseRank WindowFunction = "DENSE_RANK"
+	WindowFunctionLag       WindowFunction = "LAG"
+	WindowFunctionLead      WindowFunction = "LEAD"
+)
+
+// FrameBoundType Where a [FrameBound] is, relative to the current row
+type FrameBoundType string
+
+var (
+	FrameBoundUnboundedPreceding FrameBoundType = "UNBOUNDED PRECEDING"
+	FrameBoundPreceding          FrameBoundType = "PRECEDING"
+	FrameBoundCurrentRow         FrameBoundType = "CURRENT ROW"
+	FrameBoundFollowing          FrameBoundType = "FOLLOWING"
+	FrameBoundUnboundedFollowing FrameBoundType = "UNBOUNDED FOLLOWING"
+)
+
+// FrameBound A bound of a [Frame], Offset rows before or after the current row for
+// PRECEDING and FOLLOWING bounds
+type FrameBound struct {
+	Type   FrameBoundType
+	Offset int
+}
+
+// Frame The rows of its partition an aggregate [Window] aggregates for each row, from the
+// Start row to the End row, both included
+type Frame struct {
+	Start FrameBound
+	End   FrameBound
+}
+
+// Window A window function, its result for each row is the value of Column in the row.
+// The rows are partitioned by the values of the PartitionBy columns, NULLs being one
+// partition, and each partition is sorted by the orders, rows equal by the orders being
+// peers.
+//
+// ROW_NUMBER numbers the rows of the partition from 1, RANK is the number of the first
+// peer of the row and DENSE_RANK the number of its group of peers. LAG and LEAD evaluate
+// the expression for the row Offset rows before or after the row, or evaluate Default for
+// the row if there is no such row, NULL without Default. Aggregate functions aggregate the
+// expression over the rows of the Frame, which without a Frame are every row up to the
+// last peer of the row, or the whole partition without orders. NewState is the state of a
+// user defined aggregate function, see [Aggregate]
+type Window struct {
+	Column      ddl.Column
+	Function    WindowFunction
+	Expression  Expression
+	Offset      int
+	Default     Expression
+	PartitionBy []string
+	Orders      []Order
+	Frame       *Frame
+	NewState    func() AggregateState
+}
+
+// ComputeWindows Appends the column of each window to the rows, the rows being returned
+// in the order of the last window
+func ComputeWindows(rows []dml.Row, windows []Window) ([]dml.Row, error) {
+	for _, window := range windows {
+		var err error
+		if rows, err = window.compute(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	return rows, nil
+}
+
+func (w Window) compute(rows []dml.Row) ([]dml.Row, error) {
+	partitions, err := w.partitions(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	computed := make([]dml.Row, 0, len(rows))
+	for _, partition := range partitions {
+		if err := SortRows(partition, w.Orders...); err != nil {
+			return nil, err
+		}
+
+		values, err := w.values(partition)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, row := range partition {
+			row.Columns = append(slices.Clip(row.Columns), dml.Column{Definition: w.Column, Value: values[i]})
+			computed = append(computed, row)
+		}
+	}
+
+	return computed, nil
+}
+
+// partitions Returns the rows of each partition, in the order their first row was read
+func (w Window) partitions(rows []dml.Row) ([][]dml.Row, error) {
+	var partitions [][]dml.Row
+	positions := make(map[string]int)
+	for _, row := range rows {
+		values := make([]any, 0, len(w.PartitionBy))
+		for _, name := range w.PartitionBy {
+			column, err := rowColumn(row, name)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", err, name)
+			}
+
+			values = append(values, column.Value)
+		}
+
+		key := encodingutils.EncodeOrderedKey(values...)
+		position, exists := positions[key]
+		if !exists {
+			position = len(partitions)
+			positions[key] = position
+			partitions = append(partitions, nil)
+		}
+
+		partitions[position] = append(partitions[position], row)
+	}
+
+	return partitions, nil
+}
+
+// values Returns the value of the window for each row of the sorted partition
+func (w Window) values(partition []dml.Row) ([]any, error) {
+	switch w.Function {
+	case WindowFunctionRowNumber, WindowFunctionRank, WindowFunctionDenseRank:
+		return w.ranks(partition)
+
+	case WindowFunctionLag, WindowFunctionLead:
+		return w.offsetValues(partition)
+	}
+
+	return w.aggregates(partition)
+}
+
+func (w Window) ranks(partition []dml.Row) ([]any, error) {
+	peerEnds, err := w.peerEnds(partition)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]any, 0, len(partition))
+	rank, denseRank := int64(0), int64(0)
+	for i := range partition {
+		if i == 0 || peerEnds[i-1] == i {
+			rank, denseRank = int64(i+1), denseRank+1
+		}
+
+		switch w.Function {
+		case WindowFunctionRowNumber:
+			values = append(values, int64(i+1))
+
+		case WindowFunctionRank:
+			values = append(values, rank)
+
+		default:
+			values = append(values, denseRank)
+		}
+	}
+
+	return values, nil
+}
+
+func (w Window) offsetValues(partition []dml.Row) ([]any, error) {
+	offset := w.Offset
+	if w.Function == WindowFunctionLag {
+		offset = -offset
+	}
+
+	values := make([]any, 0, len(partition))
+	for i, row := range partition {
+		expression, evaluatedRow := w.Default, row
+		if j := i + offset; j >= 0 && j < len(partition) {
+			expression, evaluatedRow = w.Expression, partition[j]
+		}
+
+		var value any
+		if expression != nil {
+			var err error
+			if value, err = expression.Evaluate(evaluatedRow); err != nil {
+				return nil, err
+			}
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// aggregates Aggregates the frame of each row. Frames that start at the first row only grow,
+// so their rows are added to a single accumulator, otherwise each frame is aggregated again
+func (w Window) aggregates(partition []dml.Row) ([]any, error) {
+	peerEnds, err := w.peerEnds(partition)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate := Aggregate{Function: AggregateFunction(w.Function), NewState: w.NewState}
+	growing := w.Frame == nil || w.Frame.Start.Type == FrameBoundUnboundedPreceding
+
+	values := make([]any, 0, len(partition))
+	acc, added := newAccumulator(aggregate), 0
+	for i := range partition {
+		start, end := w.frame(partition, peerEnds, i)
+		if !growing {
+			acc, added = newAccumulator(aggregate), start
+		}
+
+		for ; added < end; added++ {
+			if err := accumulate(acc, w.Expression, partition[added]); err != nil {
+				return nil, err
+			}
+		}
+
+		value, err := acc.result()
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// frame Returns the first row of the frame of the row and the row after its last one
+func (w Window) frame(partition []dml.Row, peerEnds []int, row int) (int, int) {
+	if w.Frame == nil {
+		if len(w.Orders) == 0 {
+			return 0, len(partition)
+		}
+
+		return 0, peerEnds[row]
+	}
+
+	start := min(max(frameBoundRow(w.Frame.Start, row, len(partition)), 0), len(partition))
+	end := min(max(frameBoundRow(w.Frame.End, row, len(partition))+1, start), len(partition))
+
+	return start, end
+}
+
+// frameBoundRow Returns the row of the bound, which may be out of the partition
+func frameBoundRow(bound FrameBound, row, rows int) int {
+	switch bound.Type {
+	case FrameBoundUnboundedPreceding:
+		return 0
+
+	case FrameBoundPreceding:
+		return row - bound.Offset
+
+	case FrameBoundFollowing:
+		return row + bound.Offset
+
+	case FrameBoundUnboundedFollowing:
+		return rows - 1
+	}
+
+	return row
+}
+
+// peerEnds Returns for each row of the sorted partition the row after its last peer
+func (w Window) peerEnds(partition []dml.Row) ([]int, error) {
+	ends := make([]int, len(partition))
+	end := len(partition)
+	for i := len(partition) - 1; i >= 0; i-- {
+		if i < len(partition)-1 {
+			result, err := compareRows(partition[i], partition[i+1], w.Orders)
+			if err != nil {
+				return nil, err
+			}
+
+			if result != 0 {
+				end = i + 1
+			}
+		}
+
+		ends[i] = end
+	}
+
+	return ends, nil
+}
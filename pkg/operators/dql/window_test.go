@@ -0,0 +1,132 @@
+package dql
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+)
+
+func TestComputeWindows(t *testing.T) {
+	newRow := func(kind, amount any) dml.Row {
+		return dml.Row{
+			Columns: []dml.Column{
+				{Definition: ddl.Column{Name: "KIND", DataType: ddl.ColumnDataTypeText}, Value: kind},
+				{Definition: ddl.Column{Name: "AMOUNT", DataType: ddl.ColumnDataTypeInteger}, Value: amount},
+			},
+		}
+	}
+
+	mockedRows := []dml.Row{
+		newRow("b", int64(3)),
+		newRow(nil, int64(1)),
+		newRow("a", int64(2)),
+		newRow("b", int64(1)),
+		newRow("b", nil),
+		newRow(nil, int64(4)),
+	}
+
+	amount := ColumnRef{Name: "AMOUNT"}
+	byAmount := []Order{{Column: "AMOUNT"}}
+	column := ddl.Column{Name: "W", DataType: ddl.ColumnDataTypeInteger}
+
+	testCases := []struct {
+		name          string
+		windows       []Window
+		expectedRows  [][]any
+		expectedError error
+	}{
+		{
+			name: "should partition rows in the order they are read, NULLs being one partition",
+			windows: []Window{
+				{Column: column, Function: WindowFunctionRowNumber, PartitionBy: []string{"KIND"}, Orders: byAmount},
+			},
+			expectedRows: [][]any{
+				{"b", nil, int64(1)},
+				{"b", int64(1), int64(2)},
+				{"b", int64(3), int64(3)},
+				{nil, int64(1), int64(1)},
+				{nil, int64(4), int64(2)},
+				{"a", int64(2), int64(1)},
+			},
+		},
+		{
+			name: "should aggregate sliding frames, clipped to the partition",
+			windows: []Window{{
+				Column:     column,
+				Function:   WindowFunction(AggregateFunctionSum),
+				Expression: amount,
+				Orders:     byAmount,
+				Frame:      &Frame{Start: FrameBound{Type: FrameBoundPreceding, Offset: 1}, End: FrameBound{Type: FrameBoundFollowing, Offset: 1}},
+			}},
+			expectedRows: [][]any{
+				{"b", nil, int64(1)},
+				{nil, int64(1), int64(2)},
+				{"b", int64(1), int64(4)},
+				{"a", int64(2), int64(6)},
+				{"b", int64(3), int64(9)},
+				{nil, int64(4), int64(7)},
+			},
+		},
+		{
+			name: "should aggregate empty frames as aggregates without values",
+			windows: []Window{
+				{
+					Column:   column,
+					Function: WindowFunction(AggregateFunctionCount),
+					Orders:   byAmount,
+					Frame:    &Frame{Start: FrameBound{Type: FrameBoundFollowing, Offset: 5}, End: FrameBound{Type: FrameBoundUnboundedFollowing}},
+				},
+				{
+					Column:     ddl.Column{Name: "MAX", DataType: ddl.ColumnDataTypeInteger},
+					Function:   WindowFunction(AggregateFunctionMax),
+					Expression: amount,
+					Orders:     byAmount,
+					Frame:      &Frame{Start: FrameBound{Type: FrameBoundUnboundedPreceding}, End: FrameBound{Type: FrameBoundPreceding, Offset: 1}},
+				},
+			},
+			expectedRows: [][]any{
+				{"b", nil, int64(1), nil},
+				{nil, int64(1), int64(0), nil},
+				{"b", int64(1), int64(0), int64(1)},
+				{"a", int64(2), int64(0), int64(1)},
+				{"b", int64(3), int64(0), int64(2)},
+				{nil, int64(4), int64(0), int64(3)},
+			},
+		},
+		{
+			name: "should return ErrColumnNotFound for unknown partition columns",
+			windows: []Window{
+				{Column: column, Function: WindowFunctionRank, PartitionBy: []string{"UNKNOWN"}},
+			},
+			expectedError: ErrColumnNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rows, err := ComputeWindows(slices.Clone(mockedRows), testCase.windows)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			var values [][]any
+			for _, row := range rows {
+				rowValues := make([]any, 0, len(row.Columns))
+				for _, column := range row.Columns {
+					rowValues = append(rowValues, column.Value)
+				}
+
+				values = append(values, rowValues)
+			}
+
+			if !slices.EqualFunc(values, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, values)
+				return
+			}
+		})
+	}
+}
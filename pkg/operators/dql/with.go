@@ -0,0 +1,178 @@
+package dql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+)
+
+// DefaultMaxRecursiveIterations How many times the recursive query of a [CommonTable] can
+// run when no other limit is given
+const DefaultMaxRecursiveIterations = 1000
+
+var ErrRecursionLimitExceeded = errors.New("recursive query exceeded the iteration limit")
+
+// CommonTable A named query of a WITH clause, which the queries of the clause read as a
+// table with the columns of the query. Its rows are computed the first time they are read
+// in each run of the query that has the WITH clause, and reused afterwards.
+//
+// A recursive common table starts with the rows of Query and runs Recursive, which reads
+// the rows added by its previous run as the rows of the common table, until a run adds no
+// rows. Without All the rows equal to a row already added are dropped, as with UNION. It
+// fails with ErrRecursionLimitExceeded if Recursive still adds rows after running
+// MaxIterations times
+type CommonTable struct {
+	Name          string
+	Columns       []ddl.Column
+	Query         Query
+	Recursive     Query
+	All           bool
+	MaxIterations int
+
+	rows      [][]any
+	computed  bool
+	iterating bool
+	working   [][]any
+}
+
+// Definition Returns the common table as a table without constraints
+func (c *CommonTable) Definition() *ddl.Table {
+	return &ddl.Table{Name: c.Name, Columns: c.Columns}
+}
+
+// reset Drops the computed rows, so they are computed again when read
+func (c *CommonTable) reset() {
+	c.rows, c.computed, c.working = nil, false, nil
+}
+
+func (c *CommonTable) values(tx *storage.Tx) ([][]any, error) {
+	if c.iterating {
+		return c.working, nil
+	}
+
+	if c.computed {
+		return c.rows, nil
+	}
+
+	result, err := c.Query.Run(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := c.widenedValues(result.Rows)
+	if c.Recursive != nil {
+		if rows, err = c.iterate(tx, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	c.rows, c.computed = rows, true
+	return rows, nil
+}
+
+// iterate Runs the recursive query until it adds no rows, starting from the rows of the
+// query
+func (c *CommonTable) iterate(tx *storage.Tx, rows [][]any) ([][]any, error) {
+	seen := make(map[string]struct{})
+	if !c.All {
+		rows = unseenValues(rows, seen)
+	}
+
+	c.iterating = true
+	defer func() { c.iterating, c.working = false, nil }()
+
+	working := rows
+	for iteration := 0; len(working) > 0; iteration++ {
+		if iteration >= c.MaxIterations {
+			return nil, fmt.Errorf("%w: %s after %d iterations", ErrRecursionLimitExceeded, c.Name, c.MaxIterations)
+		}
+
+		c.working = working
+		result, err := c.Recursive.Run(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		added := c.widenedValues(result.Rows)
+		if !c.All {
+			added = unseenValues(added, seen)
+		}
+
+		rows = append(rows, added...)
+		working = added
+	}
+
+	return rows, nil
+}
+
+// widenedValues Converts the integers of the FLOAT columns of the common table to floats
+func (c *CommonTable) widenedValues(rows [][]any) [][]any {
+	columns := make([]ResultColumn, 0, len(c.Columns))
+	for _, column := range c.Columns {
+		columns = append(columns, ResultColumn{Name: column.Name, DataType: column.DataType})
+	}
+
+	return widenValues(rows, columns)
+}
+
+// scan Calls fn with each row of the common table for which the where condition is true
+func (c *CommonTable) scan(tx *storage.Tx, where Expression, fn func(row dml.Row) error) error {
+	rows, err := c.values(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, values := range rows {
+		row := dml.Row{Table: c.Name, Columns: make([]dml.Column, 0, len(values))}
+		for i, value := range values {
+			row.Columns = append(row.Columns, dml.Column{Definition: c.Columns[i], Value: value})
+		}
+
+		matches, err := Matches(where, row)
+		if err != nil {
+			return err
+		}
+
+		if !matches {
+			continue
+		}
+
+		if err := fn(row); err != nil {
+			if errors.Is(err, storage.ErrStopScan) {
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unseenValues Drops the rows equal to a seen row or to a row before them, marking the
+// rows kept as seen
+func unseenValues(rows [][]any, seen map[string]struct{}) [][]any {
+	kept := rows[:0:0]
+	for _, values := range rows {
+		key := encodingutils.EncodeOrderedKey(values...)
+		if _, isSeen := seen[key]; isSeen {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		kept = append(kept, values)
+	}
+
+	return kept
+}
+
+// resetCommonTables Drops the rows computed by a previous run of the common tables
+func resetCommonTables(with []*CommonTable) {
+	for _, commonTable := range with {
+		commonTable.reset()
+	}
+}
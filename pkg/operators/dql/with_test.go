@@ -0,0 +1,86 @@
+package dql
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+)
+
+func TestCommonTable(t *testing.T) {
+	column := ddl.Column{Name: "X", DataType: ddl.ColumnDataTypeInteger}
+	resultColumn := ResultColumn{Name: column.Name, DataType: column.DataType}
+
+	// newCommonTable Returns a common table starting with the values, whose recursive query
+	// adds one to the values of its previous run that are lower than the maximum
+	newCommonTable := func(values []any, maximum int64, all bool, maxIterations int) *CommonTable {
+		query := valuesQuery{columns: []ResultColumn{resultColumn}}
+		for _, value := range values {
+			query.rows = append(query.rows, []any{value})
+		}
+
+		table := &CommonTable{
+			Name:          "N",
+			Columns:       []ddl.Column{column},
+			Query:         query,
+			All:           all,
+			MaxIterations: maxIterations,
+		}
+
+		table.Recursive = SelectQuery{
+			Source: table,
+			Where:  Comparison{Comparison: FilterComparisonLess, Left: ColumnRef{Name: "X"}, Right: Literal{Value: maximum}},
+			Projections: []Projection{{
+				Column:     resultColumn,
+				Expression: Arithmetic{Operator: ArithmeticOperatorAdd, Left: ColumnRef{Name: "X"}, Right: Literal{Value: int64(1)}},
+			}},
+			Limit: -1,
+		}
+
+		return table
+	}
+
+	testCases := []struct {
+		name          string
+		table         *CommonTable
+		expectedRows  [][]any
+		expectedError error
+	}{
+		{
+			name:         "should add the rows of each run of the recursive query",
+			table:        newCommonTable([]any{int64(1)}, 3, true, 10),
+			expectedRows: [][]any{{int64(1)}, {int64(2)}, {int64(3)}},
+		},
+		{
+			name:         "should keep equal rows with All",
+			table:        newCommonTable([]any{int64(1), int64(2)}, 3, true, 10),
+			expectedRows: [][]any{{int64(1)}, {int64(2)}, {int64(2)}, {int64(3)}, {int64(3)}},
+		},
+		{
+			name:         "should drop rows already added without All",
+			table:        newCommonTable([]any{int64(1), int64(2), int64(1)}, 3, false, 10),
+			expectedRows: [][]any{{int64(1)}, {int64(2)}, {int64(3)}},
+		},
+		{
+			name:          "should return ErrRecursionLimitExceeded if the recursive query adds rows after the limit",
+			table:         newCommonTable([]any{int64(1)}, 5, true, 3),
+			expectedError: ErrRecursionLimitExceeded,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rows, err := testCase.table.values(nil)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
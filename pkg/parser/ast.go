@@ -1,14 +1,81 @@
 package parser
 
+import "strings"
+
 const (
-	TypeInsertOperation = "INSERT"
-	TypeDatabase        = "DATABASE"
-	TypeTableDefinition = "TABLE_DEFINITION"
-	TypeTable           = "TABLE"
-	TypeColumnList      = "COLUMN_LIST"
-	TypeColumn          = "COLUMN"
-	TypeValueList       = "VALUE_LIST"
-	TypeValue           = "VALUE"
+	TypeCreateDatabaseOperation = "CREATE_DATABASE"
+	TypeDropDatabaseOperation   = "DROP_DATABASE"
+	TypeCreateTableOperation    = "CREATE_TABLE"
+	TypeDropTableOperation      = "DROP_TABLE"
+	TypeCreateIndexOperation    = "CREATE_INDEX"
+	TypeDropIndexOperation      = "DROP_INDEX"
+	TypeInsertOperation         = "INSERT"
+	TypeUpdateOperation         = "UPDATE"
+	TypeDeleteOperation         = "DELETE"
+	TypeSelectOperation         = "SELECT"
+	TypeBeginOperation          = "BEGIN"
+	TypeCommitOperation         = "COMMIT"
+	TypeRollbackOperation       = "ROLLBACK"
+
+	TypeOrReplace   = "OR_REPLACE"
+	TypeIfNotExists = "IF_NOT_EXISTS"
+	TypeUnique      = "UNIQUE"
+
+	TypeDatabase             = "DATABASE"
+	TypeTableDefinition      = "TABLE_DEFINITION"
+	TypeTable                = "TABLE"
+	TypeIndex                = "INDEX"
+	TypeColumnDefinitionList = "COLUMN_DEFINITION_LIST"
+	TypeColumnDefinition     = "COLUMN_DEFINITION"
+	TypeDataType             = "DATA_TYPE"
+	TypeConstraint           = "CONSTRAINT"
+	TypeConstraintName       = "CONSTRAINT_NAME"
+	TypeColumnList           = "COLUMN_LIST"
+	TypeColumn               = "COLUMN"
+	TypeValueList            = "VALUE_LIST"
+	TypeValue                = "VALUE"
+	TypeAssignmentList       = "ASSIGNMENT_LIST"
+	TypeAssignment           = "ASSIGNMENT"
+	TypeSelectList           = "SELECT_LIST"
+	TypeAllColumns           = "ALL_COLUMNS"
+	TypeAlias                = "ALIAS"
+	TypeFrom                 = "FROM"
+	TypeWhere                = "WHERE"
+	TypeSetOperation         = "SET_OPERATION"
+	TypeJoin                 = "JOIN"
+	TypeOn                   = "ON"
+	TypeGroupBy              = "GROUP_BY"
+	TypeHaving               = "HAVING"
+	TypeOrderBy              = "ORDER_BY"
+	TypeOrderItem            = "ORDER_ITEM"
+	TypeNulls                = "NULLS"
+	TypeLimit                = "LIMIT"
+	TypeOffset               = "OFFSET"
+	TypeComparison           = "COMPARISON"
+	TypeAnd                  = "AND"
+	TypeOr                   = "OR"
+	TypeNot                  = "NOT"
+	TypeArithmetic           = "ARITHMETIC"
+	TypeNegation             = "NEGATION"
+	TypeFunction             = "FUNCTION"
+	TypeDistinct             = "DISTINCT"
+	TypeSubquery             = "SUBQUERY"
+	TypeExists               = "EXISTS"
+	TypeWith                 = "WITH"
+	TypeCommonTable          = "COMMON_TABLE"
+	TypeOver                 = "OVER"
+	TypePartitionBy          = "PARTITION_BY"
+	TypeFrame                = "FRAME"
+	TypeFrameBound           = "FRAME_BOUND"
+	TypeCase                 = "CASE"
+	TypeWhen                 = "WHEN"
+	TypeElse                 = "ELSE"
+	TypeCast                 = "CAST"
+
+	TypeStringLiteral  = "STRING_LITERAL"
+	TypeIntegerLiteral = "INTEGER_LITERAL"
+	TypeFloatLiteral   = "FLOAT_LITERAL"
+	TypeNullLiteral    = "NULL_LITERAL"
 )
 
 type AST struct {
@@ -18,48 +85,80 @@ type AST struct {
 	Children []*AST
 }
 
-//
-//var root = &AST{
-//	Type: TypeInsertOperation,
-//	Children: []*AST{
-//		{
-//			Type: TypeTableDefinition,
-//			Children: []*AST{
-//				{
-//					Type:  TypeDatabase,
-//					Value: "database",
-//				},
-//				{
-//					Type:  TypeTable,
-//					Value: "table",
-//				},
-//				{
-//					Type: TypeColumnList,
-//					Children: []*AST{
-//						{
-//							Type:  TypeColumn,
-//							Value: "id",
-//						},
-//						{
-//							Type:  TypeColumn,
-//							Value: "value",
-//						},
-//					},
-//				},
-//				{
-//					Type: TypeValueList,
-//					Children: []*AST{
-//						{
-//							Type:  TypeValue,
-//							Value: "1",
-//						},
-//						{
-//							Type:  TypeValue,
-//							Value: "'test'",
-//						},
-//					},
-//				},
-//			},
-//		},
-//	},
-//}
+func newAST(nodeType, value string, children ...*AST) *AST {
+	node := &AST{
+		Type:  nodeType,
+		Value: value,
+	}
+
+	for _, child := range children {
+		node.AppendChild(child)
+	}
+
+	return node
+}
+
+func (a *AST) AppendChild(child *AST) {
+	if child == nil {
+		return
+	}
+
+	child.Parent = a
+	a.Children = append(a.Children, child)
+}
+
+func (a *AST) FirstChildOfType(nodeType string) *AST {
+	for _, child := range a.Children {
+		if child.Type == nodeType {
+			return child
+		}
+	}
+
+	return nil
+}
+
+func (a *AST) ChildrenOfType(nodeType string) []*AST {
+	var children []*AST
+	for _, child := range a.Children {
+		if child.Type == nodeType {
+			children = append(children, child)
+		}
+	}
+
+	return children
+}
+
+func (a *AST) HasChildOfType(nodeType string) bool {
+	return a.FirstChildOfType(nodeType) != nil
+}
+
+func (a *AST) writeTo(builder *strings.Builder) {
+	builder.WriteString(a.Type)
+
+	if a.Value != "" {
+		builder.WriteString("[")
+		builder.WriteString(a.Value)
+		builder.WriteString("]")
+	}
+
+	if len(a.Children) == 0 {
+		return
+	}
+
+	builder.WriteString("(")
+	for i, child := range a.Children {
+		if i > 0 {
+			builder.WriteString(" ")
+		}
+
+		child.writeTo(builder)
+	}
+	builder.WriteString(")")
+}
+
+func (a *AST) String() string {
+	builder := strings.Builder{}
+	a.writeTo(&builder)
+
+	return builder.String()
+}
@@ -0,0 +1,321 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+)
+
+type TokenType string
+
+type Token struct {
+	Type  TokenType
+	Value string
+	// Text The word as written for non reserved keywords, which can also be names
+	Text   string
+	Line   int
+	Column int
+}
+
+const (
+	TokenKeyword    TokenType = "KEYWORD"
+	TokenIdentifier TokenType = "IDENTIFIER"
+	TokenString     TokenType = "STRING"
+	TokenInteger    TokenType = "INTEGER"
+	TokenFloat      TokenType = "FLOAT"
+	TokenSymbol     TokenType = "SYMBOL"
+	TokenEOF        TokenType = "EOF"
+)
+
+var keywords = map[string]struct{}{
+	"ALL":         {},
+	"AND":         {},
+	"AS":          {},
+	"ASC":         {},
+	"BEGIN":       {},
+	"BETWEEN":     {},
+	"BY":          {},
+	"CASE":        {},
+	"CAST":        {},
+	"COMMIT":      {},
+	"CONSTRAINT":  {},
+	"CREATE":      {},
+	"CROSS":       {},
+	"CURRENT":     {},
+	"DATABASE":    {},
+	"DELETE":      {},
+	"DESC":        {},
+	"DISTINCT":    {},
+	"DROP":        {},
+	"ELSE":        {},
+	"END":         {},
+	"EXCEPT":      {},
+	"EXISTS":      {},
+	"FIRST":       {},
+	"FOLLOWING":   {},
+	"FROM":        {},
+	"GROUP":       {},
+	"HAVING":      {},
+	"IF":          {},
+	"ILIKE":       {},
+	"IN":          {},
+	"INDEX":       {},
+	"INNER":       {},
+	"INSERT":      {},
+	"INTERSECT":   {},
+	"INTO":        {},
+	"IS":          {},
+	"JOIN":        {},
+	"KEY":         {},
+	"LAST":        {},
+	"LEFT":        {},
+	"LIKE":        {},
+	"LIMIT":       {},
+	"NOT":         {},
+	"NULL":        {},
+	"NULLS":       {},
+	"OFFSET":      {},
+	"ON":          {},
+	"OR":          {},
+	"ORDER":       {},
+	"OUTER":       {},
+	"OVER":        {},
+	"PARTITION":   {},
+	"PRECEDING":   {},
+	"PRIMARY":     {},
+	"RECURSIVE":   {},
+	"REPLACE":     {},
+	"RIGHT":       {},
+	"ROLLBACK":    {},
+	"ROW":         {},
+	"ROWS":        {},
+	"SELECT":      {},
+	"SET":         {},
+	"TABLE":       {},
+	"THEN":        {},
+	"TRANSACTION": {},
+	"UNBOUNDED":   {},
+	"UNION":       {},
+	"UNIQUE":      {},
+	"UPDATE":      {},
+	"VALUES":      {},
+	"WHEN":        {},
+	"WHERE":       {},
+	"WITH":        {},
+}
+
+// nonReservedKeywords Keywords that only have a meaning in some clauses, so they can also
+// name databases, tables, columns and aliases, as a column named key
+var nonReservedKeywords = map[string]struct{}{
+	"CURRENT": {},
+	"END":     {},
+	"FIRST":   {},
+	"KEY":     {},
+	"LAST":    {},
+	"LEFT":    {},
+	"RIGHT":   {},
+	"ROW":     {},
+}
+
+var symbols = []string{
+	"<>", "<=", ">=", "!=", "||",
+	"(", ")", ",", ".", ";", "*", "=", "<", ">", "+", "-", "/", "%",
+}
+
+func IsKeyword(word string) bool {
+	_, exists := keywords[strings.ToUpper(word)]
+	return exists
+}
+
+// IsReservedKeyword Checks if the word is a keyword that can not be used as a name
+func IsReservedKeyword(word string) bool {
+	_, isNonReserved := nonReservedKeywords[strings.ToUpper(word)]
+	return IsKeyword(word) && !isNonReserved
+}
+
+type lexer struct {
+	input  []rune
+	pos    int
+	line   int
+	column int
+}
+
+func (l *lexer) peek(offset int) rune {
+	if l.pos+offset >= len(l.input) {
+		return 0
+	}
+
+	return l.input[l.pos+offset]
+}
+
+func (l *lexer) advance() rune {
+	r := l.input[l.pos]
+	l.pos++
+
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+
+	return r
+}
+
+func (l *lexer) skipWhitespaceAndComments() {
+	for l.pos < len(l.input) {
+		r := l.peek(0)
+
+		if unicode.IsSpace(r) {
+			l.advance()
+			continue
+		}
+
+		if r == '-' && l.peek(1) == '-' {
+			for l.pos < len(l.input) && l.peek(0) != '\n' {
+				l.advance()
+			}
+			continue
+		}
+
+		return
+	}
+}
+
+func (l *lexer) readWord(line, column int) Token {
+	builder := strings.Builder{}
+	for l.pos < len(l.input) {
+		r := l.peek(0)
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			break
+		}
+
+		builder.WriteRune(l.advance())
+	}
+
+	word := builder.String()
+	if IsReservedKeyword(word) {
+		return Token{Type: TokenKeyword, Value: strings.ToUpper(word), Line: line, Column: column}
+	}
+
+	if IsKeyword(word) {
+		return Token{Type: TokenKeyword, Value: strings.ToUpper(word), Text: word, Line: line, Column: column}
+	}
+
+	return Token{Type: TokenIdentifier, Value: word, Line: line, Column: column}
+}
+
+func (l *lexer) readNumber(line, column int) (Token, error) {
+	builder := strings.Builder{}
+	tokenType := TokenInteger
+
+	for l.pos < len(l.input) {
+		r := l.peek(0)
+
+		if r == '.' && tokenType == TokenInteger && unicode.IsDigit(l.peek(1)) {
+			tokenType = TokenFloat
+			builder.WriteRune(l.advance())
+			continue
+		}
+
+		if !unicode.IsDigit(r) {
+			break
+		}
+
+		builder.WriteRune(l.advance())
+	}
+
+	if r := l.peek(0); unicode.IsLetter(r) || r == '_' {
+		return Token{}, newSyntaxError(ErrInvalidCharacter, l.line, l.column, string(r))
+	}
+
+	return Token{Type: tokenType, Value: builder.String(), Line: line, Column: column}, nil
+}
+
+func (l *lexer) readQuoted(quote rune, tokenType TokenType, line, column int) (Token, error) {
+	l.advance()
+
+	builder := strings.Builder{}
+	for {
+		if l.pos >= len(l.input) {
+			return Token{}, newSyntaxError(ErrUnterminatedString, line, column, string(quote)+builder.String())
+		}
+
+		r := l.advance()
+		if r == quote {
+			// Doubled quotes are the SQL way of escaping a quote inside a quoted value
+			if l.peek(0) == quote {
+				builder.WriteRune(l.advance())
+				continue
+			}
+
+			break
+		}
+
+		builder.WriteRune(r)
+	}
+
+	return Token{Type: tokenType, Value: builder.String(), Line: line, Column: column}, nil
+}
+
+func (l *lexer) readSymbol(line, column int) (Token, error) {
+	for _, symbol := range symbols {
+		if !strings.HasPrefix(string(l.input[l.pos:]), symbol) {
+			continue
+		}
+
+		for range symbol {
+			l.advance()
+		}
+
+		return Token{Type: TokenSymbol, Value: symbol, Line: line, Column: column}, nil
+	}
+
+	return Token{}, newSyntaxError(ErrInvalidCharacter, line, column, string(l.peek(0)))
+}
+
+func (l *lexer) next() (Token, error) {
+	l.skipWhitespaceAndComments()
+
+	line, column := l.line, l.column
+	if l.pos >= len(l.input) {
+		return Token{Type: TokenEOF, Line: line, Column: column}, nil
+	}
+
+	r := l.peek(0)
+	switch {
+	case unicode.IsLetter(r) || r == '_':
+		return l.readWord(line, column), nil
+
+	case unicode.IsDigit(r):
+		return l.readNumber(line, column)
+
+	case r == '\'':
+		return l.readQuoted('\'', TokenString, line, column)
+
+	case r == '"':
+		return l.readQuoted('"', TokenIdentifier, line, column)
+	}
+
+	return l.readSymbol(line, column)
+}
+
+func Tokenize(query string) ([]Token, error) {
+	l := &lexer{
+		input:  []rune(query),
+		line:   1,
+		column: 1,
+	}
+
+	var tokens []Token
+	for {
+		token, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+		if token.Type == TokenEOF {
+			return tokens, nil
+		}
+	}
+}
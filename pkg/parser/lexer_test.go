@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	testCases := []struct {
+		name           string
+		query          string
+		expectedTokens []Token
+		expectedError  error
+	}{
+		{
+			name:  "should tokenize keywords case insensitively",
+			query: "select * From",
+			expectedTokens: []Token{
+				{Type: TokenKeyword, Value: "SELECT", Line: 1, Column: 1},
+				{Type: TokenSymbol, Value: "*", Line: 1, Column: 8},
+				{Type: TokenKeyword, Value: "FROM", Line: 1, Column: 10},
+				{Type: TokenEOF, Line: 1, Column: 14},
+			},
+		},
+		{
+			name:  "should tokenize identifiers, numbers and strings",
+			query: "foo.bar 12 1.5 'it''s'",
+			expectedTokens: []Token{
+				{Type: TokenIdentifier, Value: "foo", Line: 1, Column: 1},
+				{Type: TokenSymbol, Value: ".", Line: 1, Column: 4},
+				{Type: TokenIdentifier, Value: "bar", Line: 1, Column: 5},
+				{Type: TokenInteger, Value: "12", Line: 1, Column: 9},
+				{Type: TokenFloat, Value: "1.5", Line: 1, Column: 12},
+				{Type: TokenString, Value: "it's", Line: 1, Column: 16},
+				{Type: TokenEOF, Line: 1, Column: 23},
+			},
+		},
+		{
+			name:  "should keep the text of non reserved keywords",
+			query: "Key",
+			expectedTokens: []Token{
+				{Type: TokenKeyword, Value: "KEY", Text: "Key", Line: 1, Column: 1},
+				{Type: TokenEOF, Line: 1, Column: 4},
+			},
+		},
+		{
+			name:  "should track lines and skip comments",
+			query: "-- comment\n  \"select\" <>",
+			expectedTokens: []Token{
+				{Type: TokenIdentifier, Value: "select", Line: 2, Column: 3},
+				{Type: TokenSymbol, Value: "<>", Line: 2, Column: 12},
+				{Type: TokenEOF, Line: 2, Column: 14},
+			},
+		},
+		{
+			name:          "should return ErrUnterminatedString for unclosed strings",
+			query:         "'foo",
+			expectedError: ErrUnterminatedString,
+		},
+		{
+			name:          "should return ErrInvalidCharacter for unknown symbols",
+			query:         "SELECT ?",
+			expectedError: ErrInvalidCharacter,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			tokens, err := Tokenize(testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if !slices.Equal(tokens, testCase.expectedTokens) {
+				t.Errorf("expected tokens %v, got %v", testCase.expectedTokens, tokens)
+				return
+			}
+		})
+	}
+}
@@ -1,6 +1,1633 @@
 package parser
 
-func ParseQueryIntoAST(query string) (*AST, error) {
-	// TODO
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnexpectedToken    = errors.New("unexpected token")
+	ErrUnterminatedString = errors.New("unterminated string")
+	ErrInvalidCharacter   = errors.New("invalid character")
+)
+
+type SyntaxError struct {
+	Err      error
+	Line     int
+	Column   int
+	Token    string
+	Expected string
+}
+
+func newSyntaxError(err error, line, column int, token string) *SyntaxError {
+	return &SyntaxError{
+		Err:    err,
+		Line:   line,
+		Column: column,
+		Token:  token,
+	}
+}
+
+func (e *SyntaxError) Error() string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("syntax error at line %d, column %d: %s %q", e.Line, e.Column, e.Err.Error(), e.Token))
+
+	if e.Expected != "" {
+		builder.WriteString(", expected ")
+		builder.WriteString(e.Expected)
+	}
+
+	return builder.String()
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+func (p *parser) current() Token {
+	return p.tokens[p.pos]
+}
+
+// peek Returns the token after the current one, which is the EOF token at the end of the
+// query
+func (p *parser) peek() Token {
+	if p.pos+1 >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+
+	return p.tokens[p.pos+1]
+}
+
+func (p *parser) advance() Token {
+	token := p.tokens[p.pos]
+	if token.Type != TokenEOF {
+		p.pos++
+	}
+
+	return token
+}
+
+func (p *parser) unexpected(expected string) error {
+	token := p.current()
+
+	value := token.Value
+	if token.Type == TokenEOF {
+		value = string(TokenEOF)
+	}
+
+	err := newSyntaxError(ErrUnexpectedToken, token.Line, token.Column, value)
+	err.Expected = expected
+
+	return err
+}
+
+func (p *parser) isKeyword(keywords ...string) bool {
+	token := p.current()
+	if token.Type != TokenKeyword {
+		return false
+	}
+
+	for _, keyword := range keywords {
+		if token.Value == keyword {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *parser) isSymbol(symbols ...string) bool {
+	token := p.current()
+	if token.Type != TokenSymbol {
+		return false
+	}
+
+	for _, symbol := range symbols {
+		if token.Value == symbol {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if !p.isKeyword(keyword) {
+		return false
+	}
+
+	p.advance()
+	return true
+}
+
+func (p *parser) acceptSymbol(symbol string) bool {
+	if !p.isSymbol(symbol) {
+		return false
+	}
+
+	p.advance()
+	return true
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.unexpected(keyword)
+	}
+
+	return nil
+}
+
+func (p *parser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.unexpected(fmt.Sprintf("%q", symbol))
+	}
+
+	return nil
+}
+
+// isIdentifier Checks if the current token is a name, which can be a non reserved keyword
+func (p *parser) isIdentifier() bool {
+	token := p.current()
+	return token.Type == TokenIdentifier || (token.Type == TokenKeyword && !IsReservedKeyword(token.Value))
+}
+
+func (p *parser) expectIdentifier(expected string) (string, error) {
+	if !p.isIdentifier() {
+		return "", p.unexpected(expected)
+	}
+
+	token := p.advance()
+	if token.Type == TokenKeyword {
+		return token.Text, nil
+	}
+
+	return token.Value, nil
+}
+
+func (p *parser) parseStatement() (*AST, error) {
+	switch {
+	case p.isKeyword("CREATE"):
+		return p.parseCreate()
+
+	case p.isKeyword("DROP"):
+		return p.parseDrop()
+
+	case p.isKeyword("INSERT"):
+		return p.parseInsert()
+
+	case p.isKeyword("UPDATE"):
+		return p.parseUpdate()
+
+	case p.isKeyword("DELETE"):
+		return p.parseDelete()
+
+	case p.isKeyword("SELECT", "WITH"):
+		return p.parseQuery()
+
+	case p.isKeyword("BEGIN"), p.isKeyword("COMMIT"), p.isKeyword("ROLLBACK"):
+		return p.parseTransactionControl()
+	}
+
+	return nil, p.unexpected("statement")
+}
+
+func (p *parser) parseTransactionControl() (*AST, error) {
+	var statementType string
+	switch p.advance().Value {
+	case "BEGIN":
+		statementType = TypeBeginOperation
+
+	case "COMMIT":
+		statementType = TypeCommitOperation
+
+	default:
+		statementType = TypeRollbackOperation
+	}
+
+	p.acceptKeyword("TRANSACTION")
+
+	return newAST(statementType, ""), nil
+}
+
+func (p *parser) parseIfNotExists() (*AST, error) {
+	if !p.acceptKeyword("IF") {
+		return nil, nil
+	}
+
+	if err := p.expectKeyword("NOT"); err != nil {
+		return nil, err
+	}
+
+	if err := p.expectKeyword("EXISTS"); err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeIfNotExists, ""), nil
+}
+
+func (p *parser) parseQualifiedTableName(node *AST) error {
+	database, err := p.expectIdentifier("database name")
+	if err != nil {
+		return err
+	}
+
+	if err := p.expectSymbol("."); err != nil {
+		return err
+	}
+
+	table, err := p.expectIdentifier("table name")
+	if err != nil {
+		return err
+	}
+
+	node.AppendChild(newAST(TypeDatabase, database))
+	node.AppendChild(newAST(TypeTable, table))
+
+	return nil
+}
+
+func (p *parser) parseCreate() (*AST, error) {
+	p.advance()
+
+	var orReplace *AST
+	if p.acceptKeyword("OR") {
+		if err := p.expectKeyword("REPLACE"); err != nil {
+			return nil, err
+		}
+
+		orReplace = newAST(TypeOrReplace, "")
+	}
+
+	switch {
+	case p.acceptKeyword("DATABASE"):
+		ifNotExists, err := p.parseIfNotExists()
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := p.expectIdentifier("database name")
+		if err != nil {
+			return nil, err
+		}
+
+		return newAST(TypeCreateDatabaseOperation, "", newAST(TypeDatabase, name), orReplace, ifNotExists), nil
+
+	case p.acceptKeyword("TABLE"):
+		ifNotExists, err := p.parseIfNotExists()
+		if err != nil {
+			return nil, err
+		}
+
+		tableDefinition := newAST(TypeTableDefinition, "")
+		if err := p.parseQualifiedTableName(tableDefinition); err != nil {
+			return nil, err
+		}
+
+		columnDefinitions, err := p.parseColumnDefinitionList()
+		if err != nil {
+			return nil, err
+		}
+		tableDefinition.AppendChild(columnDefinitions)
+
+		return newAST(TypeCreateTableOperation, "", tableDefinition, orReplace, ifNotExists), nil
+
+	case orReplace == nil && p.isKeyword("UNIQUE", "INDEX"):
+		return p.parseCreateIndex()
+	}
+
+	if orReplace != nil {
+		return nil, p.unexpected("DATABASE or TABLE")
+	}
+
+	return nil, p.unexpected("DATABASE, TABLE or INDEX")
+}
+
+func (p *parser) parseCreateIndex() (*AST, error) {
+	var unique *AST
+	if p.acceptKeyword("UNIQUE") {
+		unique = newAST(TypeUnique, "")
+	}
+
+	if err := p.expectKeyword("INDEX"); err != nil {
+		return nil, err
+	}
+
+	name, err := p.expectIdentifier("index name")
+	if err != nil {
+		return nil, err
+	}
+
+	tableDefinition, err := p.parseIndexTable()
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := p.parseColumnList()
+	if err != nil {
+		return nil, err
+	}
+	tableDefinition.AppendChild(columns)
+
+	return newAST(TypeCreateIndexOperation, "", newAST(TypeIndex, name), tableDefinition, unique), nil
+}
+
+func (p *parser) parseIndexTable() (*AST, error) {
+	if err := p.expectKeyword("ON"); err != nil {
+		return nil, err
+	}
+
+	tableDefinition := newAST(TypeTableDefinition, "")
+	if err := p.parseQualifiedTableName(tableDefinition); err != nil {
+		return nil, err
+	}
+
+	return tableDefinition, nil
+}
+
+func (p *parser) parseColumnDefinitionList() (*AST, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	list := newAST(TypeColumnDefinitionList, "")
+	for {
+		columnDefinition, err := p.parseColumnDefinition()
+		if err != nil {
+			return nil, err
+		}
+		list.AppendChild(columnDefinition)
+
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (p *parser) parseColumnDefinition() (*AST, error) {
+	name, err := p.expectIdentifier("column name")
+	if err != nil {
+		return nil, err
+	}
+
+	dataType, err := p.expectIdentifier("column type")
+	if err != nil {
+		return nil, err
+	}
+
+	columnDefinition := newAST(TypeColumnDefinition, name, newAST(TypeDataType, strings.ToUpper(dataType)))
+	for {
+		constraint, err := p.parseConstraint()
+		if err != nil {
+			return nil, err
+		}
+
+		if constraint == nil {
+			return columnDefinition, nil
+		}
+
+		columnDefinition.AppendChild(constraint)
+	}
+}
+
+func (p *parser) parseConstraint() (*AST, error) {
+	var constraintName *AST
+	if p.acceptKeyword("CONSTRAINT") {
+		name, err := p.expectIdentifier("constraint name")
+		if err != nil {
+			return nil, err
+		}
+
+		constraintName = newAST(TypeConstraintName, name)
+	}
+
+	switch {
+	case p.acceptKeyword("PRIMARY"):
+		if err := p.expectKeyword("KEY"); err != nil {
+			return nil, err
+		}
+
+		return newAST(TypeConstraint, "PRIMARY_KEY", constraintName), nil
+
+	case p.acceptKeyword("UNIQUE"):
+		return newAST(TypeConstraint, "UNIQUE", constraintName), nil
+
+	case p.acceptKeyword("NOT"):
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+
+		return newAST(TypeConstraint, "NOT_NULL", constraintName), nil
+	}
+
+	if constraintName != nil {
+		return nil, p.unexpected("PRIMARY KEY, UNIQUE or NOT NULL")
+	}
+
 	return nil, nil
 }
+
+func (p *parser) parseDrop() (*AST, error) {
+	p.advance()
+
+	switch {
+	case p.acceptKeyword("DATABASE"):
+		name, err := p.expectIdentifier("database name")
+		if err != nil {
+			return nil, err
+		}
+
+		return newAST(TypeDropDatabaseOperation, "", newAST(TypeDatabase, name)), nil
+
+	case p.acceptKeyword("TABLE"):
+		tableDefinition := newAST(TypeTableDefinition, "")
+		if err := p.parseQualifiedTableName(tableDefinition); err != nil {
+			return nil, err
+		}
+
+		return newAST(TypeDropTableOperation, "", tableDefinition), nil
+
+	case p.acceptKeyword("INDEX"):
+		name, err := p.expectIdentifier("index name")
+		if err != nil {
+			return nil, err
+		}
+
+		tableDefinition, err := p.parseIndexTable()
+		if err != nil {
+			return nil, err
+		}
+
+		return newAST(TypeDropIndexOperation, "", newAST(TypeIndex, name), tableDefinition), nil
+	}
+
+	return nil, p.unexpected("DATABASE, TABLE or INDEX")
+}
+
+func (p *parser) parseInsert() (*AST, error) {
+	p.advance()
+
+	if err := p.expectKeyword("INTO"); err != nil {
+		return nil, err
+	}
+
+	tableDefinition := newAST(TypeTableDefinition, "")
+	if err := p.parseQualifiedTableName(tableDefinition); err != nil {
+		return nil, err
+	}
+
+	columns, err := p.parseColumnList()
+	if err != nil {
+		return nil, err
+	}
+	tableDefinition.AppendChild(columns)
+
+	if err := p.expectKeyword("VALUES"); err != nil {
+		return nil, err
+	}
+
+	values, err := p.parseValueList()
+	if err != nil {
+		return nil, err
+	}
+	tableDefinition.AppendChild(values)
+
+	return newAST(TypeInsertOperation, "", tableDefinition), nil
+}
+
+func (p *parser) parseColumnList() (*AST, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	list := newAST(TypeColumnList, "")
+	for {
+		name, err := p.expectIdentifier("column name")
+		if err != nil {
+			return nil, err
+		}
+		list.AppendChild(newAST(TypeColumn, name))
+
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (p *parser) parseValueList() (*AST, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	list := newAST(TypeValueList, "")
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list.AppendChild(value)
+
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (p *parser) parseValue() (*AST, error) {
+	literal, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeValue, "", literal), nil
+}
+
+func (p *parser) parseLiteral() (*AST, error) {
+	sign := ""
+	if p.acceptSymbol("-") {
+		sign = "-"
+	}
+
+	token := p.current()
+	switch {
+	case token.Type == TokenInteger:
+		p.advance()
+		return newAST(TypeIntegerLiteral, sign+token.Value), nil
+
+	case token.Type == TokenFloat:
+		p.advance()
+		return newAST(TypeFloatLiteral, sign+token.Value), nil
+
+	case token.Type == TokenString && sign == "":
+		p.advance()
+		return newAST(TypeStringLiteral, token.Value), nil
+
+	case p.isKeyword("NULL") && sign == "":
+		p.advance()
+		return newAST(TypeNullLiteral, token.Value), nil
+	}
+
+	return nil, p.unexpected("value")
+}
+
+func (p *parser) parseUpdate() (*AST, error) {
+	p.advance()
+
+	tableDefinition := newAST(TypeTableDefinition, "")
+	if err := p.parseQualifiedTableName(tableDefinition); err != nil {
+		return nil, err
+	}
+
+	if err := p.expectKeyword("SET"); err != nil {
+		return nil, err
+	}
+
+	assignments := newAST(TypeAssignmentList, "")
+	for {
+		name, err := p.expectIdentifier("column name")
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectSymbol("="); err != nil {
+			return nil, err
+		}
+
+		value, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		assignments.AppendChild(newAST(TypeAssignment, "", newAST(TypeColumn, name), value))
+
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	where, err := p.parseWhere()
+	if err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeUpdateOperation, "", tableDefinition, assignments, where), nil
+}
+
+func (p *parser) parseDelete() (*AST, error) {
+	p.advance()
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+
+	tableDefinition := newAST(TypeTableDefinition, "")
+	if err := p.parseQualifiedTableName(tableDefinition); err != nil {
+		return nil, err
+	}
+
+	where, err := p.parseWhere()
+	if err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeDeleteOperation, "", tableDefinition, where), nil
+}
+
+// parseQuery Parses a select or set operations between selects, where INTERSECT binds
+// tighter than UNION and EXCEPT, optionally preceded by a WITH clause. ORDER BY, LIMIT and
+// OFFSET after the last select apply to the whole query
+func (p *parser) parseQuery() (*AST, error) {
+	with, err := p.parseWith()
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := p.parseSetOperation(p.parseIntersection, "UNION", "EXCEPT")
+	if err != nil {
+		return nil, err
+	}
+
+	if with != nil {
+		with.Parent = query
+		query.Children = append([]*AST{with}, query.Children...)
+	}
+
+	orderBy, err := p.parseOrderBy()
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := p.parseCount("LIMIT", TypeLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := p.parseCount("OFFSET", TypeOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	query.AppendChild(orderBy)
+	query.AppendChild(limit)
+	query.AppendChild(offset)
+
+	return query, nil
+}
+
+// parseWith Parses WITH [RECURSIVE] name [(column, ...)] AS (query), ...
+func (p *parser) parseWith() (*AST, error) {
+	if !p.acceptKeyword("WITH") {
+		return nil, nil
+	}
+
+	with := newAST(TypeWith, "")
+	if p.acceptKeyword("RECURSIVE") {
+		with.Value = "RECURSIVE"
+	}
+
+	for {
+		name, err := p.expectIdentifier("common table name")
+		if err != nil {
+			return nil, err
+		}
+
+		commonTable := newAST(TypeCommonTable, name)
+		if p.isSymbol("(") {
+			columns, err := p.parseColumnList()
+			if err != nil {
+				return nil, err
+			}
+
+			commonTable.AppendChild(columns)
+		}
+
+		if err := p.expectKeyword("AS"); err != nil {
+			return nil, err
+		}
+
+		query, err := p.parseSubquery()
+		if err != nil {
+			return nil, err
+		}
+
+		commonTable.AppendChild(query)
+		with.AppendChild(commonTable)
+
+		if !p.acceptSymbol(",") {
+			return with, nil
+		}
+	}
+}
+
+func (p *parser) parseIntersection() (*AST, error) {
+	return p.parseSetOperation(p.parseSelect, "INTERSECT")
+}
+
+// parseSetOperation Parses left associative set operations with one of the operators,
+// optionally followed by ALL
+func (p *parser) parseSetOperation(parseOperand func() (*AST, error), operators ...string) (*AST, error) {
+	left, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		operator := ""
+		for _, candidate := range operators {
+			if p.acceptKeyword(candidate) {
+				operator = candidate
+				break
+			}
+		}
+
+		if operator == "" {
+			return left, nil
+		}
+
+		if p.acceptKeyword("ALL") {
+			operator += " ALL"
+		}
+
+		right, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		left = newAST(TypeSetOperation, operator, left, right)
+	}
+}
+
+func (p *parser) parseSelect() (*AST, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+
+	var distinct *AST
+	if p.acceptKeyword("DISTINCT") {
+		distinct = newAST(TypeDistinct, "")
+	}
+
+	selectList, err := p.parseSelectList()
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := p.parseFrom()
+	if err != nil {
+		return nil, err
+	}
+
+	where, err := p.parseWhere()
+	if err != nil {
+		return nil, err
+	}
+
+	groupBy, err := p.parseGroupBy()
+	if err != nil {
+		return nil, err
+	}
+
+	having, err := p.parseHaving()
+	if err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeSelectOperation, "", distinct, selectList, from, where, groupBy, having), nil
+}
+
+// parseFrom Parses the table of a select followed by the tables joined to it, as in
+// FROM db.a [AS] x [INNER|LEFT [OUTER]|RIGHT [OUTER]] JOIN db.b y ON <condition>, or
+// CROSS JOIN db.b, without a condition
+func (p *parser) parseFrom() (*AST, error) {
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+
+	tableDefinition, err := p.parseTableReference()
+	if err != nil {
+		return nil, err
+	}
+
+	from := newAST(TypeFrom, "", tableDefinition)
+	for {
+		var joinType string
+		switch {
+		case p.isKeyword("JOIN"):
+			joinType = "INNER"
+
+		case p.isKeyword("INNER", "CROSS"):
+			joinType = p.advance().Value
+
+		case p.isKeyword("LEFT", "RIGHT"):
+			joinType = p.advance().Value
+			p.acceptKeyword("OUTER")
+
+		default:
+			return from, nil
+		}
+
+		if err := p.expectKeyword("JOIN"); err != nil {
+			return nil, err
+		}
+
+		joined, err := p.parseTableReference()
+		if err != nil {
+			return nil, err
+		}
+
+		var on *AST
+		if joinType != "CROSS" {
+			if err := p.expectKeyword("ON"); err != nil {
+				return nil, err
+			}
+
+			condition, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+
+			on = newAST(TypeOn, "", condition)
+		}
+
+		from.AppendChild(newAST(TypeJoin, joinType, joined, on))
+	}
+}
+
+// parseTableReference Parses a qualified table name, or the name of a common table of a
+// WITH clause, optionally followed by an alias
+func (p *parser) parseTableReference() (*AST, error) {
+	tableDefinition := newAST(TypeTableDefinition, "")
+	if next := p.peek(); next.Type != TokenSymbol || next.Value != "." {
+		table, err := p.expectIdentifier("table name")
+		if err != nil {
+			return nil, err
+		}
+
+		tableDefinition.AppendChild(newAST(TypeTable, table))
+	} else if err := p.parseQualifiedTableName(tableDefinition); err != nil {
+		return nil, err
+	}
+
+	if p.acceptKeyword("AS") || p.current().Type == TokenIdentifier {
+		alias, err := p.expectIdentifier("alias")
+		if err != nil {
+			return nil, err
+		}
+
+		tableDefinition.AppendChild(newAST(TypeAlias, alias))
+	}
+
+	return tableDefinition, nil
+}
+
+func (p *parser) parseGroupBy() (*AST, error) {
+	if !p.acceptKeyword("GROUP") {
+		return nil, nil
+	}
+
+	if err := p.expectKeyword("BY"); err != nil {
+		return nil, err
+	}
+
+	groupBy := newAST(TypeGroupBy, "")
+	for {
+		column, err := p.parseColumnReference()
+		if err != nil {
+			return nil, err
+		}
+
+		groupBy.AppendChild(column)
+
+		if !p.acceptSymbol(",") {
+			return groupBy, nil
+		}
+	}
+}
+
+func (p *parser) parseHaving() (*AST, error) {
+	if !p.acceptKeyword("HAVING") {
+		return nil, nil
+	}
+
+	condition, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeHaving, "", condition), nil
+}
+
+// parseOrderBy Parses the ORDER BY items, each a column followed by its optional direction
+// and NULLS placement
+func (p *parser) parseOrderBy() (*AST, error) {
+	if !p.acceptKeyword("ORDER") {
+		return nil, nil
+	}
+
+	if err := p.expectKeyword("BY"); err != nil {
+		return nil, err
+	}
+
+	orderBy := newAST(TypeOrderBy, "")
+	for {
+		column, err := p.parseColumnReference()
+		if err != nil {
+			return nil, err
+		}
+
+		direction := "ASC"
+		if p.isKeyword("ASC", "DESC") {
+			direction = p.advance().Value
+		}
+
+		item := newAST(TypeOrderItem, direction, column)
+
+		if p.acceptKeyword("NULLS") {
+			if !p.isKeyword("FIRST", "LAST") {
+				return nil, p.unexpected("FIRST or LAST")
+			}
+
+			item.AppendChild(newAST(TypeNulls, p.advance().Value))
+		}
+
+		orderBy.AppendChild(item)
+
+		if !p.acceptSymbol(",") {
+			return orderBy, nil
+		}
+	}
+}
+
+// parseCount Parses the keyword followed by a non negative integer, like LIMIT 10
+func (p *parser) parseCount(keyword, nodeType string) (*AST, error) {
+	if !p.acceptKeyword(keyword) {
+		return nil, nil
+	}
+
+	if p.current().Type != TokenInteger {
+		return nil, p.unexpected("non negative integer")
+	}
+
+	return newAST(nodeType, p.advance().Value), nil
+}
+
+// parseSelectList Parses the items of a select list, which are * or expressions optionally
+// named by an alias
+func (p *parser) parseSelectList() (*AST, error) {
+	selectList := newAST(TypeSelectList, "")
+	for {
+		if p.acceptSymbol("*") {
+			selectList.AppendChild(newAST(TypeAllColumns, ""))
+		} else {
+			expression, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+
+			hasAlias := p.acceptKeyword("AS")
+			if hasAlias || p.current().Type == TokenIdentifier {
+				alias, err := p.expectIdentifier("alias")
+				if err != nil {
+					return nil, err
+				}
+
+				expression = newAST(TypeAlias, alias, expression)
+			}
+
+			selectList.AppendChild(expression)
+		}
+
+		if !p.acceptSymbol(",") {
+			return selectList, nil
+		}
+	}
+}
+
+func (p *parser) parseWhere() (*AST, error) {
+	if !p.acceptKeyword("WHERE") {
+		return nil, nil
+	}
+
+	condition, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeWhere, "", condition), nil
+}
+
+// parseExpression Parses a boolean expression, OR binds looser than AND, which binds
+// looser than NOT, and parentheses group expressions
+func (p *parser) parseExpression() (*AST, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (*AST, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = newAST(TypeOr, "", left, right)
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (*AST, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = newAST(TypeAnd, "", left, right)
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseNot() (*AST, error) {
+	if p.acceptKeyword("NOT") {
+		expression, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return newAST(TypeNot, "", expression), nil
+	}
+
+	return p.parsePredicate()
+}
+
+// parsePredicate Parses an operand optionally followed by a comparison. The negated
+// comparisons, NOT BETWEEN, NOT IN, NOT LIKE, NOT ILIKE and IS NOT NULL, are parsed as the
+// negation of the comparison
+func (p *parser) parsePredicate() (*AST, error) {
+	operand, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isSymbol("=", "<>", "!=", "<", "<=", ">", ">=") {
+		operator := p.advance().Value
+		if operator == "!=" {
+			operator = "<>"
+		}
+
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+
+		return newAST(TypeComparison, operator, operand, right), nil
+	}
+
+	if p.acceptKeyword("IS") {
+		negated := p.acceptKeyword("NOT")
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+
+		return negate(newAST(TypeComparison, "IS NULL", operand), negated), nil
+	}
+
+	negated := p.acceptKeyword("NOT")
+
+	switch {
+	case p.acceptKeyword("BETWEEN"):
+		low, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+
+		high, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+
+		return negate(newAST(TypeComparison, "BETWEEN", operand, low, high), negated), nil
+
+	case p.acceptKeyword("IN"):
+		var values *AST
+		if p.isSubquery() {
+			values, err = p.parseSubquery()
+		} else {
+			values, err = p.parseValueList()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return negate(newAST(TypeComparison, "IN", operand, values), negated), nil
+
+	case p.isKeyword("LIKE", "ILIKE"):
+		operator := p.advance().Value
+
+		pattern, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+
+		return negate(newAST(TypeComparison, operator, operand, pattern), negated), nil
+	}
+
+	if negated {
+		return nil, p.unexpected("BETWEEN, IN, LIKE or ILIKE")
+	}
+
+	return operand, nil
+}
+
+// parseAdditive Parses the +, - and || operations, which bind looser than *, / and %
+func (p *parser) parseAdditive() (*AST, error) {
+	return p.parseArithmetic(p.parseMultiplicative, "+", "-", "||")
+}
+
+func (p *parser) parseMultiplicative() (*AST, error) {
+	return p.parseArithmetic(p.parseUnary, "*", "/", "%")
+}
+
+// parseArithmetic Parses the left associative operations of the operators over the
+// operands parsed by parseOperand
+func (p *parser) parseArithmetic(parseOperand func() (*AST, error), operators ...string) (*AST, error) {
+	left, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isSymbol(operators...) {
+		operator := p.advance().Value
+
+		right, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		left = newAST(TypeArithmetic, operator, left, right)
+	}
+
+	return left, nil
+}
+
+// parseUnary Parses a negated operand, the negation of a number is parsed as a negative
+// literal
+func (p *parser) parseUnary() (*AST, error) {
+	if !p.isSymbol("-") {
+		return p.parseOperand()
+	}
+
+	if next := p.peek(); next.Type == TokenInteger || next.Type == TokenFloat {
+		return p.parseValue()
+	}
+
+	p.advance()
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeNegation, "", operand), nil
+}
+
+// parseOperand Parses a column, a value, a function call, a CASE or a parenthesised
+// expression
+func (p *parser) parseOperand() (*AST, error) {
+	if p.isSubquery() {
+		return p.parseSubquery()
+	}
+
+	if p.acceptKeyword("EXISTS") {
+		if !p.isSubquery() {
+			return nil, p.unexpected("subquery")
+		}
+
+		subquery, err := p.parseSubquery()
+		if err != nil {
+			return nil, err
+		}
+
+		return newAST(TypeExists, "", subquery), nil
+	}
+
+	if p.acceptSymbol("(") {
+		expression, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+
+		return expression, nil
+	}
+
+	if p.isKeyword("CASE") {
+		return p.parseCase()
+	}
+
+	if p.isKeyword("CAST") {
+		return p.parseCast()
+	}
+
+	if p.isFunctionCall() {
+		return p.parseFunction()
+	}
+
+	if p.isIdentifier() {
+		return p.parseColumnReference()
+	}
+
+	return p.parseValue()
+}
+
+// isFunctionCall Checks if the current token is a name followed by a parenthesis, which
+// can be the REPLACE keyword as it names a function too
+func (p *parser) isFunctionCall() bool {
+	if !p.isIdentifier() && !p.isKeyword("REPLACE") {
+		return false
+	}
+
+	next := p.peek()
+	return next.Type == TokenSymbol && next.Value == "("
+}
+
+// parseCase Parses CASE WHEN <condition> THEN <expression> ... [ELSE <expression>] END
+func (p *parser) parseCase() (*AST, error) {
+	p.advance()
+
+	node := newAST(TypeCase, "")
+	for p.acceptKeyword("WHEN") {
+		condition, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+
+		result, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		node.AppendChild(newAST(TypeWhen, "", condition, result))
+	}
+
+	if len(node.Children) == 0 {
+		return nil, p.unexpected("WHEN")
+	}
+
+	if p.acceptKeyword("ELSE") {
+		result, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		node.AppendChild(newAST(TypeElse, "", result))
+	}
+
+	return node, p.expectKeyword("END")
+}
+
+// parseCast Parses CAST(<expression> AS <type>) into a CAST node valued by the type
+func (p *parser) parseCast() (*AST, error) {
+	p.advance()
+
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	expression, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+
+	dataType, err := p.expectIdentifier("type")
+	if err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeCast, strings.ToUpper(dataType), expression), p.expectSymbol(")")
+}
+
+// isSubquery Checks if the current token opens a parenthesized query
+func (p *parser) isSubquery() bool {
+	return p.isSymbol("(") && p.peek().Type == TokenKeyword && p.peek().Value == "SELECT"
+}
+
+// parseSubquery Parses a parenthesized query, which can be sorted and paged
+func (p *parser) parseSubquery() (*AST, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	query, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeSubquery, "", query), nil
+}
+
+// parseColumnReference Parses a column name, optionally qualified by the name or the alias
+// of its table, as in alias.column
+func (p *parser) parseColumnReference() (*AST, error) {
+	name, err := p.expectIdentifier("column name")
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.acceptSymbol(".") {
+		return newAST(TypeColumn, name), nil
+	}
+
+	column, err := p.expectIdentifier("column name")
+	if err != nil {
+		return nil, err
+	}
+
+	return newAST(TypeColumn, column, newAST(TypeTable, name)), nil
+}
+
+// parseFunction Parses a function call, which is a window function if followed by OVER
+func (p *parser) parseFunction() (*AST, error) {
+	function, err := p.parseFunctionCall()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.acceptKeyword("OVER") {
+		return function, nil
+	}
+
+	return p.parseWindow(function)
+}
+
+// parseFunctionCall Parses the name and the arguments of a function, which are expressions,
+// a single * as in COUNT(*), or an expression prefixed by DISTINCT as in COUNT(DISTINCT name).
+// EXTRACT(<field> FROM <expression>) is parsed as EXTRACT('<field>', <expression>)
+func (p *parser) parseFunctionCall() (*AST, error) {
+	function := newAST(TypeFunction, strings.ToUpper(p.advance().Value))
+	p.advance()
+
+	if p.acceptSymbol(")") {
+		return function, nil
+	}
+
+	if function.Value == "EXTRACT" && p.isIdentifier() && p.peek().Value == "FROM" {
+		field := strings.ToUpper(p.advance().Value)
+		p.advance()
+
+		function.AppendChild(newAST(TypeValue, "", newAST(TypeStringLiteral, field)))
+	}
+
+	if p.acceptSymbol("*") {
+		function.AppendChild(newAST(TypeAllColumns, ""))
+		return function, p.expectSymbol(")")
+	}
+
+	if p.acceptKeyword("DISTINCT") {
+		argument, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		function.AppendChild(newAST(TypeDistinct, "", argument))
+		return function, p.expectSymbol(")")
+	}
+
+	for {
+		argument, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		function.AppendChild(argument)
+
+		if !p.acceptSymbol(",") {
+			return function, p.expectSymbol(")")
+		}
+	}
+}
+
+// parseWindow Parses the window of a window function, with the columns that partition its
+// rows, their order and the frame of rows aggregated for each row, as in
+// OVER (PARTITION BY a ORDER BY b ROWS BETWEEN 1 PRECEDING AND CURRENT ROW)
+func (p *parser) parseWindow(function *AST) (*AST, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	window := newAST(TypeOver, "", function)
+
+	if p.acceptKeyword("PARTITION") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+
+		partitionBy := newAST(TypePartitionBy, "")
+		for {
+			column, err := p.parseColumnReference()
+			if err != nil {
+				return nil, err
+			}
+
+			partitionBy.AppendChild(column)
+
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+
+		window.AppendChild(partitionBy)
+	}
+
+	orderBy, err := p.parseOrderBy()
+	if err != nil {
+		return nil, err
+	}
+
+	frame, err := p.parseFrame()
+	if err != nil {
+		return nil, err
+	}
+
+	window.AppendChild(orderBy)
+	window.AppendChild(frame)
+
+	return window, p.expectSymbol(")")
+}
+
+// parseFrame Parses a ROWS frame, a single bound being the start of a frame that ends at
+// the current row
+func (p *parser) parseFrame() (*AST, error) {
+	if !p.acceptKeyword("ROWS") {
+		return nil, nil
+	}
+
+	frame := newAST(TypeFrame, "ROWS")
+	if !p.acceptKeyword("BETWEEN") {
+		start, err := p.parseFrameBound()
+		if err != nil {
+			return nil, err
+		}
+
+		frame.AppendChild(start)
+		frame.AppendChild(newAST(TypeFrameBound, "CURRENT ROW"))
+		return frame, nil
+	}
+
+	start, err := p.parseFrameBound()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expectKeyword("AND"); err != nil {
+		return nil, err
+	}
+
+	end, err := p.parseFrameBound()
+	if err != nil {
+		return nil, err
+	}
+
+	frame.AppendChild(start)
+	frame.AppendChild(end)
+	return frame, nil
+}
+
+// parseFrameBound Parses UNBOUNDED PRECEDING, <count> PRECEDING, CURRENT ROW,
+// <count> FOLLOWING or UNBOUNDED FOLLOWING
+func (p *parser) parseFrameBound() (*AST, error) {
+	if p.acceptKeyword("CURRENT") {
+		return newAST(TypeFrameBound, "CURRENT ROW"), p.expectKeyword("ROW")
+	}
+
+	var count *AST
+	prefix := ""
+	switch {
+	case p.acceptKeyword("UNBOUNDED"):
+		prefix = "UNBOUNDED "
+
+	case p.current().Type == TokenInteger:
+		count = newAST(TypeIntegerLiteral, p.advance().Value)
+
+	default:
+		return nil, p.unexpected("frame bound")
+	}
+
+	if !p.isKeyword("PRECEDING", "FOLLOWING") {
+		return nil, p.unexpected("PRECEDING or FOLLOWING")
+	}
+
+	return newAST(TypeFrameBound, prefix+p.advance().Value, count), nil
+}
+
+func negate(node *AST, negated bool) *AST {
+	if !negated {
+		return node
+	}
+
+	return newAST(TypeNot, "", node)
+}
+
+func ParseQueryIntoAST(query string) (*AST, error) {
+	tokens, err := Tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	statement, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	p.acceptSymbol(";")
+	if p.current().Type != TokenEOF {
+		return nil, p.unexpected(string(TokenEOF))
+	}
+
+	return statement, nil
+}
+
+// ParseStatementsIntoAST Parses a script of statements separated by semicolons
+func ParseStatementsIntoAST(query string) ([]*AST, error) {
+	tokens, err := Tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	var statements []*AST
+	for p.current().Type != TokenEOF {
+		if p.acceptSymbol(";") {
+			continue
+		}
+
+		statement, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+
+		statements = append(statements, statement)
+
+		if p.current().Type != TokenEOF && !p.isSymbol(";") {
+			return nil, p.unexpected(";")
+		}
+	}
+
+	return statements, nil
+}
@@ -0,0 +1,489 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQueryIntoAST(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expectedAST   string
+		expectedError error
+	}{
+		{
+			name:        "should parse CREATE DATABASE",
+			query:       "CREATE DATABASE foo;",
+			expectedAST: "CREATE_DATABASE(DATABASE[foo])",
+		},
+		{
+			name:        "should parse CREATE OR REPLACE DATABASE IF NOT EXISTS",
+			query:       "create or replace database if not exists foo",
+			expectedAST: "CREATE_DATABASE(DATABASE[foo] OR_REPLACE IF_NOT_EXISTS)",
+		},
+		{
+			name:        "should parse DROP DATABASE",
+			query:       "DROP DATABASE foo",
+			expectedAST: "DROP_DATABASE(DATABASE[foo])",
+		},
+		{
+			name:  "should parse CREATE TABLE with constraints",
+			query: "CREATE TABLE foo.bar (id INTEGER PRIMARY KEY, name text CONSTRAINT name_uq UNIQUE, ts TIMESTAMP);",
+			expectedAST: "CREATE_TABLE(TABLE_DEFINITION(DATABASE[foo] TABLE[bar] COLUMN_DEFINITION_LIST(" +
+				"COLUMN_DEFINITION[id](DATA_TYPE[INTEGER] CONSTRAINT[PRIMARY_KEY]) " +
+				"COLUMN_DEFINITION[name](DATA_TYPE[TEXT] CONSTRAINT[UNIQUE](CONSTRAINT_NAME[name_uq])) " +
+				"COLUMN_DEFINITION[ts](DATA_TYPE[TIMESTAMP]))))",
+		},
+		{
+			name:  "should parse non reserved keywords as names",
+			query: "CREATE TABLE foo.bar (key TEXT PRIMARY KEY)",
+			expectedAST: "CREATE_TABLE(TABLE_DEFINITION(DATABASE[foo] TABLE[bar] COLUMN_DEFINITION_LIST(" +
+				"COLUMN_DEFINITION[key](DATA_TYPE[TEXT] CONSTRAINT[PRIMARY_KEY]))))",
+		},
+		{
+			name:        "should parse non reserved keywords as columns",
+			query:       "SELECT key AS Key FROM foo.bar WHERE bar.key = 'a'",
+			expectedAST: "SELECT(SELECT_LIST(ALIAS[Key](COLUMN[key])) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) WHERE(COMPARISON[=](COLUMN[key](TABLE[bar]) VALUE(STRING_LITERAL[a]))))",
+		},
+		{
+			name:        "should parse FIRST and LAST as columns",
+			query:       "SELECT first FROM foo.bar ORDER BY last DESC NULLS FIRST",
+			expectedAST: "SELECT(SELECT_LIST(COLUMN[first]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) ORDER_BY(ORDER_ITEM[DESC](COLUMN[last] NULLS[FIRST])))",
+		},
+		{
+			name:        "should parse LEFT and RIGHT as columns next to joins",
+			query:       "SELECT left, right FROM foo.bar t LEFT JOIN foo.baz ON t.left = baz.right",
+			expectedAST: "SELECT(SELECT_LIST(COLUMN[left] COLUMN[right]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar] ALIAS[t]) JOIN[LEFT](TABLE_DEFINITION(DATABASE[foo] TABLE[baz]) ON(COMPARISON[=](COLUMN[left](TABLE[t]) COLUMN[right](TABLE[baz]))))))",
+		},
+		{
+			name:        "should parse ROW and CURRENT as columns next to frames",
+			query:       "SELECT SUM(current) OVER (ORDER BY row ROWS BETWEEN CURRENT ROW AND 1 FOLLOWING) FROM foo.bar",
+			expectedAST: "SELECT(SELECT_LIST(OVER(FUNCTION[SUM](COLUMN[current]) ORDER_BY(ORDER_ITEM[ASC](COLUMN[row])) FRAME[ROWS](FRAME_BOUND[CURRENT ROW] FRAME_BOUND[FOLLOWING](INTEGER_LITERAL[1])))) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])))",
+		},
+		{
+			name:        "should parse END as a column inside CASE",
+			query:       "SELECT CASE WHEN end > 1 THEN end ELSE 0 END AS end FROM foo.bar",
+			expectedAST: "SELECT(SELECT_LIST(ALIAS[end](CASE(WHEN(COMPARISON[>](COLUMN[end] VALUE(INTEGER_LITERAL[1])) COLUMN[end]) ELSE(VALUE(INTEGER_LITERAL[0]))))) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])))",
+		},
+		{
+			name:  "should parse CREATE TABLE with NOT NULL constraints",
+			query: "CREATE TABLE foo.bar (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE, price FLOAT CONSTRAINT price_nn NOT NULL)",
+			expectedAST: "CREATE_TABLE(TABLE_DEFINITION(DATABASE[foo] TABLE[bar] COLUMN_DEFINITION_LIST(" +
+				"COLUMN_DEFINITION[id](DATA_TYPE[INTEGER] CONSTRAINT[PRIMARY_KEY]) " +
+				"COLUMN_DEFINITION[name](DATA_TYPE[TEXT] CONSTRAINT[NOT_NULL] CONSTRAINT[UNIQUE]) " +
+				"COLUMN_DEFINITION[price](DATA_TYPE[FLOAT] CONSTRAINT[NOT_NULL](CONSTRAINT_NAME[price_nn])))))",
+		},
+		{
+			name:          "should return ErrUnexpectedToken for NOT without NULL in constraints",
+			query:         "CREATE TABLE foo.bar (id INTEGER PRIMARY KEY, name TEXT NOT UNIQUE)",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:        "should parse DROP TABLE",
+			query:       "DROP TABLE foo.bar",
+			expectedAST: "DROP_TABLE(TABLE_DEFINITION(DATABASE[foo] TABLE[bar]))",
+		},
+		{
+			name:        "should parse CREATE UNIQUE INDEX",
+			query:       "CREATE UNIQUE INDEX bar_name_idx ON foo.bar (name, ts);",
+			expectedAST: "CREATE_INDEX(INDEX[bar_name_idx] TABLE_DEFINITION(DATABASE[foo] TABLE[bar] COLUMN_LIST(COLUMN[name] COLUMN[ts])) UNIQUE)",
+		},
+		{
+			name:        "should parse DROP INDEX",
+			query:       "DROP INDEX bar_name_idx ON foo.bar",
+			expectedAST: "DROP_INDEX(INDEX[bar_name_idx] TABLE_DEFINITION(DATABASE[foo] TABLE[bar]))",
+		},
+		{
+			name:          "should return ErrUnexpectedToken for FROM without a table",
+			query:         "SELECT * FROM",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for queries ending in a name",
+			query:         "SELECT * FROM foo.bar WHERE EXTRACT(year",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for CREATE OR REPLACE INDEX",
+			query:         "CREATE OR REPLACE INDEX bar_name_idx ON foo.bar (name)",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:  "should parse INSERT",
+			query: "INSERT INTO foo.bar (id, name, price) VALUES (1, 'test', -2.5)",
+			expectedAST: "INSERT(TABLE_DEFINITION(DATABASE[foo] TABLE[bar] COLUMN_LIST(COLUMN[id] COLUMN[name] COLUMN[price]) " +
+				"VALUE_LIST(VALUE(INTEGER_LITERAL[1]) VALUE(STRING_LITERAL[test]) VALUE(FLOAT_LITERAL[-2.5]))))",
+		},
+		{
+			name:  "should parse NULL values",
+			query: "INSERT INTO foo.bar (id, name) VALUES (1, NULL)",
+			expectedAST: "INSERT(TABLE_DEFINITION(DATABASE[foo] TABLE[bar] COLUMN_LIST(COLUMN[id] COLUMN[name]) " +
+				"VALUE_LIST(VALUE(INTEGER_LITERAL[1]) VALUE(NULL_LITERAL[NULL]))))",
+		},
+		{
+			name:          "should return ErrUnexpectedToken for negated NULL values",
+			query:         "INSERT INTO foo.bar (id, price) VALUES (1, -NULL)",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:  "should parse UPDATE with WHERE",
+			query: "UPDATE foo.bar SET name = 'baz', price = 3 WHERE id = 1",
+			expectedAST: "UPDATE(TABLE_DEFINITION(DATABASE[foo] TABLE[bar]) " +
+				"ASSIGNMENT_LIST(ASSIGNMENT(COLUMN[name] VALUE(STRING_LITERAL[baz])) ASSIGNMENT(COLUMN[price] VALUE(INTEGER_LITERAL[3]))) " +
+				"WHERE(COMPARISON[=](COLUMN[id] VALUE(INTEGER_LITERAL[1]))))",
+		},
+		{
+			name:  "should parse UPDATE with expressions",
+			query: "UPDATE foo.bar SET name = replace(name, 'a', 'b'), price = price * 2",
+			expectedAST: "UPDATE(TABLE_DEFINITION(DATABASE[foo] TABLE[bar]) " +
+				"ASSIGNMENT_LIST(ASSIGNMENT(COLUMN[name] FUNCTION[REPLACE](COLUMN[name] VALUE(STRING_LITERAL[a]) VALUE(STRING_LITERAL[b]))) " +
+				"ASSIGNMENT(COLUMN[price] ARITHMETIC[*](COLUMN[price] VALUE(INTEGER_LITERAL[2])))))",
+		},
+		{
+			name:  "should parse CASE and EXTRACT",
+			query: "SELECT CASE WHEN price > 10 THEN 'high' WHEN price > 5 THEN 'mid' ELSE 'low' END, EXTRACT(year FROM created_at) FROM foo.bar",
+			expectedAST: "SELECT(SELECT_LIST(" +
+				"CASE(WHEN(COMPARISON[>](COLUMN[price] VALUE(INTEGER_LITERAL[10])) VALUE(STRING_LITERAL[high])) " +
+				"WHEN(COMPARISON[>](COLUMN[price] VALUE(INTEGER_LITERAL[5])) VALUE(STRING_LITERAL[mid])) " +
+				"ELSE(VALUE(STRING_LITERAL[low]))) " +
+				"FUNCTION[EXTRACT](VALUE(STRING_LITERAL[YEAR]) COLUMN[created_at])" +
+				") FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])))",
+		},
+		{
+			name:  "should parse CAST",
+			query: "SELECT CAST(price AS integer) FROM foo.bar WHERE created_at > CAST('2024-01-01' AS TIMESTAMP)",
+			expectedAST: "SELECT(SELECT_LIST(CAST[INTEGER](COLUMN[price])) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) WHERE(" +
+				"COMPARISON[>](COLUMN[created_at] CAST[TIMESTAMP](VALUE(STRING_LITERAL[2024-01-01])))))",
+		},
+		{
+			name:          "should return ErrUnexpectedToken for CAST without type",
+			query:         "SELECT CAST(price) FROM foo.bar",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for CASE without WHEN",
+			query:         "SELECT CASE ELSE 1 END FROM foo.bar",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:        "should parse DELETE without WHERE",
+			query:       "DELETE FROM foo.bar",
+			expectedAST: "DELETE(TABLE_DEFINITION(DATABASE[foo] TABLE[bar]))",
+		},
+		{
+			name:  "should parse SELECT * with chained conditions",
+			query: "SELECT * FROM foo.bar WHERE NOT id = 1 AND name = 'a' OR NOT name = 'b'",
+			expectedAST: "SELECT(SELECT_LIST(ALL_COLUMNS) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) WHERE(" +
+				"OR(AND(NOT(COMPARISON[=](COLUMN[id] VALUE(INTEGER_LITERAL[1]))) " +
+				"COMPARISON[=](COLUMN[name] VALUE(STRING_LITERAL[a]))) " +
+				"NOT(COMPARISON[=](COLUMN[name] VALUE(STRING_LITERAL[b]))))))",
+		},
+		{
+			name:  "should parse SELECT with comparison operators",
+			query: "SELECT * FROM foo.bar WHERE id >= 1 AND id != 3 OR price BETWEEN 1 AND 2.5",
+			expectedAST: "SELECT(SELECT_LIST(ALL_COLUMNS) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) WHERE(" +
+				"OR(AND(COMPARISON[>=](COLUMN[id] VALUE(INTEGER_LITERAL[1])) " +
+				"COMPARISON[<>](COLUMN[id] VALUE(INTEGER_LITERAL[3]))) " +
+				"COMPARISON[BETWEEN](COLUMN[price] VALUE(INTEGER_LITERAL[1]) VALUE(FLOAT_LITERAL[2.5])))))",
+		},
+		{
+			name:  "should parse SELECT with negated IN, LIKE and IS NULL",
+			query: "SELECT * FROM foo.bar WHERE id NOT IN (1, 2) AND NOT name ILIKE 'a%' AND name IS NOT NULL",
+			expectedAST: "SELECT(SELECT_LIST(ALL_COLUMNS) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) WHERE(" +
+				"AND(AND(NOT(COMPARISON[IN](COLUMN[id] VALUE_LIST(VALUE(INTEGER_LITERAL[1]) VALUE(INTEGER_LITERAL[2])))) " +
+				"NOT(COMPARISON[ILIKE](COLUMN[name] VALUE(STRING_LITERAL[a%])))) " +
+				"NOT(COMPARISON[IS NULL](COLUMN[name])))))",
+		},
+		{
+			name:  "should parse AND before OR",
+			query: "SELECT * FROM foo.bar WHERE id = 1 OR id = 2 AND name = 'a'",
+			expectedAST: "SELECT(SELECT_LIST(ALL_COLUMNS) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) WHERE(" +
+				"OR(COMPARISON[=](COLUMN[id] VALUE(INTEGER_LITERAL[1])) " +
+				"AND(COMPARISON[=](COLUMN[id] VALUE(INTEGER_LITERAL[2])) COMPARISON[=](COLUMN[name] VALUE(STRING_LITERAL[a]))))))",
+		},
+		{
+			name:  "should parse parenthesised conditions and column comparisons",
+			query: "SELECT * FROM foo.bar WHERE NOT (id = 1 OR price > id) AND (name LIKE 'a%')",
+			expectedAST: "SELECT(SELECT_LIST(ALL_COLUMNS) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) WHERE(" +
+				"AND(NOT(OR(COMPARISON[=](COLUMN[id] VALUE(INTEGER_LITERAL[1])) COMPARISON[>](COLUMN[price] COLUMN[id]))) " +
+				"COMPARISON[LIKE](COLUMN[name] VALUE(STRING_LITERAL[a%])))))",
+		},
+		{
+			name:          "should return ErrUnexpectedToken for unbalanced parentheses",
+			query:         "SELECT * FROM foo.bar WHERE (id = 1 OR id = 2",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for NOT without a negatable comparison",
+			query:         "SELECT * FROM foo.bar WHERE id NOT = 1",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:        "should parse SELECT with column list",
+			query:       "SELECT id, name FROM foo.bar",
+			expectedAST: "SELECT(SELECT_LIST(COLUMN[id] COLUMN[name]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])))",
+		},
+		{
+			name:  "should parse SELECT with aliases and computed expressions",
+			query: "SELECT id AS code, price * -2 + 1 total, -(id - 1), name || 'x', * FROM foo.bar",
+			expectedAST: "SELECT(SELECT_LIST(" +
+				"ALIAS[code](COLUMN[id]) " +
+				"ALIAS[total](ARITHMETIC[+](ARITHMETIC[*](COLUMN[price] VALUE(INTEGER_LITERAL[-2])) VALUE(INTEGER_LITERAL[1]))) " +
+				"NEGATION(ARITHMETIC[-](COLUMN[id] VALUE(INTEGER_LITERAL[1]))) " +
+				"ARITHMETIC[||](COLUMN[name] VALUE(STRING_LITERAL[x])) " +
+				"ALL_COLUMNS) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])))",
+		},
+		{
+			name:  "should parse arithmetic before comparisons",
+			query: "SELECT * FROM foo.bar WHERE price - 1 > id % 2",
+			expectedAST: "SELECT(SELECT_LIST(ALL_COLUMNS) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) WHERE(" +
+				"COMPARISON[>](ARITHMETIC[-](COLUMN[price] VALUE(INTEGER_LITERAL[1])) ARITHMETIC[%](COLUMN[id] VALUE(INTEGER_LITERAL[2])))))",
+		},
+		{
+			name:  "should parse SELECT with ORDER BY, LIMIT and OFFSET",
+			query: "SELECT * FROM foo.bar WHERE id > 1 ORDER BY name DESC NULLS FIRST, id, price ASC NULLS LAST LIMIT 10 OFFSET 20",
+			expectedAST: "SELECT(SELECT_LIST(ALL_COLUMNS) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) " +
+				"WHERE(COMPARISON[>](COLUMN[id] VALUE(INTEGER_LITERAL[1]))) " +
+				"ORDER_BY(ORDER_ITEM[DESC](COLUMN[name] NULLS[FIRST]) ORDER_ITEM[ASC](COLUMN[id]) ORDER_ITEM[ASC](COLUMN[price] NULLS[LAST])) " +
+				"LIMIT[10] OFFSET[20])",
+		},
+		{
+			name:        "should parse SELECT with OFFSET only",
+			query:       "SELECT id FROM foo.bar OFFSET 5",
+			expectedAST: "SELECT(SELECT_LIST(COLUMN[id]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) OFFSET[5])",
+		},
+		{
+			name:  "should parse aggregate functions with GROUP BY and HAVING",
+			query: "SELECT kind, count(*), COUNT(DISTINCT name), sum(price * 2) AS total FROM foo.bar GROUP BY kind, id HAVING COUNT(*) > 1",
+			expectedAST: "SELECT(SELECT_LIST(COLUMN[kind] FUNCTION[COUNT](ALL_COLUMNS) FUNCTION[COUNT](DISTINCT(COLUMN[name])) " +
+				"ALIAS[total](FUNCTION[SUM](ARITHMETIC[*](COLUMN[price] VALUE(INTEGER_LITERAL[2]))))) " +
+				"FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])) GROUP_BY(COLUMN[kind] COLUMN[id]) " +
+				"HAVING(COMPARISON[>](FUNCTION[COUNT](ALL_COLUMNS) VALUE(INTEGER_LITERAL[1]))))",
+		},
+		{
+			name:        "should parse SELECT DISTINCT",
+			query:       "SELECT DISTINCT name FROM foo.bar",
+			expectedAST: "SELECT(DISTINCT SELECT_LIST(COLUMN[name]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])))",
+		},
+		{
+			name:  "should parse set operations with INTERSECT first and ORDER BY over the whole query",
+			query: "SELECT id FROM foo.a UNION ALL SELECT id FROM foo.b INTERSECT SELECT id FROM foo.c EXCEPT SELECT id FROM foo.d ORDER BY id LIMIT 1",
+			expectedAST: "SET_OPERATION[EXCEPT](" +
+				"SET_OPERATION[UNION ALL](" +
+				"SELECT(SELECT_LIST(COLUMN[id]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[a]))) " +
+				"SET_OPERATION[INTERSECT](" +
+				"SELECT(SELECT_LIST(COLUMN[id]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[b]))) " +
+				"SELECT(SELECT_LIST(COLUMN[id]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[c]))))) " +
+				"SELECT(SELECT_LIST(COLUMN[id]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[d]))) " +
+				"ORDER_BY(ORDER_ITEM[ASC](COLUMN[id])) LIMIT[1])",
+		},
+		{
+			name:  "should parse joins with aliases and qualified columns",
+			query: "SELECT f.id, b.name FROM foo.a AS f LEFT OUTER JOIN bar.b b ON f.id = b.id CROSS JOIN foo.c WHERE c.id > 1 ORDER BY b.name",
+			expectedAST: "SELECT(SELECT_LIST(COLUMN[id](TABLE[f]) COLUMN[name](TABLE[b])) " +
+				"FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[a] ALIAS[f]) " +
+				"JOIN[LEFT](TABLE_DEFINITION(DATABASE[bar] TABLE[b] ALIAS[b]) ON(COMPARISON[=](COLUMN[id](TABLE[f]) COLUMN[id](TABLE[b])))) " +
+				"JOIN[CROSS](TABLE_DEFINITION(DATABASE[foo] TABLE[c]))) " +
+				"WHERE(COMPARISON[>](COLUMN[id](TABLE[c]) VALUE(INTEGER_LITERAL[1]))) " +
+				"ORDER_BY(ORDER_ITEM[ASC](COLUMN[name](TABLE[b]))))",
+		},
+		{
+			name:          "should return ErrUnexpectedToken for joins without ON",
+			query:         "SELECT id FROM foo.a JOIN foo.b",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:  "should parse IN, EXISTS and scalar subqueries",
+			query: "SELECT id, (SELECT max(b.id) FROM foo.b) FROM foo.a WHERE id NOT IN (SELECT id FROM foo.c ORDER BY id LIMIT 2) AND NOT EXISTS (SELECT id FROM foo.d WHERE d.id = a.id)",
+			expectedAST: "SELECT(SELECT_LIST(COLUMN[id] SUBQUERY(SELECT(SELECT_LIST(FUNCTION[MAX](COLUMN[id](TABLE[b]))) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[b]))))) " +
+				"FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[a])) " +
+				"WHERE(AND(" +
+				"NOT(COMPARISON[IN](COLUMN[id] SUBQUERY(SELECT(SELECT_LIST(COLUMN[id]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[c])) ORDER_BY(ORDER_ITEM[ASC](COLUMN[id])) LIMIT[2])))) " +
+				"NOT(EXISTS(SUBQUERY(SELECT(SELECT_LIST(COLUMN[id]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[d])) WHERE(COMPARISON[=](COLUMN[id](TABLE[d]) COLUMN[id](TABLE[a]))))))))))",
+		},
+		{
+			name:          "should return ErrUnexpectedToken for EXISTS without a subquery",
+			query:         "SELECT id FROM foo.a WHERE EXISTS (1)",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:  "should parse WITH RECURSIVE before set operations, with common tables read as tables",
+			query: "WITH RECURSIVE t (n) AS (SELECT id FROM foo.a UNION ALL SELECT n + 1 FROM t WHERE n < 3), u AS (SELECT n FROM t) SELECT n FROM t UNION SELECT n FROM u x",
+			expectedAST: "SET_OPERATION[UNION](" +
+				"WITH[RECURSIVE](" +
+				"COMMON_TABLE[t](COLUMN_LIST(COLUMN[n]) SUBQUERY(SET_OPERATION[UNION ALL](" +
+				"SELECT(SELECT_LIST(COLUMN[id]) FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[a]))) " +
+				"SELECT(SELECT_LIST(ARITHMETIC[+](COLUMN[n] VALUE(INTEGER_LITERAL[1]))) FROM(TABLE_DEFINITION(TABLE[t])) WHERE(COMPARISON[<](COLUMN[n] VALUE(INTEGER_LITERAL[3]))))))) " +
+				"COMMON_TABLE[u](SUBQUERY(SELECT(SELECT_LIST(COLUMN[n]) FROM(TABLE_DEFINITION(TABLE[t])))))) " +
+				"SELECT(SELECT_LIST(COLUMN[n]) FROM(TABLE_DEFINITION(TABLE[t]))) " +
+				"SELECT(SELECT_LIST(COLUMN[n]) FROM(TABLE_DEFINITION(TABLE[u] ALIAS[x]))))",
+		},
+		{
+			name:          "should return ErrUnexpectedToken for common tables without AS",
+			query:         "WITH t (SELECT id FROM foo.a) SELECT id FROM t",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:  "should parse window functions with partitions, orders and frames",
+			query: "SELECT ROW_NUMBER() OVER (), lag(x, 2) OVER (PARTITION BY a, b ORDER BY c DESC), SUM(x) OVER (ORDER BY c ROWS 2 PRECEDING), SUM(x) OVER (ROWS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING) FROM foo.bar",
+			expectedAST: "SELECT(SELECT_LIST(" +
+				"OVER(FUNCTION[ROW_NUMBER]) " +
+				"OVER(FUNCTION[LAG](COLUMN[x] VALUE(INTEGER_LITERAL[2])) PARTITION_BY(COLUMN[a] COLUMN[b]) ORDER_BY(ORDER_ITEM[DESC](COLUMN[c]))) " +
+				"OVER(FUNCTION[SUM](COLUMN[x]) ORDER_BY(ORDER_ITEM[ASC](COLUMN[c])) FRAME[ROWS](FRAME_BOUND[PRECEDING](INTEGER_LITERAL[2]) FRAME_BOUND[CURRENT ROW])) " +
+				"OVER(FUNCTION[SUM](COLUMN[x]) FRAME[ROWS](FRAME_BOUND[CURRENT ROW] FRAME_BOUND[UNBOUNDED FOLLOWING]))" +
+				") FROM(TABLE_DEFINITION(DATABASE[foo] TABLE[bar])))",
+		},
+		{
+			name:          "should return ErrUnexpectedToken for frame bounds without a direction",
+			query:         "SELECT SUM(x) OVER (ROWS BETWEEN 1 AND CURRENT ROW) FROM foo.bar",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for ORDER BY before a set operation",
+			query:         "SELECT id FROM foo.a ORDER BY id UNION SELECT id FROM foo.b",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for GROUP without BY",
+			query:         "SELECT id FROM foo.bar GROUP id",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for unterminated function calls",
+			query:         "SELECT count(id FROM foo.bar",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for negative limits",
+			query:         "SELECT id FROM foo.bar LIMIT -1",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for NULLS without placement",
+			query:         "SELECT id FROM foo.bar ORDER BY id NULLS",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for AS without an alias",
+			query:         "SELECT id AS FROM foo.bar",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:        "should parse BEGIN TRANSACTION",
+			query:       "BEGIN TRANSACTION;",
+			expectedAST: "BEGIN",
+		},
+		{
+			name:        "should parse COMMIT",
+			query:       "commit",
+			expectedAST: "COMMIT",
+		},
+		{
+			name:        "should parse ROLLBACK",
+			query:       "ROLLBACK",
+			expectedAST: "ROLLBACK",
+		},
+		{
+			name:          "should return ErrUnexpectedToken for unknown statements",
+			query:         "FOO bar",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for unqualified table names",
+			query:         "DELETE FROM bar",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for trailing tokens",
+			query:         "DROP DATABASE foo bar",
+			expectedError: ErrUnexpectedToken,
+		},
+		{
+			name:          "should return ErrUnterminatedString for unclosed strings",
+			query:         "SELECT * FROM foo.bar WHERE name = 'baz",
+			expectedError: ErrUnterminatedString,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ast, err := ParseQueryIntoAST(testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if actual := ast.String(); actual != testCase.expectedAST {
+				t.Errorf("expected AST %s, got %s", testCase.expectedAST, actual)
+				return
+			}
+		})
+	}
+}
+
+func TestParseStatementsIntoAST(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expectedASTs  []string
+		expectedError error
+	}{
+		{
+			name:         "should parse statements separated by semicolons",
+			query:        "BEGIN; DROP DATABASE foo;; COMMIT",
+			expectedASTs: []string{"BEGIN", "DROP_DATABASE(DATABASE[foo])", "COMMIT"},
+		},
+		{
+			name:         "should parse an empty script",
+			query:        " ; ",
+			expectedASTs: nil,
+		},
+		{
+			name:          "should return ErrUnexpectedToken for statements without separator",
+			query:         "BEGIN COMMIT",
+			expectedError: ErrUnexpectedToken,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			asts, err := ParseStatementsIntoAST(testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if len(asts) != len(testCase.expectedASTs) {
+				t.Errorf("expected %d statements, got %d", len(testCase.expectedASTs), len(asts))
+				return
+			}
+
+			for i, ast := range asts {
+				if actual := ast.String(); actual != testCase.expectedASTs[i] {
+					t.Errorf("expected AST %s, got %s", testCase.expectedASTs[i], actual)
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestParseQueryIntoASTSyntaxErrorPosition(t *testing.T) {
+	_, err := ParseQueryIntoAST("SELECT *\nFROM foo.bar\nWHERE id = = 1")
+
+	var syntaxError *SyntaxError
+	if !errors.As(err, &syntaxError) {
+		t.Errorf("expected *SyntaxError, got %v", err)
+		return
+	}
+
+	if syntaxError.Line != 3 || syntaxError.Column != 12 || syntaxError.Token != "=" {
+		t.Errorf("expected error at line 3, column 12 near \"=\", got %s", syntaxError)
+		return
+	}
+}
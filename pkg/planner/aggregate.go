@@ -0,0 +1,207 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+)
+
+var (
+	ErrColumnNotGrouped   = errors.New("column must be grouped or used in an aggregate function")
+	ErrMisplacedAggregate = errors.New("aggregate functions are not allowed here")
+)
+
+var aggregateFunctions = map[string]dql.AggregateFunction{
+	string(dql.AggregateFunctionCount): dql.AggregateFunctionCount,
+	string(dql.AggregateFunctionSum):   dql.AggregateFunctionSum,
+	string(dql.AggregateFunctionAvg):   dql.AggregateFunctionAvg,
+	string(dql.AggregateFunctionMin):   dql.AggregateFunctionMin,
+	string(dql.AggregateFunctionMax):   dql.AggregateFunctionMax,
+}
+
+type grouping struct {
+	columns    []ddl.Column
+	aggregates []dql.Aggregate
+}
+
+// isAggregated Checks if a select groups its rows, which it does if it has GROUP BY or
+// HAVING, or if its select list calls an aggregate function
+func (f *functions) isAggregated(ast *parser.AST) bool {
+	if ast.FirstChildOfType(parser.TypeGroupBy) != nil || ast.FirstChildOfType(parser.TypeHaving) != nil {
+		return true
+	}
+
+	selectList := ast.FirstChildOfType(parser.TypeSelectList)
+	return selectList != nil && f.callsAggregate(selectList)
+}
+
+// callsAggregate Checks if the node calls an aggregate function outside of subqueries,
+// which aggregate their own rows, and other than as window functions, whose arguments can
+// still call them
+func (f *functions) callsAggregate(node *parser.AST) bool {
+	if node.Type == parser.TypeSubquery {
+		return false
+	}
+
+	if node.Type == parser.TypeOver {
+		function := node.FirstChildOfType(parser.TypeFunction)
+		return function != nil && slices.ContainsFunc(function.Children, f.callsAggregate)
+	}
+
+	if node.Type == parser.TypeFunction {
+		if _, isAggregate := f.aggregate(node.Value); isAggregate {
+			return true
+		}
+	}
+
+	for _, child := range node.Children {
+		if f.callsAggregate(child) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func groupingForGroupBy(scope *scope, groupBy *parser.AST) (*grouping, error) {
+	grouping := &grouping{}
+	if groupBy == nil {
+		return grouping, nil
+	}
+
+	for _, columnNode := range groupBy.ChildrenOfType(parser.TypeColumn) {
+		column, err := scope.columnForNode(columnNode)
+		if err != nil {
+			return nil, err
+		}
+
+		grouping.columns = append(grouping.columns, column)
+	}
+
+	return grouping, nil
+}
+
+// aggregation Returns the aggregation of the grouping, with the grouped columns and the
+// aggregates planned so far
+func (g *grouping) aggregation(having dql.Expression) *dql.Aggregation {
+	groupBy := make([]string, 0, len(g.columns))
+	for _, column := range g.columns {
+		groupBy = append(groupBy, column.Name)
+	}
+
+	return &dql.Aggregation{
+		GroupBy:    groupBy,
+		Aggregates: g.aggregates,
+		Having:     having,
+	}
+}
+
+func functionForNode(scope *scope, node *parser.AST) (typedExpression, error) {
+	if function, isAggregate := scope.with.functions.aggregate(node.Value); isAggregate {
+		return aggregateForNode(scope, node, function)
+	}
+
+	return scalarFunctionForNode(scope, node)
+}
+
+// aggregateForNode Adds the aggregate to the grouping of the scope and returns a reference
+// to its column in the group rows. COUNT is INTEGER, AVG is FLOAT, SUM, MIN and MAX have
+// the type of their argument, and user defined functions their returned type
+func aggregateForNode(scope *scope, node *parser.AST, function dql.AggregateFunction) (typedExpression, error) {
+	if scope.grouping == nil {
+		return typedExpression{}, fmt.Errorf("%w: %s", ErrMisplacedAggregate, function)
+	}
+
+	if len(node.Children) != 1 {
+		return typedExpression{}, malformedASTError(node, "single argument")
+	}
+
+	aggregate := dql.Aggregate{Function: function, NewState: scope.with.functions.newState(function)}
+	argument := node.Children[0]
+
+	switch argument.Type {
+	case parser.TypeAllColumns:
+		if function != dql.AggregateFunctionCount {
+			return typedExpression{}, fmt.Errorf("%w: %s(*)", ErrUnsupportedStatement, function)
+		}
+
+		aggregate.Column.DataType = ddl.ColumnDataTypeInteger
+
+	case parser.TypeDistinct:
+		if len(argument.Children) != 1 {
+			return typedExpression{}, malformedASTError(argument, "expression")
+		}
+
+		aggregate.Distinct = true
+		argument = argument.Children[0]
+	}
+
+	if argument.Type != parser.TypeAllColumns {
+		// The argument is evaluated over the rows of the group, so it can reference any
+		// column but no other aggregate
+		typed, err := expressionForNode(scope.ungrouped(), argument, nil)
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		dataType, err := scope.with.functions.aggregateDataType(function, typed.dataType)
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		aggregate.Expression = typed.expression
+		aggregate.Column.DataType = dataType
+	}
+
+	aggregate.Column.Name = fmt.Sprintf("$%s%d", function, len(scope.grouping.aggregates))
+	scope.grouping.aggregates = append(scope.grouping.aggregates, aggregate)
+
+	return typedExpression{
+		expression: dql.ColumnRef{Name: aggregate.Column.Name},
+		dataType:   aggregate.Column.DataType,
+	}, nil
+}
+
+func (f *functions) aggregateDataType(function dql.AggregateFunction, argumentDataType ddl.ColumnDataType) (ddl.ColumnDataType, error) {
+	if argumentDataType == conditionDataType {
+		return "", fmt.Errorf("%w: can not aggregate conditions with %s", dql.ErrInvalidDataType, function)
+	}
+
+	if user, isUser := f.aggregates[string(function)]; isUser {
+		if !dataTypeAccepts(user.argument, argumentDataType) {
+			return "", fmt.Errorf("%w: %s expects %s, got %s", dql.ErrInvalidDataType, function, user.argument, argumentDataType)
+		}
+
+		return user.returns, nil
+	}
+
+	switch function {
+	case dql.AggregateFunctionCount:
+		return ddl.ColumnDataTypeInteger, nil
+
+	case dql.AggregateFunctionSum, dql.AggregateFunctionAvg:
+		if !dataTypeIsNumber(argumentDataType) {
+			return "", fmt.Errorf("%w: %s requires a number, got %s", dql.ErrInvalidDataType, function, argumentDataType)
+		}
+
+		if function == dql.AggregateFunctionAvg {
+			return ddl.ColumnDataTypeFloat, nil
+		}
+	}
+
+	return argumentDataType, nil
+}
+
+// newState Returns the constructor of the states of a user defined aggregate function, or
+// nil for built-in ones
+func (f *functions) newState(function dql.AggregateFunction) func() dql.AggregateState {
+	if user, isUser := f.aggregates[string(function)]; isUser {
+		return user.newState
+	}
+
+	return nil
+}
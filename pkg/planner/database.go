@@ -0,0 +1,40 @@
+package planner
+
+import (
+	"github.com/gustapinto/go-sql-store/pkg/executor"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+func planCreateDatabase(_ *storage.Tx, ast *parser.AST) ([]executor.Action, error) {
+	name, err := childValue(ast, parser.TypeDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	action := executor.CreateDatabaseAction().WithParams(executor.Params{
+		executor.CreateDatabaseParamsDatabaseName:      name,
+		executor.CreateDatabaseParamsCreateOrReplace:   ast.HasChildOfType(parser.TypeOrReplace),
+		executor.CreateDatabaseParamsCreateIfNotExists: ast.HasChildOfType(parser.TypeIfNotExists),
+	})
+
+	return []executor.Action{action}, nil
+}
+
+func planDropDatabase(tx *storage.Tx, ast *parser.AST) ([]executor.Action, error) {
+	name, err := childValue(ast, parser.TypeDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ddl.GetDatabase(tx, name); err != nil {
+		return nil, err
+	}
+
+	action := executor.DropDatabaseAction().WithParams(executor.Params{
+		executor.DropDatabaseParamsDatabaseNameKey: name,
+	})
+
+	return []executor.Action{action}, nil
+}
@@ -0,0 +1,468 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+)
+
+var (
+	ErrFunctionArguments = errors.New("wrong number of arguments for function")
+	ErrFunctionExists    = errors.New("function already exists")
+)
+
+// functionSignature Checks the types of the arguments of a call to a scalar function and
+// returns the type of its result
+type functionSignature func(name string, arguments []ddl.ColumnDataType) (ddl.ColumnDataType, error)
+
+// numberDataType Accepts INTEGER and FLOAT arguments in a signature
+const numberDataType ddl.ColumnDataType = "NUMBER"
+
+var builtinSignatures = map[string]functionSignature{
+	"UPPER":      signature(ddl.ColumnDataTypeText, 1, ddl.ColumnDataTypeText),
+	"LOWER":      signature(ddl.ColumnDataTypeText, 1, ddl.ColumnDataTypeText),
+	"TRIM":       signature(ddl.ColumnDataTypeText, 1, ddl.ColumnDataTypeText, ddl.ColumnDataTypeText),
+	"SUBSTR":     signature(ddl.ColumnDataTypeText, 2, ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger),
+	"LENGTH":     signature(ddl.ColumnDataTypeInteger, 1, ddl.ColumnDataTypeText),
+	"CONCAT":     concatSignature,
+	"REPLACE":    signature(ddl.ColumnDataTypeText, 3, ddl.ColumnDataTypeText, ddl.ColumnDataTypeText, ddl.ColumnDataTypeText),
+	"ABS":        numberSignature(1, numberDataType),
+	"ROUND":      numberSignature(1, numberDataType, ddl.ColumnDataTypeInteger),
+	"FLOOR":      numberSignature(1, numberDataType),
+	"CEIL":       numberSignature(1, numberDataType),
+	"MOD":        modSignature,
+	"NOW":        signature(ddl.ColumnDataTypeTimestamp, 0),
+	"DATE_TRUNC": signature(ddl.ColumnDataTypeTimestamp, 2, ddl.ColumnDataTypeText, ddl.ColumnDataTypeTimestamp),
+	"EXTRACT":    signature(ddl.ColumnDataTypeInteger, 2, ddl.ColumnDataTypeText, ddl.ColumnDataTypeTimestamp),
+	"COALESCE":   coalesceSignature,
+	"NULLIF":     nullIfSignature,
+}
+
+// functions The user defined functions a statement can call, by their name in uppercase
+type functions struct {
+	scalars    map[string]userScalarFunction
+	aggregates map[string]userAggregateFunction
+}
+
+// userScalarFunction A scalar function registered with [WithScalarFunction]
+type userScalarFunction struct {
+	function       dql.ScalarFunction
+	checkArguments functionSignature
+}
+
+// userAggregateFunction An aggregate function registered with [WithAggregateFunction]
+type userAggregateFunction struct {
+	argument ddl.ColumnDataType
+	returns  ddl.ColumnDataType
+	newState func() dql.AggregateState
+}
+
+// add Registers a function under the name in uppercase, which can not be the name of a
+// built-in function or of a function already registered
+func (f *functions) add(name string, scalar *userScalarFunction, aggregate *userAggregateFunction) error {
+	name = strings.ToUpper(name)
+
+	_, isBuiltin := dql.BuiltinFunctions[name]
+	_, isAggregate := aggregateFunctions[name]
+	_, isWindow := windowFunctions[name]
+	_, isScalar := f.scalars[name]
+	_, isUserAggregate := f.aggregates[name]
+	if isBuiltin || isAggregate || isWindow || isScalar || isUserAggregate {
+		return fmt.Errorf("%w: %s", ErrFunctionExists, name)
+	}
+
+	if scalar != nil {
+		if f.scalars == nil {
+			f.scalars = make(map[string]userScalarFunction)
+		}
+
+		f.scalars[name] = *scalar
+	}
+
+	if aggregate != nil {
+		if f.aggregates == nil {
+			f.aggregates = make(map[string]userAggregateFunction)
+		}
+
+		f.aggregates[name] = *aggregate
+	}
+
+	return nil
+}
+
+// scalar Returns the built-in or user defined scalar function with the name, and its
+// signature
+func (f *functions) scalar(name string) (dql.ScalarFunction, functionSignature, bool) {
+	if function, isBuiltin := dql.BuiltinFunctions[name]; isBuiltin {
+		checkArguments, hasSignature := builtinSignatures[name]
+		return function, checkArguments, hasSignature
+	}
+
+	user, isUser := f.scalars[name]
+	return user.function, user.checkArguments, isUser
+}
+
+// aggregate Returns the built-in or user defined aggregate function with the name
+func (f *functions) aggregate(name string) (dql.AggregateFunction, bool) {
+	if function, isBuiltin := aggregateFunctions[name]; isBuiltin {
+		return function, true
+	}
+
+	_, isUser := f.aggregates[name]
+	return dql.AggregateFunction(name), isUser
+}
+
+// newUserScalarFunction Returns a scalar function called with the values of its arguments,
+// INTEGER values of FLOAT arguments being converted to floats, whose results must have the
+// returned type
+func newUserScalarFunction(name string, arguments []ddl.ColumnDataType, returns ddl.ColumnDataType, fn func(arguments []any) (any, error)) *userScalarFunction {
+	call := func(values []any) (any, error) {
+		for i, value := range values {
+			values[i] = widenedValue(value, arguments[i])
+		}
+
+		value, err := fn(values)
+		if err != nil {
+			return nil, err
+		}
+
+		return checkedResult(name, value, returns)
+	}
+
+	return &userScalarFunction{
+		function:       dql.ScalarFunction{Call: call},
+		checkArguments: signature(returns, len(arguments), arguments...),
+	}
+}
+
+// userAggregateState The state of a user defined aggregate function, converting INTEGER
+// values of a FLOAT argument to floats and checking the type of its result
+type userAggregateState struct {
+	dql.AggregateState
+	name     string
+	argument ddl.ColumnDataType
+	returns  ddl.ColumnDataType
+}
+
+func (u userAggregateState) Add(value any) error {
+	return u.AggregateState.Add(widenedValue(value, u.argument))
+}
+
+func (u userAggregateState) Result() (any, error) {
+	value, err := u.AggregateState.Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return checkedResult(u.name, value, u.returns)
+}
+
+func newUserAggregateFunction(name string, argument ddl.ColumnDataType, returns ddl.ColumnDataType, newState func() dql.AggregateState) *userAggregateFunction {
+	return &userAggregateFunction{
+		argument: argument,
+		returns:  returns,
+		newState: func() dql.AggregateState {
+			return userAggregateState{AggregateState: newState(), name: name, argument: argument, returns: returns}
+		},
+	}
+}
+
+func widenedValue(value any, dataType ddl.ColumnDataType) any {
+	if integer, isInteger := value.(int64); isInteger && dataType == ddl.ColumnDataTypeFloat {
+		return float64(integer)
+	}
+
+	return value
+}
+
+// checkedResult Returns the result of a user defined function as a value of its returned
+// type, see [ddl.ValueForDataType]
+func checkedResult(name string, value any, returns ddl.ColumnDataType) (any, error) {
+	value, err := ddl.ValueForDataType(value, returns)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s returned %s", dql.ErrInvalidDataType, strings.ToUpper(name), err.Error())
+	}
+
+	return value, nil
+}
+
+// scalarFunctionForNode Returns the call of a scalar function, which is FLOAT if any of its
+// INTEGER arguments can be its result
+func scalarFunctionForNode(scope *scope, node *parser.AST) (typedExpression, error) {
+	function, checkArguments, isFunction := scope.with.functions.scalar(node.Value)
+	if !isFunction {
+		return typedExpression{}, fmt.Errorf("%w: function %s", ErrUnsupportedStatement, node.Value)
+	}
+
+	call := dql.FunctionCall{Name: node.Value, Function: function}
+	dataTypes := make([]ddl.ColumnDataType, 0, len(node.Children))
+	for _, argument := range node.Children {
+		if argument.Type == parser.TypeAllColumns || argument.Type == parser.TypeDistinct {
+			return typedExpression{}, fmt.Errorf("%w: %s in function %s", ErrUnsupportedStatement, argument.Type, node.Value)
+		}
+
+		typed, err := expressionForNode(scope, argument, nil)
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		if typed.dataType == conditionDataType {
+			return typedExpression{}, fmt.Errorf("%w: expected a value in %s, got a condition", dql.ErrInvalidDataType, node.Value)
+		}
+
+		call.Arguments = append(call.Arguments, typed.expression)
+		dataTypes = append(dataTypes, typed.dataType)
+	}
+
+	dataType, err := checkArguments(node.Value, dataTypes)
+	if err != nil {
+		return typedExpression{}, err
+	}
+
+	return typedExpression{expression: widened(call, dataType, dataTypes...), dataType: dataType}, nil
+}
+
+// caseForNode Returns the expression of a CASE, whose results must have the same type or
+// be numbers, in which case it is FLOAT if any of them is
+func caseForNode(scope *scope, node *parser.AST) (typedExpression, error) {
+	var expression dql.Case
+	var dataTypes []ddl.ColumnDataType
+	for _, child := range node.Children {
+		var resultNode *parser.AST
+		switch {
+		case child.Type == parser.TypeWhen && len(child.Children) == 2:
+			condition, err := conditionForNode(scope, child.Children[0])
+			if err != nil {
+				return typedExpression{}, err
+			}
+
+			expression.Whens = append(expression.Whens, dql.When{Condition: condition})
+			resultNode = child.Children[1]
+
+		case child.Type == parser.TypeElse && len(child.Children) == 1:
+			resultNode = child.Children[0]
+
+		default:
+			return typedExpression{}, malformedASTError(node, "WHEN or ELSE")
+		}
+
+		result, err := expressionForNode(scope, resultNode, nil)
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		if result.dataType == conditionDataType {
+			return typedExpression{}, fmt.Errorf("%w: expected a value in CASE, got a condition", dql.ErrInvalidDataType)
+		}
+
+		if child.Type == parser.TypeElse {
+			expression.Else = result.expression
+		} else {
+			expression.Whens[len(expression.Whens)-1].Result = result.expression
+		}
+
+		dataTypes = append(dataTypes, result.dataType)
+	}
+
+	if len(expression.Whens) == 0 {
+		return typedExpression{}, malformedASTError(node, parser.TypeWhen)
+	}
+
+	dataType, err := commonDataType("CASE", dataTypes)
+	if err != nil {
+		return typedExpression{}, err
+	}
+
+	return typedExpression{expression: widened(expression, dataType, dataTypes...), dataType: dataType}, nil
+}
+
+// castForNode Returns the conversion of an expression to the type of a CAST. Any value can
+// be cast to TEXT, TEXT to any type, and INTEGER and FLOAT to each other. Literals are
+// converted when planning
+func castForNode(scope *scope, node *parser.AST) (typedExpression, error) {
+	if len(node.Children) != 1 {
+		return typedExpression{}, malformedASTError(node, "expression")
+	}
+
+	dataType := ddl.ColumnDataType(node.Value)
+	if !ddl.IsColumnDataTypeSupported(dataType) {
+		return typedExpression{}, fmt.Errorf("%w: %s", ErrUnsupportedDataType, node.Value)
+	}
+
+	typed, err := expressionForNode(scope, node.Children[0], nil)
+	if err != nil {
+		return typedExpression{}, err
+	}
+
+	switch {
+	case typed.dataType == conditionDataType:
+		return typedExpression{}, fmt.Errorf("%w: can not cast a condition to %s", dql.ErrInvalidDataType, dataType)
+
+	case typed.dataType == dataType:
+		return typed, nil
+
+	case typed.dataType == nullDataType:
+
+	case dataType == ddl.ColumnDataTypeText, typed.dataType == ddl.ColumnDataTypeText:
+	case dataTypeIsNumber(dataType) && dataTypeIsNumber(typed.dataType):
+
+	default:
+		return typedExpression{}, fmt.Errorf("%w: can not cast %s to %s", dql.ErrInvalidDataType, typed.dataType, dataType)
+	}
+
+	cast := dql.Cast{Expression: typed.expression, DataType: dataType}
+	if _, isLiteral := typed.expression.(dql.Literal); isLiteral {
+		value, err := cast.Evaluate(dml.Row{})
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		return typedExpression{expression: dql.Literal{Value: value}, dataType: dataType}, nil
+	}
+
+	return typedExpression{expression: cast, dataType: dataType}, nil
+}
+
+// widened Returns the expression converting its integers to floats if it is FLOAT but any
+// of the values it can result in is INTEGER
+func widened(expression dql.Expression, dataType ddl.ColumnDataType, dataTypes ...ddl.ColumnDataType) dql.Expression {
+	if dataType != ddl.ColumnDataTypeFloat {
+		return expression
+	}
+
+	for _, valueDataType := range dataTypes {
+		if valueDataType == ddl.ColumnDataTypeInteger {
+			return dql.Widen{Expression: expression}
+		}
+	}
+
+	return expression
+}
+
+// signature Returns the signature of a function with arguments of the types, of which the
+// first required ones must be given
+func signature(returnType ddl.ColumnDataType, required int, argumentTypes ...ddl.ColumnDataType) functionSignature {
+	return func(name string, arguments []ddl.ColumnDataType) (ddl.ColumnDataType, error) {
+		if err := checkArgumentTypes(name, arguments, required, argumentTypes); err != nil {
+			return "", err
+		}
+
+		return returnType, nil
+	}
+}
+
+// numberSignature Returns the signature of a function whose result has the type of its
+// first argument, a number
+func numberSignature(required int, argumentTypes ...ddl.ColumnDataType) functionSignature {
+	return func(name string, arguments []ddl.ColumnDataType) (ddl.ColumnDataType, error) {
+		if err := checkArgumentTypes(name, arguments, required, argumentTypes); err != nil {
+			return "", err
+		}
+
+		return arguments[0], nil
+	}
+}
+
+func checkArgumentTypes(name string, arguments []ddl.ColumnDataType, required int, argumentTypes []ddl.ColumnDataType) error {
+	if len(arguments) < required || len(arguments) > len(argumentTypes) {
+		if required == len(argumentTypes) {
+			return fmt.Errorf("%w: %s expects %d arguments, got %d", ErrFunctionArguments, name, required, len(arguments))
+		}
+
+		return fmt.Errorf("%w: %s expects %d to %d arguments, got %d", ErrFunctionArguments, name, required, len(argumentTypes), len(arguments))
+	}
+
+	for i, dataType := range arguments {
+		if !dataTypeAccepts(argumentTypes[i], dataType) {
+			return fmt.Errorf("%w: %s expects %s as argument %d, got %s", dql.ErrInvalidDataType, name, argumentTypes[i], i+1, dataType)
+		}
+	}
+
+	return nil
+}
+
+// dataTypeAccepts Checks if values of the type can be given where values of the expected
+// type are, which they can if they are the same, NULL, numbers for NUMBER, or INTEGER for
+// FLOAT
+func dataTypeAccepts(expected, dataType ddl.ColumnDataType) bool {
+	if dataType == nullDataType {
+		return true
+	}
+
+	switch expected {
+	case dataType:
+		return true
+
+	case numberDataType:
+		return dataTypeIsNumber(dataType)
+
+	case ddl.ColumnDataTypeFloat:
+		return dataType == ddl.ColumnDataTypeInteger
+	}
+
+	return false
+}
+
+// concatSignature Accepts any number of arguments of any type
+func concatSignature(name string, arguments []ddl.ColumnDataType) (ddl.ColumnDataType, error) {
+	if len(arguments) == 0 {
+		return "", fmt.Errorf("%w: %s expects at least 1 argument", ErrFunctionArguments, name)
+	}
+
+	return ddl.ColumnDataTypeText, nil
+}
+
+// modSignature Accepts two numbers, the result being INTEGER if both are INTEGER
+func modSignature(name string, arguments []ddl.ColumnDataType) (ddl.ColumnDataType, error) {
+	if err := checkArgumentTypes(name, arguments, 2, []ddl.ColumnDataType{numberDataType, numberDataType}); err != nil {
+		return "", err
+	}
+
+	return commonDataType(name, arguments)
+}
+
+// coalesceSignature Accepts at least one argument, all of them having the same type or
+// being numbers
+func coalesceSignature(name string, arguments []ddl.ColumnDataType) (ddl.ColumnDataType, error) {
+	if len(arguments) == 0 {
+		return "", fmt.Errorf("%w: %s expects at least 1 argument", ErrFunctionArguments, name)
+	}
+
+	return commonDataType(name, arguments)
+}
+
+// nullIfSignature Accepts two arguments that can be compared, the result having the type
+// of the first one
+func nullIfSignature(name string, arguments []ddl.ColumnDataType) (ddl.ColumnDataType, error) {
+	if len(arguments) != 2 {
+		return "", fmt.Errorf("%w: %s expects 2 arguments, got %d", ErrFunctionArguments, name, len(arguments))
+	}
+
+	if !dataTypesAreComparable(arguments[0], arguments[1]) {
+		return "", fmt.Errorf("%w: %s can not compare %s with %s", dql.ErrInvalidDataType, name, arguments[0], arguments[1])
+	}
+
+	return arguments[0], nil
+}
+
+// commonDataType Returns the type of values that can have any of the types, which must be
+// the same, NULL or numbers, in which case it is FLOAT if any of them is
+func commonDataType(name string, dataTypes []ddl.ColumnDataType) (ddl.ColumnDataType, error) {
+	common := dataTypes[0]
+	for _, dataType := range dataTypes[1:] {
+		if !dataTypesAreComparable(common, dataType) {
+			return "", fmt.Errorf("%w: %s can not mix %s with %s", dql.ErrInvalidDataType, name, common, dataType)
+		}
+
+		if common == nullDataType || dataType == ddl.ColumnDataTypeFloat {
+			common = dataType
+		}
+	}
+
+	return common, nil
+}
@@ -0,0 +1,90 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/gustapinto/go-sql-store/pkg/executor"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
+)
+
+func planCreateIndex(tx *storage.Tx, ast *parser.AST) ([]executor.Action, error) {
+	name, err := childValue(ast, parser.TypeIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := tableForStatement(tx, ast)
+	if err != nil {
+		return nil, err
+	}
+
+	columnList := ast.FirstChildOfType(parser.TypeTableDefinition).FirstChildOfType(parser.TypeColumnList)
+	if columnList == nil {
+		return nil, malformedASTError(ast, parser.TypeColumnList)
+	}
+
+	index := ddl.Index{
+		Name:   name,
+		Unique: ast.HasChildOfType(parser.TypeUnique),
+	}
+
+	for _, columnNode := range columnList.ChildrenOfType(parser.TypeColumn) {
+		column, err := columnForName(table, columnNode.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, existing := range index.Columns {
+			if stringutils.EqualsIgnoreCase(existing, column.Name) {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicatedColumn, column.Name)
+			}
+		}
+
+		index.Columns = append(index.Columns, column.Name)
+	}
+
+	for _, existing := range ddl.TableIndexes(*table) {
+		if stringutils.EqualsIgnoreCase(existing.Name, index.Name) {
+			return nil, fmt.Errorf("%w: %s", ddl.ErrIndexAlreadyExists, index.Name)
+		}
+	}
+
+	action := executor.CreateIndexAction().WithParams(executor.Params{
+		executor.CreateIndexParamsDatabaseKey:  table.Database,
+		executor.CreateIndexParamsTableNameKey: table.Name,
+		executor.CreateIndexParamsIndexKey:     index,
+	})
+
+	return []executor.Action{action}, nil
+}
+
+func planDropIndex(tx *storage.Tx, ast *parser.AST) ([]executor.Action, error) {
+	name, err := childValue(ast, parser.TypeIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := tableForStatement(tx, ast)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, existing := range table.Indexes {
+		if !stringutils.EqualsIgnoreCase(existing.Name, name) {
+			continue
+		}
+
+		action := executor.DropIndexAction().WithParams(executor.Params{
+			executor.DropIndexParamsDatabaseKey:  table.Database,
+			executor.DropIndexParamsTableNameKey: table.Name,
+			executor.DropIndexParamsIndexNameKey: existing.Name,
+		})
+
+		return []executor.Action{action}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ddl.ErrIndexDoesNotExists, name)
+}
@@ -0,0 +1,157 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/gustapinto/go-sql-store/pkg/executor"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var (
+	ErrUnsupportedStatement = errors.New("unsupported statement")
+	ErrMalformedAST         = errors.New("malformed AST")
+)
+
+func malformedASTError(node *parser.AST, expected string) error {
+	return fmt.Errorf("%w: expected %s in %s node", ErrMalformedAST, expected, node.Type)
+}
+
+func childValue(node *parser.AST, nodeType string) (string, error) {
+	child := node.FirstChildOfType(nodeType)
+	if child == nil {
+		return "", malformedASTError(node, nodeType)
+	}
+
+	return child.Value, nil
+}
+
+// Option Changes how statements are planned
+type Option func(o *options)
+
+type options struct {
+	maxRecursiveIterations int
+	functions              functions
+	err                    error
+}
+
+// WithMaxRecursiveIterations Limits how many times the recursive query of a WITH RECURSIVE
+// common table can run, [dql.DefaultMaxRecursiveIterations] by default
+func WithMaxRecursiveIterations(iterations int) Option {
+	return func(o *options) {
+		o.maxRecursiveIterations = iterations
+	}
+}
+
+// WithScalarFunction Registers a scalar function, callable by its name in any case with
+// arguments of the types and returning values of the returned type. It is not called with
+// NULL arguments, the call being NULL, and INTEGER values are given as floats to FLOAT
+// arguments. Planning fails with ErrFunctionExists if the name is already taken
+func WithScalarFunction(name string, arguments []ddl.ColumnDataType, returns ddl.ColumnDataType, fn func(arguments []any) (any, error)) Option {
+	return func(o *options) {
+		if err := o.functions.add(name, newUserScalarFunction(name, arguments, returns, fn), nil); err != nil {
+			o.err = errors.Join(o.err, err)
+		}
+	}
+}
+
+// WithAggregateFunction Registers an aggregate function, callable by its name in any case
+// with an argument of the type, as a grouping or a window function. Each group, or frame,
+// has a new state, to which the non NULL values are added, see [dql.AggregateState].
+// Planning fails with ErrFunctionExists if the name is already taken
+func WithAggregateFunction(name string, argument ddl.ColumnDataType, returns ddl.ColumnDataType, newState func() dql.AggregateState) Option {
+	return func(o *options) {
+		if err := o.functions.add(name, nil, newUserAggregateFunction(name, argument, returns, newState)); err != nil {
+			o.err = errors.Join(o.err, err)
+		}
+	}
+}
+
+func Plan(tx *storage.Tx, ast *parser.AST, opts ...Option) (executor.ExecutionPlan, error) {
+	if ast == nil {
+		return executor.ExecutionPlan{}, fmt.Errorf("%w: empty statement", ErrUnsupportedStatement)
+	}
+
+	o := options{maxRecursiveIterations: dql.DefaultMaxRecursiveIterations}
+	for _, option := range opts {
+		option(&o)
+	}
+
+	if o.err != nil {
+		return executor.ExecutionPlan{}, o.err
+	}
+
+	// The statement starts without common tables, its WITH clauses add them
+	with := &commonTables{maxRecursiveIterations: o.maxRecursiveIterations, functions: &o.functions}
+
+	var actions []executor.Action
+	var err error
+
+	switch ast.Type {
+	case parser.TypeCreateDatabaseOperation:
+		actions, err = planCreateDatabase(tx, ast)
+
+	case parser.TypeDropDatabaseOperation:
+		actions, err = planDropDatabase(tx, ast)
+
+	case parser.TypeCreateTableOperation:
+		actions, err = planCreateTable(tx, ast)
+
+	case parser.TypeDropTableOperation:
+		actions, err = planDropTable(tx, ast)
+
+	case parser.TypeCreateIndexOperation:
+		actions, err = planCreateIndex(tx, ast)
+
+	case parser.TypeDropIndexOperation:
+		actions, err = planDropIndex(tx, ast)
+
+	case parser.TypeInsertOperation:
+		actions, err = planInsert(tx, ast)
+
+	case parser.TypeUpdateOperation:
+		actions, err = planUpdate(tx, ast, with)
+
+	case parser.TypeDeleteOperation:
+		actions, err = planDelete(tx, ast, with)
+
+	case parser.TypeSelectOperation:
+		actions, err = planSelect(tx, ast, with)
+
+	case parser.TypeSetOperation:
+		actions, err = planSetOperation(tx, ast, with)
+
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedStatement, ast.Type)
+	}
+
+	if err != nil {
+		return executor.ExecutionPlan{}, err
+	}
+
+	return executor.ExecutionPlan{
+		ID:      uuid.NewString(),
+		Actions: actions,
+	}, nil
+}
+
+// ExecuteQuery Parses, plans and runs a single statement inside the transaction, the
+// transaction is not committed
+func ExecuteQuery(tx *storage.Tx, query string, opts ...Option) (executor.ExecuteResult, error) {
+	ast, err := parser.ParseQueryIntoAST(query)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := Plan(tx, ast, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return executor.Run(tx, plan, context.Background())
+}
@@ -0,0 +1,1838 @@
+package planner
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gustapinto/go-sql-store/pkg/executor"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+func testPlannerExecuteQuery(store *storage.Storage, query string, opts ...Option) (executor.ExecuteResult, error) {
+	tx := store.Begin()
+
+	result, err := ExecuteQuery(tx, query, opts...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return result, tx.Commit()
+}
+
+func testPlannerMockStorage(t *testing.T, queries ...string) *storage.Storage {
+	store, err := storage.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	for _, query := range queries {
+		result, err := testPlannerExecuteQuery(store, query)
+		if err != nil {
+			t.Fatalf("not expected error when executing %s, got %s", query, err)
+		}
+
+		if result["Status"] != "SUCCESS" {
+			t.Fatalf("expected %s to succeed, got %v", query, result)
+		}
+	}
+
+	return store
+}
+
+func TestPlan(t *testing.T) {
+	testCases := []struct {
+		name              string
+		setup             []string
+		query             string
+		expectedActionIDs []string
+		expectedError     error
+	}{
+		{
+			name:              "should plan CREATE DATABASE",
+			query:             "CREATE DATABASE PLAN_DB_1",
+			expectedActionIDs: []string{executor.CreateDatabaseID},
+		},
+		{
+			name:              "should plan CREATE TABLE",
+			setup:             []string{"CREATE DATABASE PLAN_DB_2"},
+			query:             "CREATE TABLE PLAN_DB_2.FOO (id INTEGER PRIMARY KEY, name TEXT)",
+			expectedActionIDs: []string{executor.CreateTableID},
+		},
+		{
+			name:          "should return ErrDatabaseDoesNotExists when creating a table in a missing database",
+			query:         "CREATE TABLE PLAN_DB_3.FOO (id INTEGER PRIMARY KEY)",
+			expectedError: ddl.ErrDatabaseDoesNotExists,
+		},
+		{
+			name:          "should return ErrTableWithoutPrimaryKey when creating a table without primary key",
+			setup:         []string{"CREATE DATABASE PLAN_DB_4"},
+			query:         "CREATE TABLE PLAN_DB_4.FOO (id INTEGER, name TEXT)",
+			expectedError: ErrTableWithoutPrimaryKey,
+		},
+		{
+			name:          "should return ErrUnsupportedDataType when creating a column with unknown type",
+			setup:         []string{"CREATE DATABASE PLAN_DB_5"},
+			query:         "CREATE TABLE PLAN_DB_5.FOO (id BLOB PRIMARY KEY)",
+			expectedError: ErrUnsupportedDataType,
+		},
+		{
+			name:          "should return ErrDuplicatedColumn when creating a table with repeated columns",
+			setup:         []string{"CREATE DATABASE PLAN_DB_6"},
+			query:         "CREATE TABLE PLAN_DB_6.FOO (id INTEGER PRIMARY KEY, ID TEXT)",
+			expectedError: ErrDuplicatedColumn,
+		},
+		{
+			name:          "should return ErrDuplicatedConstraint when creating a table with repeated constraint names",
+			setup:         []string{"CREATE DATABASE PLAN_DB_9"},
+			query:         "CREATE TABLE PLAN_DB_9.FOO (id INTEGER PRIMARY KEY, a INTEGER CONSTRAINT x UNIQUE, b INTEGER CONSTRAINT X UNIQUE)",
+			expectedError: ddl.ErrDuplicatedConstraint,
+		},
+		{
+			name:              "should plan DROP TABLE",
+			setup:             []string{"CREATE DATABASE PLAN_DB_7", "CREATE TABLE PLAN_DB_7.FOO (id INTEGER PRIMARY KEY)"},
+			query:             "DROP TABLE PLAN_DB_7.FOO",
+			expectedActionIDs: []string{executor.DropTableID},
+		},
+		{
+			name:          "should return ErrTableDoesNotExists when dropping a missing table",
+			setup:         []string{"CREATE DATABASE PLAN_DB_8"},
+			query:         "DROP TABLE PLAN_DB_8.FOO",
+			expectedError: ddl.ErrTableDoesNotExists,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			store := testPlannerMockStorage(t, testCase.setup...)
+
+			ast, err := parser.ParseQueryIntoAST(testCase.query)
+			if err != nil {
+				t.Errorf("not expected error when parsing query, got %s", err)
+				return
+			}
+
+			tx := store.Begin()
+			defer tx.Rollback()
+
+			plan, err := Plan(tx, ast)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if len(plan.Actions) != len(testCase.expectedActionIDs) {
+				t.Errorf("expected %d actions, got %d", len(testCase.expectedActionIDs), len(plan.Actions))
+				return
+			}
+
+			for i, action := range plan.Actions {
+				if action.ID != testCase.expectedActionIDs[i] {
+					t.Errorf("expected action %s, got %s", testCase.expectedActionIDs[i], action.ID)
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteQuery(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE EXECUTE_DB",
+		"CREATE TABLE EXECUTE_DB.FOO (id INTEGER PRIMARY KEY, name TEXT CONSTRAINT foo_name UNIQUE)",
+	)
+
+	tx := store.Begin()
+	defer tx.Rollback()
+
+	table, err := ddl.GetTable(tx, "EXECUTE_DB", "FOO")
+	if err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	expectedColumns := []ddl.Column{
+		{
+			Name:     "ID",
+			DataType: ddl.ColumnDataTypeInteger,
+			Constraints: []ddl.Constraint{
+				{Type: ddl.ConstraintPrimaryKey, Name: "id_pkey"},
+			},
+		},
+		{
+			Name:     "NAME",
+			DataType: ddl.ColumnDataTypeText,
+			Constraints: []ddl.Constraint{
+				{Type: ddl.ConstraintUnique, Name: "foo_name"},
+			},
+		},
+	}
+
+	if len(table.Columns) != len(expectedColumns) {
+		t.Errorf("expected %d columns, got %d", len(expectedColumns), len(table.Columns))
+		return
+	}
+
+	for i, column := range table.Columns {
+		if !ddl.AreColumnsEqual(column, expectedColumns[i]) {
+			t.Errorf("expected column %v, got %v", expectedColumns[i], column)
+			return
+		}
+	}
+
+	if _, err := ExecuteQuery(tx, "CREATE TABLE"); !errors.Is(err, parser.ErrUnexpectedToken) {
+		t.Errorf("expected error %v, got %v", parser.ErrUnexpectedToken, err)
+		return
+	}
+
+	if _, err := testPlannerExecuteQuery(store, "INSERT INTO EXECUTE_DB.FOO (id, name) VALUES (1, 'foo')"); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if _, err := testPlannerExecuteQuery(store, "INSERT INTO EXECUTE_DB.FOO (id, name) VALUES (2, 'foo')"); !errors.Is(err, dml.ErrUniqueConstraintViolation) {
+		t.Errorf("expected error %v, got %v", dml.ErrUniqueConstraintViolation, err)
+		return
+	}
+}
+
+func TestExecuteQueryCreateIfNotExists(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE EXISTS_DB",
+		"CREATE TABLE EXISTS_DB.FOO (id INTEGER PRIMARY KEY, name TEXT, amount INTEGER)",
+		"CREATE INDEX foo_amount_idx ON EXISTS_DB.FOO (amount)",
+		"INSERT INTO EXISTS_DB.FOO (id, name, amount) VALUES (1, 'foo', 2)",
+		"CREATE DATABASE IF NOT EXISTS EXISTS_DB",
+		"CREATE TABLE IF NOT EXISTS EXISTS_DB.FOO (id INTEGER PRIMARY KEY)",
+	)
+
+	result, err := testPlannerExecuteQuery(store, "SELECT * FROM EXISTS_DB.FOO WHERE amount = 2")
+	if err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	expectedRows := [][]any{{int64(1), "foo", int64(2)}}
+	if rows, _ := result["Rows"].([][]any); !slices.EqualFunc(rows, expectedRows, slices.Equal) {
+		t.Errorf("expected rows %v, got %v", expectedRows, rows)
+		return
+	}
+
+	tx := store.Begin()
+	defer tx.Rollback()
+
+	table, err := ddl.GetTable(tx, "EXISTS_DB", "FOO")
+	if err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if len(table.Columns) != 3 || len(table.Indexes) != 1 {
+		t.Errorf("expected the table to keep its 3 columns and 1 index, got %v", table)
+		return
+	}
+}
+
+func TestExecuteQueryRows(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE ROWS_DB",
+		"CREATE TABLE ROWS_DB.FOO (id INTEGER PRIMARY KEY, name TEXT, price FLOAT)",
+		"INSERT INTO ROWS_DB.FOO (id, name, price) VALUES (1, 'foo', 10)",
+		"INSERT INTO ROWS_DB.FOO (id, name, price) VALUES (2, 'bar', 2.5)",
+		"INSERT INTO ROWS_DB.FOO (id, name, price) VALUES (3, 'baz', 7.5)",
+	)
+
+	testCases := []struct {
+		name                 string
+		query                string
+		verifyQuery          string
+		expectedAffectedRows int
+		expectedRows         [][]any
+		expectedError        error
+	}{
+		{
+			name:         "should select with equality filter",
+			query:        "SELECT name, price FROM ROWS_DB.FOO WHERE id = 1",
+			expectedRows: [][]any{{"foo", float64(10)}},
+		},
+		{
+			name:                 "should update matching rows",
+			query:                "UPDATE ROWS_DB.FOO SET name = 'qux' WHERE id = 2",
+			verifyQuery:          "SELECT * FROM ROWS_DB.FOO WHERE id = 2",
+			expectedAffectedRows: 1,
+			expectedRows:         [][]any{{int64(2), "qux", float64(2.5)}},
+		},
+		{
+			name:                 "should delete matching rows",
+			query:                "DELETE FROM ROWS_DB.FOO WHERE name = 'baz'",
+			verifyQuery:          "SELECT * FROM ROWS_DB.FOO WHERE id = 3",
+			expectedAffectedRows: 1,
+			expectedRows:         nil,
+		},
+		{
+			name:          "should return ErrColumnNotFound for unknown columns",
+			query:         "SELECT foobar FROM ROWS_DB.FOO",
+			expectedError: dql.ErrColumnNotFound,
+		},
+		{
+			name:          "should return ErrInvalidDataType for values with wrong type",
+			query:         "INSERT INTO ROWS_DB.FOO (id, name) VALUES ('4', 'foo')",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrColumnValueCountMismatch when counts differ",
+			query:         "INSERT INTO ROWS_DB.FOO (id, name) VALUES (4)",
+			expectedError: ErrColumnValueCountMismatch,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if testCase.verifyQuery != "" {
+				if result["AffectedRows"] != testCase.expectedAffectedRows {
+					t.Errorf("expected %d affected rows, got %v", testCase.expectedAffectedRows, result["AffectedRows"])
+					return
+				}
+
+				result, err = testPlannerExecuteQuery(store, testCase.verifyQuery)
+				if err != nil {
+					t.Errorf("not expected error, got %s", err)
+					return
+				}
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if len(rows) != len(testCase.expectedRows) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, result)
+				return
+			}
+
+			for i, row := range rows {
+				if !slices.Equal(row, testCase.expectedRows[i]) {
+					t.Errorf("expected row %v, got %v", testCase.expectedRows[i], row)
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteQueryWhere(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE WHERE_DB",
+		"CREATE TABLE WHERE_DB.FOO (id INTEGER PRIMARY KEY, name TEXT, price FLOAT)",
+		"INSERT INTO WHERE_DB.FOO (id, name, price) VALUES (1, 'foo', 10)",
+		"INSERT INTO WHERE_DB.FOO (id, name, price) VALUES (2, 'bar', 2.5)",
+		"INSERT INTO WHERE_DB.FOO (id, name, price) VALUES (3, 'baz', 7.5)",
+		"INSERT INTO WHERE_DB.FOO (id, name, price) VALUES (10, 'Qux_1', 7.5)",
+	)
+
+	testCases := []struct {
+		name          string
+		where         string
+		expectedIDs   []int64
+		expectedError error
+	}{
+		{
+			name:        "should filter by inequality",
+			where:       "price <> 7.5",
+			expectedIDs: []int64{1, 2},
+		},
+		{
+			name:        "should filter by inequality with !=",
+			where:       "name != 'foo'",
+			expectedIDs: []int64{2, 3, 10},
+		},
+		{
+			name:        "should compare integers as numbers",
+			where:       "id > 2 AND id <= 10",
+			expectedIDs: []int64{3, 10},
+		},
+		{
+			name:        "should compare floats with integer literals",
+			where:       "price >= 8 OR price < 3",
+			expectedIDs: []int64{1, 2},
+		},
+		{
+			name:        "should filter by BETWEEN",
+			where:       "price BETWEEN 2.5 AND 7.5 AND name <> 'bar'",
+			expectedIDs: []int64{3, 10},
+		},
+		{
+			name:        "should filter by NOT BETWEEN",
+			where:       "id NOT BETWEEN 2 AND 3",
+			expectedIDs: []int64{1, 10},
+		},
+		{
+			name:        "should filter by IN",
+			where:       "name IN ('foo', 'baz', 'qux')",
+			expectedIDs: []int64{1, 3},
+		},
+		{
+			name:        "should filter by NOT IN",
+			where:       "NOT id NOT IN (2, 3)",
+			expectedIDs: []int64{2, 3},
+		},
+		{
+			name:        "should filter by LIKE",
+			where:       "name LIKE 'ba_'",
+			expectedIDs: []int64{2, 3},
+		},
+		{
+			name:        "should filter by LIKE case sensitively",
+			where:       "name LIKE 'q%'",
+			expectedIDs: nil,
+		},
+		{
+			name:        "should filter by ILIKE",
+			where:       "name ILIKE 'q%1'",
+			expectedIDs: []int64{10},
+		},
+		{
+			name:        "should filter by NOT LIKE",
+			where:       "name NOT LIKE '%a%'",
+			expectedIDs: []int64{1, 10},
+		},
+		{
+			name:        "should filter by IS NOT NULL",
+			where:       "name IS NOT NULL",
+			expectedIDs: []int64{1, 2, 3, 10},
+		},
+		{
+			name:        "should filter by IS NULL",
+			where:       "name IS NULL",
+			expectedIDs: nil,
+		},
+		{
+			name:        "should evaluate AND before OR",
+			where:       "id = 1 OR id = 2 AND name = 'baz'",
+			expectedIDs: []int64{1},
+		},
+		{
+			name:        "should evaluate parenthesised conditions first",
+			where:       "(id = 1 OR id = 2) AND name = 'bar'",
+			expectedIDs: []int64{2},
+		},
+		{
+			name:        "should negate parenthesised conditions",
+			where:       "NOT (price = 7.5 OR id = 1) OR id = 10",
+			expectedIDs: []int64{2, 10},
+		},
+		{
+			name:        "should compare columns with each other",
+			where:       "price > id AND 5 < price",
+			expectedIDs: []int64{1, 3},
+		},
+		{
+			name:          "should not filter by a value that is not a condition",
+			where:         "id AND name = 'foo'",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should not compare columns of another type",
+			where:         "name = id",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should not compare columns with values of another type",
+			where:         "id < 'foo'",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should not match patterns against columns that are not TEXT",
+			where:         "id LIKE '1%'",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should not filter by IN with values of another type",
+			where:         "id IN (1, 'foo')",
+			expectedError: dql.ErrInvalidDataType,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, "SELECT id FROM WHERE_DB.FOO WHERE "+testCase.where)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			var ids []int64
+			rows, _ := result["Rows"].([][]any)
+			for _, row := range rows {
+				ids = append(ids, row[0].(int64))
+			}
+			slices.Sort(ids)
+
+			if !slices.Equal(ids, testCase.expectedIDs) {
+				t.Errorf("expected rows with ids %v, got %v", testCase.expectedIDs, ids)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQueryProjection(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE PROJECTION_DB",
+		"CREATE TABLE PROJECTION_DB.FOO (id INTEGER PRIMARY KEY, name TEXT, price FLOAT)",
+		"INSERT INTO PROJECTION_DB.FOO (id, name, price) VALUES (7, 'foo', 2.5)",
+	)
+
+	testCases := []struct {
+		name                string
+		selectList          string
+		expectedColumns     []string
+		expectedColumnTypes []ddl.ColumnDataType
+		expectedRow         []any
+		expectedError       error
+	}{
+		{
+			name:                "should select every column with *",
+			selectList:          "*",
+			expectedColumns:     []string{"ID", "NAME", "PRICE"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeText, ddl.ColumnDataTypeFloat},
+			expectedRow:         []any{int64(7), "foo", 2.5},
+		},
+		{
+			name:                "should select columns in the order of the list",
+			selectList:          "PRICE, id, *",
+			expectedColumns:     []string{"PRICE", "ID", "ID", "NAME", "PRICE"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeFloat, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeText, ddl.ColumnDataTypeFloat},
+			expectedRow:         []any{2.5, int64(7), int64(7), "foo", 2.5},
+		},
+		{
+			name:                "should name columns by their aliases",
+			selectList:          "id AS code, name label",
+			expectedColumns:     []string{"code", "label"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeText},
+			expectedRow:         []any{int64(7), "foo"},
+		},
+		{
+			name:                "should compute expressions with their types",
+			selectList:          "id * 2 + 1 AS odd, -id % 4 AS remainder, id / 2, price * id AS total, 'name: ' || name",
+			expectedColumns:     []string{"odd", "remainder", "?column?", "total", "?column?"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeFloat, ddl.ColumnDataTypeText},
+			expectedRow:         []any{int64(15), int64(-3), int64(3), 17.5, "name: foo"},
+		},
+		{
+			name:                "should compute parenthesised expressions first",
+			selectList:          "(id + 1) * 2 AS doubled",
+			expectedColumns:     []string{"doubled"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRow:         []any{int64(16)},
+		},
+		{
+			name:                "should type NULL by the other operand",
+			selectList:          "NULL + 1, price * NULL, NULL",
+			expectedColumns:     []string{"?column?", "?column?", "?column?"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeFloat, ddl.ColumnDataTypeText},
+			expectedRow:         []any{nil, nil, nil},
+		},
+		{
+			name:          "should return ErrColumnNotFound for unknown columns in expressions",
+			selectList:    "id + foo",
+			expectedError: dql.ErrColumnNotFound,
+		},
+		{
+			name:          "should return ErrInvalidDataType for operations over values of another type",
+			selectList:    "name + 1",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for conditions",
+			selectList:    "id = 7",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrDivisionByZero when dividing by zero",
+			selectList:    "price / (id - 7)",
+			expectedError: dql.ErrDivisionByZero,
+		},
+		{
+			name:          "should return ErrValueOutOfRange when INTEGER arithmetic overflows",
+			selectList:    "9223372036854775807 + id",
+			expectedError: ddl.ErrValueOutOfRange,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, "SELECT "+testCase.selectList+" FROM PROJECTION_DB.FOO")
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if columns, _ := result["Columns"].([]string); !slices.Equal(columns, testCase.expectedColumns) {
+				t.Errorf("expected columns %v, got %v", testCase.expectedColumns, columns)
+				return
+			}
+
+			if columnTypes, _ := result["ColumnTypes"].([]ddl.ColumnDataType); !slices.Equal(columnTypes, testCase.expectedColumnTypes) {
+				t.Errorf("expected column types %v, got %v", testCase.expectedColumnTypes, columnTypes)
+				return
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if len(rows) != 1 || !slices.Equal(rows[0], testCase.expectedRow) {
+				t.Errorf("expected row %v, got %v", testCase.expectedRow, rows)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQueryOrderBy(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE ORDER_DB",
+		"CREATE TABLE ORDER_DB.FOO (id INTEGER PRIMARY KEY, name TEXT, price FLOAT)",
+		"INSERT INTO ORDER_DB.FOO (id, name, price) VALUES (1, 'foo', 10)",
+		"INSERT INTO ORDER_DB.FOO (id, name, price) VALUES (2, 'bar', 2.5)",
+		"INSERT INTO ORDER_DB.FOO (id, name, price) VALUES (3, 'baz', 7.5)",
+		"INSERT INTO ORDER_DB.FOO (id, name, price) VALUES (10, 'qux', 7.5)",
+	)
+
+	testCases := []struct {
+		name          string
+		clauses       string
+		expectedIDs   []int64
+		expectedError error
+	}{
+		{
+			name:        "should sort by a column",
+			clauses:     "ORDER BY name",
+			expectedIDs: []int64{2, 3, 1, 10},
+		},
+		{
+			name:        "should sort by columns in turn",
+			clauses:     "ORDER BY price DESC, id DESC",
+			expectedIDs: []int64{1, 10, 3, 2},
+		},
+		{
+			name:        "should sort integers as numbers",
+			clauses:     "WHERE id > 1 ORDER BY id DESC LIMIT 2",
+			expectedIDs: []int64{10, 3},
+		},
+		{
+			name:        "should page through the sorted rows",
+			clauses:     "ORDER BY price, id LIMIT 2 OFFSET 1",
+			expectedIDs: []int64{3, 10},
+		},
+		{
+			name:        "should skip the offset rows",
+			clauses:     "ORDER BY id OFFSET 3",
+			expectedIDs: []int64{10},
+		},
+		{
+			name:        "should select no rows with LIMIT 0",
+			clauses:     "ORDER BY id LIMIT 0",
+			expectedIDs: nil,
+		},
+		{
+			name:          "should return ErrColumnNotFound for unknown columns",
+			clauses:       "ORDER BY foo",
+			expectedError: dql.ErrColumnNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, "SELECT id FROM ORDER_DB.FOO "+testCase.clauses)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			var ids []int64
+			rows, _ := result["Rows"].([][]any)
+			for _, row := range rows {
+				ids = append(ids, row[0].(int64))
+			}
+
+			if !slices.Equal(ids, testCase.expectedIDs) {
+				t.Errorf("expected rows with ids %v, got %v", testCase.expectedIDs, ids)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQueryAggregate(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE AGGREGATE_DB",
+		"CREATE TABLE AGGREGATE_DB.FOO (id INTEGER PRIMARY KEY, kind TEXT, amount INTEGER, price FLOAT)",
+		"INSERT INTO AGGREGATE_DB.FOO (id, kind, amount, price) VALUES (1, 'a', 2, 1.5)",
+		"INSERT INTO AGGREGATE_DB.FOO (id, kind, amount, price) VALUES (2, 'a', 2, 2.5)",
+		"INSERT INTO AGGREGATE_DB.FOO (id, kind, amount, price) VALUES (3, 'b', 5, 3)",
+		"INSERT INTO AGGREGATE_DB.FOO (id, kind, amount, price) VALUES (4, 'a', 8, 5)",
+		"INSERT INTO AGGREGATE_DB.FOO (id, kind, amount, price) VALUES (5, 'c', 1, 1)",
+	)
+
+	testCases := []struct {
+		name                string
+		query               string
+		expectedColumns     []string
+		expectedColumnTypes []ddl.ColumnDataType
+		expectedRows        [][]any
+		expectedError       error
+	}{
+		{
+			name:                "should aggregate every row without GROUP BY",
+			query:               "SELECT COUNT(*), COUNT(price), SUM(amount), SUM(price), AVG(amount), MIN(kind), MAX(price) FROM AGGREGATE_DB.FOO",
+			expectedColumns:     []string{"count", "count", "sum", "sum", "avg", "min", "max"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeFloat, ddl.ColumnDataTypeFloat, ddl.ColumnDataTypeText, ddl.ColumnDataTypeFloat},
+			expectedRows:        [][]any{{int64(5), int64(5), int64(18), 13.0, 3.6, "a", 5.0}},
+		},
+		{
+			name:                "should aggregate a single group when no rows match",
+			query:               "SELECT COUNT(*), SUM(amount) FROM AGGREGATE_DB.FOO WHERE id > 10",
+			expectedColumns:     []string{"count", "sum"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(0), nil}},
+		},
+		{
+			name:                "should aggregate each group",
+			query:               "SELECT kind, COUNT(*) AS total, COUNT(DISTINCT amount) AS amounts, SUM(amount * 2) FROM AGGREGATE_DB.FOO GROUP BY kind",
+			expectedColumns:     []string{"KIND", "total", "amounts", "sum"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{"a", int64(3), int64(2), int64(24)}, {"b", int64(1), int64(1), int64(10)}, {"c", int64(1), int64(1), int64(2)}},
+		},
+		{
+			name:                "should group by several columns",
+			query:               "SELECT kind, amount, COUNT(*) FROM AGGREGATE_DB.FOO WHERE kind <> 'c' GROUP BY kind, amount",
+			expectedColumns:     []string{"KIND", "AMOUNT", "count"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{"a", int64(2), int64(2)}, {"a", int64(8), int64(1)}, {"b", int64(5), int64(1)}},
+		},
+		{
+			name:                "should filter groups with HAVING and sort them by aggregates",
+			query:               "SELECT kind, MAX(amount) AS highest FROM AGGREGATE_DB.FOO GROUP BY kind HAVING COUNT(*) < 3 ORDER BY highest DESC LIMIT 1",
+			expectedColumns:     []string{"KIND", "highest"},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{"b", int64(5)}},
+		},
+		{
+			name:          "should return ErrColumnNotGrouped for columns out of GROUP BY",
+			query:         "SELECT kind, amount FROM AGGREGATE_DB.FOO GROUP BY kind",
+			expectedError: ErrColumnNotGrouped,
+		},
+		{
+			name:          "should return ErrColumnNotGrouped for columns used along aggregates",
+			query:         "SELECT id, COUNT(*) FROM AGGREGATE_DB.FOO",
+			expectedError: ErrColumnNotGrouped,
+		},
+		{
+			name:          "should return ErrMisplacedAggregate for aggregates in WHERE",
+			query:         "SELECT COUNT(*) FROM AGGREGATE_DB.FOO WHERE COUNT(*) > 1",
+			expectedError: ErrMisplacedAggregate,
+		},
+		{
+			name:          "should return ErrMisplacedAggregate for nested aggregates",
+			query:         "SELECT SUM(COUNT(*)) FROM AGGREGATE_DB.FOO",
+			expectedError: ErrMisplacedAggregate,
+		},
+		{
+			name:          "should return ErrInvalidDataType for sums of texts",
+			query:         "SELECT SUM(kind) FROM AGGREGATE_DB.FOO",
+			expectedError: dql.ErrInvalidDataType,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if columns, _ := result["Columns"].([]string); !slices.Equal(columns, testCase.expectedColumns) {
+				t.Errorf("expected columns %v, got %v", testCase.expectedColumns, columns)
+				return
+			}
+
+			if columnTypes, _ := result["ColumnTypes"].([]ddl.ColumnDataType); !slices.Equal(columnTypes, testCase.expectedColumnTypes) {
+				t.Errorf("expected column types %v, got %v", testCase.expectedColumnTypes, columnTypes)
+				return
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQuerySetOperation(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE SET_DB",
+		"CREATE TABLE SET_DB.FOO (id INTEGER PRIMARY KEY, name TEXT, price FLOAT)",
+		"CREATE TABLE SET_DB.BAR (id INTEGER PRIMARY KEY, name TEXT, amount INTEGER)",
+		"INSERT INTO SET_DB.FOO (id, name, price) VALUES (1, 'a', 1)",
+		"INSERT INTO SET_DB.FOO (id, name, price) VALUES (2, 'b', 2.5)",
+		"INSERT INTO SET_DB.FOO (id, name, price) VALUES (3, 'a', 3)",
+		"INSERT INTO SET_DB.BAR (id, name, amount) VALUES (1, 'b', 2)",
+		"INSERT INTO SET_DB.BAR (id, name, amount) VALUES (2, 'c', 3)",
+	)
+
+	testCases := []struct {
+		name                string
+		query               string
+		expectedColumnTypes []ddl.ColumnDataType
+		expectedRows        [][]any
+		expectedError       error
+	}{
+		{
+			name:                "should select distinct rows",
+			query:               "SELECT DISTINCT name FROM SET_DB.FOO ORDER BY name DESC",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"b"}, {"a"}},
+		},
+		{
+			name:                "should page through distinct rows",
+			query:               "SELECT DISTINCT name FROM SET_DB.FOO ORDER BY id LIMIT 1 OFFSET 1",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"b"}},
+		},
+		{
+			name:                "should return the distinct rows of both queries with UNION",
+			query:               "SELECT name FROM SET_DB.FOO UNION SELECT name FROM SET_DB.BAR ORDER BY name",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"a"}, {"b"}, {"c"}},
+		},
+		{
+			name:                "should return every row of both queries with UNION ALL",
+			query:               "SELECT name FROM SET_DB.FOO UNION ALL SELECT name FROM SET_DB.BAR ORDER BY name DESC LIMIT 3",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"c"}, {"b"}, {"b"}},
+		},
+		{
+			name:                "should compare integers and floats as numbers",
+			query:               "SELECT price FROM SET_DB.FOO INTERSECT SELECT amount FROM SET_DB.BAR",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeFloat},
+			expectedRows:        [][]any{{3.0}},
+		},
+		{
+			name:                "should return the distinct rows missing from the right query with EXCEPT",
+			query:               "SELECT name FROM SET_DB.FOO EXCEPT SELECT name FROM SET_DB.BAR",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"a"}},
+		},
+		{
+			name:          "should return ErrIncompatibleQueries for queries with another number of columns",
+			query:         "SELECT id, name FROM SET_DB.FOO UNION SELECT id FROM SET_DB.BAR",
+			expectedError: ErrIncompatibleQueries,
+		},
+		{
+			name:          "should return ErrIncompatibleQueries for columns of another type",
+			query:         "SELECT id FROM SET_DB.FOO UNION SELECT name FROM SET_DB.BAR",
+			expectedError: ErrIncompatibleQueries,
+		},
+		{
+			name:          "should return ErrColumnNotFound when sorting by columns out of the result",
+			query:         "SELECT id FROM SET_DB.FOO UNION SELECT id FROM SET_DB.BAR ORDER BY name",
+			expectedError: dql.ErrColumnNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if columnTypes, _ := result["ColumnTypes"].([]ddl.ColumnDataType); !slices.Equal(columnTypes, testCase.expectedColumnTypes) {
+				t.Errorf("expected column types %v, got %v", testCase.expectedColumnTypes, columnTypes)
+				return
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQueryJoin(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE JOIN_A",
+		"CREATE DATABASE JOIN_B",
+		"CREATE TABLE JOIN_A.CUSTOMERS (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE JOIN_B.ORDERS (id INTEGER PRIMARY KEY, customer_id INTEGER, total FLOAT)",
+		"INSERT INTO JOIN_A.CUSTOMERS (id, name) VALUES (1, 'ana')",
+		"INSERT INTO JOIN_A.CUSTOMERS (id, name) VALUES (2, 'bob')",
+		"INSERT INTO JOIN_A.CUSTOMERS (id, name) VALUES (3, 'cid')",
+		"INSERT INTO JOIN_B.ORDERS (id, customer_id, total) VALUES (1, 1, 10)",
+		"INSERT INTO JOIN_B.ORDERS (id, customer_id, total) VALUES (2, 1, 5.5)",
+		"INSERT INTO JOIN_B.ORDERS (id, customer_id, total) VALUES (3, 2, 7)",
+		"INSERT INTO JOIN_B.ORDERS (id, customer_id, total) VALUES (4, 9, 1)",
+	)
+
+	testCases := []struct {
+		name                string
+		query               string
+		expectedColumnTypes []ddl.ColumnDataType
+		expectedRows        [][]any
+		expectedError       error
+	}{
+		{
+			name:                "should join through the primary key of the joined table",
+			query:               "SELECT o.id, c.name FROM JOIN_B.ORDERS o JOIN JOIN_A.CUSTOMERS c ON o.customer_id = c.id ORDER BY o.id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{int64(1), "ana"}, {int64(2), "ana"}, {int64(3), "bob"}},
+		},
+		{
+			name:                "should keep the rows without matches with LEFT JOIN",
+			query:               "SELECT c.name, o.id FROM JOIN_A.CUSTOMERS c LEFT JOIN JOIN_B.ORDERS o ON o.customer_id = c.id ORDER BY c.name, o.id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{"ana", int64(1)}, {"ana", int64(2)}, {"bob", int64(3)}, {"cid", nil}},
+		},
+		{
+			name:                "should page sorted joined rows",
+			query:               "SELECT c.name, o.id FROM JOIN_A.CUSTOMERS c LEFT JOIN JOIN_B.ORDERS o ON o.customer_id = c.id ORDER BY o.total DESC NULLS LAST, c.name LIMIT 2 OFFSET 1",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{"bob", int64(3)}, {"ana", int64(2)}},
+		},
+		{
+			name:                "should keep the rows of the joined table without matches with RIGHT JOIN",
+			query:               "SELECT c.name, o.id FROM JOIN_A.CUSTOMERS c RIGHT OUTER JOIN JOIN_B.ORDERS o ON c.id = o.customer_id ORDER BY o.id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{"ana", int64(1)}, {"ana", int64(2)}, {"bob", int64(3)}, {nil, int64(4)}},
+		},
+		{
+			name:                "should join every pair of rows with CROSS JOIN",
+			query:               "SELECT COUNT(*) FROM JOIN_A.CUSTOMERS CROSS JOIN JOIN_B.ORDERS",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(12)}},
+		},
+		{
+			name:                "should join on conditions without equalities",
+			query:               "SELECT c.name, o.id FROM JOIN_A.CUSTOMERS c JOIN JOIN_B.ORDERS o ON o.total > c.id * 4 ORDER BY c.name, o.id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{"ana", int64(1)}, {"ana", int64(2)}, {"ana", int64(3)}, {"bob", int64(1)}},
+		},
+		{
+			name:  "should select every column of the joined tables with a filter",
+			query: "SELECT * FROM JOIN_A.CUSTOMERS c JOIN JOIN_B.ORDERS o ON c.id = o.customer_id WHERE o.total > 6 ORDER BY o.id",
+			expectedColumnTypes: []ddl.ColumnDataType{
+				ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeFloat,
+			},
+			expectedRows: [][]any{{int64(1), "ana", int64(1), int64(1), 10.0}, {int64(2), "bob", int64(3), int64(2), 7.0}},
+		},
+		{
+			name:                "should aggregate joined rows",
+			query:               "SELECT c.name, SUM(o.total) AS total FROM JOIN_A.CUSTOMERS c JOIN JOIN_B.ORDERS o ON c.id = o.customer_id GROUP BY c.name ORDER BY total DESC",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeFloat},
+			expectedRows:        [][]any{{"ana", 15.5}, {"bob", 7.0}},
+		},
+		{
+			name:          "should return ErrAmbiguousColumn for unqualified columns of many tables",
+			query:         "SELECT id FROM JOIN_A.CUSTOMERS c JOIN JOIN_B.ORDERS o ON c.id = o.customer_id",
+			expectedError: ErrAmbiguousColumn,
+		},
+		{
+			name:          "should return ErrAmbiguousTable for tables joined with the same name",
+			query:         "SELECT name FROM JOIN_A.CUSTOMERS JOIN JOIN_A.CUSTOMERS ON name = name",
+			expectedError: ErrAmbiguousTable,
+		},
+		{
+			name:          "should return ErrColumnNotFound for columns of unknown tables",
+			query:         "SELECT x.id FROM JOIN_A.CUSTOMERS c JOIN JOIN_B.ORDERS o ON c.id = o.customer_id",
+			expectedError: dql.ErrColumnNotFound,
+		},
+		{
+			name:          "should return ErrMisplacedAggregate for aggregates in ON",
+			query:         "SELECT c.name FROM JOIN_A.CUSTOMERS c JOIN JOIN_B.ORDERS o ON COUNT(*) > 1",
+			expectedError: ErrMisplacedAggregate,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if columnTypes, _ := result["ColumnTypes"].([]ddl.ColumnDataType); !slices.Equal(columnTypes, testCase.expectedColumnTypes) {
+				t.Errorf("expected column types %v, got %v", testCase.expectedColumnTypes, columnTypes)
+				return
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQuerySubquery(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE SUB_DB",
+		"CREATE TABLE SUB_DB.CUSTOMERS (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE SUB_DB.ORDERS (id INTEGER PRIMARY KEY, customer_id INTEGER, total FLOAT)",
+		"INSERT INTO SUB_DB.CUSTOMERS (id, name) VALUES (1, 'ana')",
+		"INSERT INTO SUB_DB.CUSTOMERS (id, name) VALUES (2, 'bob')",
+		"INSERT INTO SUB_DB.CUSTOMERS (id, name) VALUES (3, 'cid')",
+		"INSERT INTO SUB_DB.ORDERS (id, customer_id, total) VALUES (1, 1, 10)",
+		"INSERT INTO SUB_DB.ORDERS (id, customer_id, total) VALUES (2, 1, 5.5)",
+		"INSERT INTO SUB_DB.ORDERS (id, customer_id, total) VALUES (3, 2, 7)",
+	)
+
+	testCases := []struct {
+		name                string
+		query               string
+		expectedColumnTypes []ddl.ColumnDataType
+		expectedRows        [][]any
+		expectedError       error
+	}{
+		{
+			name:                "should filter by the values of subqueries with IN",
+			query:               "SELECT name FROM SUB_DB.CUSTOMERS WHERE id IN (SELECT customer_id FROM SUB_DB.ORDERS) ORDER BY name",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"ana"}, {"bob"}},
+		},
+		{
+			name:                "should filter by the values missing from subqueries with NOT IN",
+			query:               "SELECT name FROM SUB_DB.CUSTOMERS WHERE id NOT IN (SELECT customer_id FROM SUB_DB.ORDERS)",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"cid"}},
+		},
+		{
+			name:                "should filter by correlated subqueries with EXISTS",
+			query:               "SELECT name FROM SUB_DB.CUSTOMERS c WHERE EXISTS (SELECT id FROM SUB_DB.ORDERS o WHERE o.customer_id = c.id AND o.total > 6) ORDER BY name",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"ana"}, {"bob"}},
+		},
+		{
+			name:                "should filter by correlated subqueries with NOT EXISTS",
+			query:               "SELECT name FROM SUB_DB.CUSTOMERS c WHERE NOT EXISTS (SELECT id FROM SUB_DB.ORDERS WHERE customer_id = c.id)",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"cid"}},
+		},
+		{
+			name:                "should project correlated scalar subqueries",
+			query:               "SELECT name, (SELECT SUM(total) FROM SUB_DB.ORDERS WHERE customer_id = c.id) AS spent FROM SUB_DB.CUSTOMERS c ORDER BY name",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeFloat},
+			expectedRows:        [][]any{{"ana", 15.5}, {"bob", 7.0}, {"cid", nil}},
+		},
+		{
+			name:                "should compare with scalar subqueries",
+			query:               "SELECT id FROM SUB_DB.ORDERS WHERE total > (SELECT AVG(total) FROM SUB_DB.ORDERS)",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(1)}},
+		},
+		{
+			name:                "should reference the outer queries of nested subqueries",
+			query:               "SELECT name FROM SUB_DB.CUSTOMERS c WHERE EXISTS (SELECT id FROM SUB_DB.ORDERS o WHERE o.customer_id IN (SELECT id FROM SUB_DB.CUSTOMERS WHERE id = c.id AND name <> 'ana'))",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"bob"}},
+		},
+		{
+			name:          "should return ErrSubqueryReturnedManyRows for scalar subqueries with many rows",
+			query:         "SELECT (SELECT id FROM SUB_DB.ORDERS) FROM SUB_DB.CUSTOMERS",
+			expectedError: dql.ErrSubqueryReturnedManyRows,
+		},
+		{
+			name:          "should return ErrSubqueryColumns for IN subqueries with many columns",
+			query:         "SELECT name FROM SUB_DB.CUSTOMERS WHERE id IN (SELECT id, total FROM SUB_DB.ORDERS)",
+			expectedError: ErrSubqueryColumns,
+		},
+		{
+			name:          "should return ErrInvalidDataType for IN subqueries of another type",
+			query:         "SELECT name FROM SUB_DB.CUSTOMERS WHERE name IN (SELECT id FROM SUB_DB.ORDERS)",
+			expectedError: dql.ErrInvalidDataType,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if columnTypes, _ := result["ColumnTypes"].([]ddl.ColumnDataType); !slices.Equal(columnTypes, testCase.expectedColumnTypes) {
+				t.Errorf("expected column types %v, got %v", testCase.expectedColumnTypes, columnTypes)
+				return
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQueryWith(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE CTE_DB",
+		"CREATE TABLE CTE_DB.CATEGORIES (id INTEGER PRIMARY KEY, parent_id INTEGER, name TEXT)",
+		"INSERT INTO CTE_DB.CATEGORIES (id, parent_id, name) VALUES (1, 0, 'root')",
+		"INSERT INTO CTE_DB.CATEGORIES (id, parent_id, name) VALUES (2, 1, 'books')",
+		"INSERT INTO CTE_DB.CATEGORIES (id, parent_id, name) VALUES (3, 1, 'games')",
+		"INSERT INTO CTE_DB.CATEGORIES (id, parent_id, name) VALUES (4, 2, 'novels')",
+		"INSERT INTO CTE_DB.CATEGORIES (id, parent_id, name) VALUES (5, 4, 'poetry')",
+	)
+
+	testCases := []struct {
+		name                string
+		query               string
+		options             []Option
+		expectedColumnTypes []ddl.ColumnDataType
+		expectedRows        [][]any
+		expectedError       error
+	}{
+		{
+			name:                "should read common tables as tables",
+			query:               "WITH children AS (SELECT id, name FROM CTE_DB.CATEGORIES WHERE parent_id = 1) SELECT name FROM children ORDER BY name",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"books"}, {"games"}},
+		},
+		{
+			name:                "should page sorted rows of common tables",
+			query:               "WITH children AS (SELECT id, name FROM CTE_DB.CATEGORIES WHERE id > 1) SELECT name FROM children ORDER BY name DESC LIMIT 2 OFFSET 1",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"novels"}, {"games"}},
+		},
+		{
+			name:                "should join common tables with tables",
+			query:               "WITH counts AS (SELECT parent_id, COUNT(*) AS total FROM CTE_DB.CATEGORIES GROUP BY parent_id) SELECT c.name, k.total FROM CTE_DB.CATEGORIES c JOIN counts k ON k.parent_id = c.id ORDER BY c.name",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{"books", int64(1)}, {"novels", int64(1)}, {"root", int64(2)}},
+		},
+		{
+			name:                "should read common tables in set operations",
+			query:               "WITH top AS (SELECT name FROM CTE_DB.CATEGORIES WHERE id < 3) SELECT name FROM top UNION SELECT name FROM CTE_DB.CATEGORIES WHERE id = 5 ORDER BY name",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"books"}, {"poetry"}, {"root"}},
+		},
+		{
+			name: "should walk hierarchies with recursive common tables",
+			query: "WITH RECURSIVE tree (id, depth) AS (" +
+				"SELECT id, 0 FROM CTE_DB.CATEGORIES WHERE id = 2 " +
+				"UNION ALL SELECT c.id, t.depth + 1 FROM CTE_DB.CATEGORIES c JOIN tree t ON c.parent_id = t.id" +
+				") SELECT id, depth FROM tree ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(2), int64(0)}, {int64(4), int64(1)}, {int64(5), int64(2)}},
+		},
+		{
+			name:                "should run recursive queries until they add no rows",
+			query:               "WITH RECURSIVE n (x) AS (SELECT id FROM CTE_DB.CATEGORIES WHERE id = 1 UNION SELECT x + 1 FROM n WHERE x < 5) SELECT SUM(x) FROM n",
+			options:             []Option{WithMaxRecursiveIterations(5)},
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(15)}},
+		},
+		{
+			name:          "should return ErrRecursionLimitExceeded for recursive queries over the limit",
+			query:         "WITH RECURSIVE n (x) AS (SELECT id FROM CTE_DB.CATEGORIES WHERE id = 1 UNION ALL SELECT x + 1 FROM n) SELECT x FROM n",
+			options:       []Option{WithMaxRecursiveIterations(10)},
+			expectedError: dql.ErrRecursionLimitExceeded,
+		},
+		{
+			name:          "should return ErrCommonTableColumns for column lists of another length",
+			query:         "WITH t (a, b) AS (SELECT id FROM CTE_DB.CATEGORIES) SELECT a FROM t",
+			expectedError: ErrCommonTableColumns,
+		},
+		{
+			name:          "should return ErrIncompatibleQueries for recursive queries of another type",
+			query:         "WITH RECURSIVE t (x) AS (SELECT id FROM CTE_DB.CATEGORIES UNION SELECT c.name FROM CTE_DB.CATEGORIES c JOIN t ON t.x = c.id) SELECT x FROM t",
+			expectedError: ErrIncompatibleQueries,
+		},
+		{
+			name:          "should return ErrTableDoesNotExists for unknown common tables",
+			query:         "WITH t AS (SELECT id FROM CTE_DB.CATEGORIES) SELECT id FROM u",
+			expectedError: ddl.ErrTableDoesNotExists,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query, testCase.options...)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if columnTypes, _ := result["ColumnTypes"].([]ddl.ColumnDataType); !slices.Equal(columnTypes, testCase.expectedColumnTypes) {
+				t.Errorf("expected column types %v, got %v", testCase.expectedColumnTypes, columnTypes)
+				return
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQueryWindow(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE WINDOW_DB",
+		"CREATE TABLE WINDOW_DB.EVENTS (id INTEGER PRIMARY KEY, user_id INTEGER, amount INTEGER, kind TEXT)",
+		"INSERT INTO WINDOW_DB.EVENTS (id, user_id, amount, kind) VALUES (1, 1, 10, 'view')",
+		"INSERT INTO WINDOW_DB.EVENTS (id, user_id, amount, kind) VALUES (2, 1, 20, 'buy')",
+		"INSERT INTO WINDOW_DB.EVENTS (id, user_id, amount, kind) VALUES (3, 1, 20, 'view')",
+		"INSERT INTO WINDOW_DB.EVENTS (id, user_id, amount, kind) VALUES (4, 2, 5, 'view')",
+		"INSERT INTO WINDOW_DB.EVENTS (id, user_id, amount, kind) VALUES (5, 2, 15, 'buy')",
+	)
+
+	testCases := []struct {
+		name                string
+		query               string
+		expectedColumnTypes []ddl.ColumnDataType
+		expectedRows        [][]any
+		expectedError       error
+	}{
+		{
+			name:  "should number and rank the rows of each partition",
+			query: "SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY amount, id), RANK() OVER (PARTITION BY user_id ORDER BY amount), DENSE_RANK() OVER (ORDER BY amount DESC) FROM WINDOW_DB.EVENTS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{
+				ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger,
+			},
+			expectedRows: [][]any{
+				{int64(1), int64(1), int64(1), int64(3)},
+				{int64(2), int64(2), int64(2), int64(1)},
+				{int64(3), int64(3), int64(2), int64(1)},
+				{int64(4), int64(1), int64(1), int64(4)},
+				{int64(5), int64(2), int64(2), int64(2)},
+			},
+		},
+		{
+			name:                "should read the previous and the next rows with LAG and LEAD",
+			query:               "SELECT id, LAG(amount) OVER (PARTITION BY user_id ORDER BY id), LEAD(amount, 1, 0) OVER (PARTITION BY user_id ORDER BY id) FROM WINDOW_DB.EVENTS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger},
+			expectedRows: [][]any{
+				{int64(1), nil, int64(20)},
+				{int64(2), int64(10), int64(20)},
+				{int64(3), int64(20), int64(0)},
+				{int64(4), nil, int64(15)},
+				{int64(5), int64(5), int64(0)},
+			},
+		},
+		{
+			name:                "should sum up to the last peer of each row by default",
+			query:               "SELECT id, SUM(amount) OVER (ORDER BY amount) AS running FROM WINDOW_DB.EVENTS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(1), int64(15)}, {int64(2), int64(70)}, {int64(3), int64(70)}, {int64(4), int64(5)}, {int64(5), int64(30)}},
+		},
+		{
+			name:                "should aggregate the rows of frames and of whole partitions",
+			query:               "SELECT id, SUM(amount) OVER (PARTITION BY user_id ORDER BY id ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING), COUNT(*) OVER (PARTITION BY user_id) FROM WINDOW_DB.EVENTS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger},
+			expectedRows: [][]any{
+				{int64(1), int64(30), int64(3)},
+				{int64(2), int64(50), int64(3)},
+				{int64(3), int64(40), int64(3)},
+				{int64(4), int64(20), int64(2)},
+				{int64(5), int64(20), int64(2)},
+			},
+		},
+		{
+			name:                "should compute windows over the group rows of aggregated selects",
+			query:               "SELECT user_id, SUM(amount), SUM(SUM(amount)) OVER () AS total FROM WINDOW_DB.EVENTS GROUP BY user_id ORDER BY user_id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(1), int64(50), int64(70)}, {int64(2), int64(20), int64(70)}},
+		},
+		{
+			name:                "should sort and page by window functions through their alias",
+			query:               "SELECT id, ROW_NUMBER() OVER (ORDER BY amount DESC) AS n FROM WINDOW_DB.EVENTS WHERE kind = 'view' ORDER BY n LIMIT 2",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(3), int64(1)}, {int64(1), int64(2)}},
+		},
+		{
+			name:          "should return ErrMisplacedWindow for window functions in WHERE",
+			query:         "SELECT id FROM WINDOW_DB.EVENTS WHERE ROW_NUMBER() OVER () = 1",
+			expectedError: ErrMisplacedWindow,
+		},
+		{
+			name:          "should return ErrMisplacedWindow for window functions in aggregates",
+			query:         "SELECT SUM(ROW_NUMBER() OVER ()) FROM WINDOW_DB.EVENTS",
+			expectedError: ErrMisplacedWindow,
+		},
+		{
+			name:          "should return ErrFunctionArguments for ranking functions with arguments",
+			query:         "SELECT RANK(id) OVER (ORDER BY id) FROM WINDOW_DB.EVENTS",
+			expectedError: ErrFunctionArguments,
+		},
+		{
+			name:          "should return ErrInvalidFrame for frames starting after their end",
+			query:         "SELECT SUM(amount) OVER (ROWS BETWEEN UNBOUNDED FOLLOWING AND CURRENT ROW) FROM WINDOW_DB.EVENTS",
+			expectedError: ErrInvalidFrame,
+		},
+		{
+			name:          "should return ErrInvalidDataType for sums of text",
+			query:         "SELECT SUM(kind) OVER () FROM WINDOW_DB.EVENTS",
+			expectedError: dql.ErrInvalidDataType,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if columnTypes, _ := result["ColumnTypes"].([]ddl.ColumnDataType); !slices.Equal(columnTypes, testCase.expectedColumnTypes) {
+				t.Errorf("expected column types %v, got %v", testCase.expectedColumnTypes, columnTypes)
+				return
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQueryFunction(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE FUNCTION_DB",
+		"CREATE TABLE FUNCTION_DB.PRODUCTS (id INTEGER PRIMARY KEY, name TEXT, price FLOAT, stock INTEGER, created_at TIMESTAMP)",
+		"INSERT INTO FUNCTION_DB.PRODUCTS (id, name, price, stock, created_at) VALUES (1, ' Lamp ', 19.99, 3, '2024-02-29 13:45:30')",
+		"INSERT INTO FUNCTION_DB.PRODUCTS (id, name, price, stock, created_at) VALUES (2, 'desk', 120.5, 0, '2024-01-01')",
+	)
+
+	testCases := []struct {
+		name                 string
+		query                string
+		verifyQuery          string
+		expectedAffectedRows int
+		expectedColumnTypes  []ddl.ColumnDataType
+		expectedRows         [][]any
+		expectedError        error
+	}{
+		{
+			name:  "should call text and number functions in projections",
+			query: "SELECT UPPER(TRIM(name)), LENGTH(name), ROUND(price), ABS(stock - 5), MOD(stock, 2) FROM FUNCTION_DB.PRODUCTS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{
+				ddl.ColumnDataTypeText, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeFloat, ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeInteger,
+			},
+			expectedRows: [][]any{
+				{"LAMP", int64(6), float64(20), int64(2), int64(1)},
+				{"DESK", int64(4), float64(121), int64(5), int64(0)},
+			},
+		},
+		{
+			name:                "should truncate and extract fields of timestamps",
+			query:               "SELECT EXTRACT(MONTH FROM created_at), DATE_TRUNC('month', created_at) FROM FUNCTION_DB.PRODUCTS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeTimestamp},
+			expectedRows: [][]any{
+				{int64(2), time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+				{int64(1), time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:                "should call functions in WHERE",
+			query:               "SELECT id FROM FUNCTION_DB.PRODUCTS WHERE LOWER(TRIM(name)) = 'lamp'",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(1)}},
+		},
+		{
+			name:                "should evaluate CASE to the result of the first matching WHEN",
+			query:               "SELECT id, CASE WHEN stock = 0 THEN 'sold out' WHEN stock < 5 THEN 'few' ELSE 'many' END AS availability FROM FUNCTION_DB.PRODUCTS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{int64(1), "few"}, {int64(2), "sold out"}},
+		},
+		{
+			name:                "should widen integers mixed with floats",
+			query:               "SELECT COALESCE(stock, price), NULLIF(stock, 0) FROM FUNCTION_DB.PRODUCTS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeFloat, ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{float64(3), int64(3)}, {float64(0), nil}},
+		},
+		{
+			name:                 "should update columns with expressions of the row",
+			query:                "UPDATE FUNCTION_DB.PRODUCTS SET name = UPPER(TRIM(name)), price = ROUND(price * 2, 1), stock = stock + 1 WHERE id = 1",
+			verifyQuery:          "SELECT name, price, stock FROM FUNCTION_DB.PRODUCTS WHERE id = 1",
+			expectedAffectedRows: 1,
+			expectedRows:         [][]any{{"LAMP", float64(40), int64(4)}},
+		},
+		{
+			name:                 "should widen integers set to FLOAT columns",
+			query:                "UPDATE FUNCTION_DB.PRODUCTS SET price = stock WHERE id = 2",
+			verifyQuery:          "SELECT price FROM FUNCTION_DB.PRODUCTS WHERE id = 2",
+			expectedAffectedRows: 1,
+			expectedRows:         [][]any{{float64(0)}},
+		},
+		{
+			name:          "should return ErrInvalidDataType for floats set to INTEGER columns",
+			query:         "UPDATE FUNCTION_DB.PRODUCTS SET stock = price",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for arguments of another type",
+			query:         "SELECT UPPER(stock) FROM FUNCTION_DB.PRODUCTS",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for CASE results of different types",
+			query:         "SELECT CASE WHEN stock = 0 THEN 'none' ELSE stock END FROM FUNCTION_DB.PRODUCTS",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrFunctionArguments for missing arguments",
+			query:         "SELECT SUBSTR(name) FROM FUNCTION_DB.PRODUCTS",
+			expectedError: ErrFunctionArguments,
+		},
+		{
+			name:          "should return ErrUnsupportedStatement for unknown functions",
+			query:         "SELECT REVERSE(name) FROM FUNCTION_DB.PRODUCTS",
+			expectedError: ErrUnsupportedStatement,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if testCase.verifyQuery != "" {
+				if result["AffectedRows"] != testCase.expectedAffectedRows {
+					t.Errorf("expected %d affected rows, got %v", testCase.expectedAffectedRows, result["AffectedRows"])
+					return
+				}
+
+				result, err = testPlannerExecuteQuery(store, testCase.verifyQuery)
+				if err != nil {
+					t.Errorf("not expected error, got %s", err)
+					return
+				}
+			} else if columnTypes, _ := result["ColumnTypes"].([]ddl.ColumnDataType); !slices.Equal(columnTypes, testCase.expectedColumnTypes) {
+				t.Errorf("expected column types %v, got %v", testCase.expectedColumnTypes, columnTypes)
+				return
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQueryTypes(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE TYPES_DB",
+		"CREATE TABLE TYPES_DB.EVENTS (id INTEGER PRIMARY KEY, amount FLOAT, label TEXT, at TIMESTAMP)",
+		"INSERT INTO TYPES_DB.EVENTS (id, amount, label, at) VALUES (1, 2.5, '10', '2024-03-01T10:00:00.123456789+02:00')",
+		"INSERT INTO TYPES_DB.EVENTS (id, amount, label, at) VALUES (2, 7, 'x', '2023-12-31')",
+		"CREATE TABLE TYPES_DB.TICKS (at TIMESTAMP PRIMARY KEY, value INTEGER)",
+		"INSERT INTO TYPES_DB.TICKS (at, value) VALUES ('2024-01-02T05:04:05+02:00', 1)",
+	)
+
+	testCases := []struct {
+		name                string
+		query               string
+		expectedColumnTypes []ddl.ColumnDataType
+		expectedRows        [][]any
+		expectedError       error
+	}{
+		{
+			name:                "should keep the zone and nanoseconds of timestamps",
+			query:               "SELECT CAST(at AS TEXT) FROM TYPES_DB.EVENTS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeText},
+			expectedRows:        [][]any{{"2024-03-01T10:00:00.123456789+02:00"}, {"2023-12-31T00:00:00Z"}},
+		},
+		{
+			name:                "should compare timestamps as instants across zones",
+			query:               "SELECT id FROM TYPES_DB.EVENTS WHERE at = '2024-03-01 08:00:00.123456789'",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(1)}},
+		},
+		{
+			name:                "should find rows by TIMESTAMP primary keys in any zone",
+			query:               "SELECT value FROM TYPES_DB.TICKS WHERE at = '2024-01-02T03:04:05Z'",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(1)}},
+		},
+		{
+			name:                "should compare timestamps with casted texts",
+			query:               "SELECT id FROM TYPES_DB.EVENTS WHERE at > CAST('2024-01-01' AS TIMESTAMP)",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(1)}},
+		},
+		{
+			name:                "should widen integers inserted into FLOAT columns",
+			query:               "SELECT amount FROM TYPES_DB.EVENTS WHERE id = 2",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeFloat},
+			expectedRows:        [][]any{{float64(7)}},
+		},
+		{
+			name:                "should widen INTEGER columns compared with FLOAT values",
+			query:               "SELECT id FROM TYPES_DB.EVENTS WHERE id < 1.5 OR id BETWEEN 1.9 AND 2.1 ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(1)}, {int64(2)}},
+		},
+		{
+			name:                "should widen INTEGER primary keys compared with FLOAT values",
+			query:               "SELECT id FROM TYPES_DB.EVENTS WHERE id = 2.0",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(2)}},
+		},
+		{
+			name:                "should cast between numbers and texts",
+			query:               "SELECT CAST(amount AS INTEGER), CAST(id AS TEXT), CAST('12.5' AS FLOAT) FROM TYPES_DB.EVENTS ORDER BY id",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger, ddl.ColumnDataTypeText, ddl.ColumnDataTypeFloat},
+			expectedRows:        [][]any{{int64(3), "1", 12.5}, {int64(7), "2", 12.5}},
+		},
+		{
+			name:                "should compare casted columns",
+			query:               "SELECT id FROM TYPES_DB.EVENTS WHERE CAST(label AS TEXT) = '10' AND CAST(amount AS INTEGER) < 5",
+			expectedColumnTypes: []ddl.ColumnDataType{ddl.ColumnDataTypeInteger},
+			expectedRows:        [][]any{{int64(1)}},
+		},
+		{
+			name:          "should return ErrInvalidDataType for texts of labels that are not integers",
+			query:         "SELECT CAST(label AS INTEGER) FROM TYPES_DB.EVENTS",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for integers inserted into TIMESTAMP columns",
+			query:         "INSERT INTO TYPES_DB.EVENTS (id, amount, label, at) VALUES (3, 1.0, 'y', 1709280000)",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for texts that are not timestamps",
+			query:         "INSERT INTO TYPES_DB.EVENTS (id, amount, label, at) VALUES (3, 1.0, 'y', '01/03/2024')",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for floats inserted into INTEGER columns",
+			query:         "INSERT INTO TYPES_DB.EVENTS (id, amount, label, at) VALUES (3.5, 1.0, 'y', '2024-01-01')",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrValueOutOfRange for integers inserted into FLOAT columns that are not exactly a float",
+			query:         "INSERT INTO TYPES_DB.EVENTS (id, amount, label, at) VALUES (3, 9007199254740993, 'y', '2024-01-01')",
+			expectedError: ddl.ErrValueOutOfRange,
+		},
+		{
+			name:          "should return ErrValueOutOfRange for integers set to FLOAT columns that are not exactly a float",
+			query:         "UPDATE TYPES_DB.EVENTS SET amount = id + 9007199254740992 WHERE id = 1",
+			expectedError: ddl.ErrValueOutOfRange,
+		},
+		{
+			name:          "should return ErrInvalidDataType for casts of timestamps to numbers",
+			query:         "SELECT CAST(at AS INTEGER) FROM TYPES_DB.EVENTS",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrInvalidDataType for literals that can not be casted",
+			query:         "SELECT CAST('x' AS INTEGER) FROM TYPES_DB.EVENTS",
+			expectedError: dql.ErrInvalidDataType,
+		},
+		{
+			name:          "should return ErrUnsupportedDataType for casts to unknown types",
+			query:         "SELECT CAST(id AS BLOB) FROM TYPES_DB.EVENTS",
+			expectedError: ErrUnsupportedDataType,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if columnTypes, _ := result["ColumnTypes"].([]ddl.ColumnDataType); !slices.Equal(columnTypes, testCase.expectedColumnTypes) {
+				t.Errorf("expected column types %v, got %v", testCase.expectedColumnTypes, columnTypes)
+				return
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQueryNull(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE NULL_DB",
+		"CREATE TABLE NULL_DB.ITEMS (id INTEGER PRIMARY KEY, name TEXT NOT NULL, price FLOAT, code TEXT UNIQUE)",
+		"INSERT INTO NULL_DB.ITEMS (id, name, price, code) VALUES (1, 'lamp', 19.5, 'a')",
+		"INSERT INTO NULL_DB.ITEMS (id, name) VALUES (2, 'desk')",
+		"INSERT INTO NULL_DB.ITEMS (id, name, price, code) VALUES (3, 'chair', NULL, NULL)",
+	)
+
+	testCases := []struct {
+		name                 string
+		query                string
+		verifyQuery          string
+		expectedAffectedRows int
+		expectedRows         [][]any
+		expectedError        error
+	}{
+		{
+			name:         "should store omitted columns as NULL",
+			query:        "SELECT * FROM NULL_DB.ITEMS WHERE id = 2",
+			expectedRows: [][]any{{int64(2), "desk", nil, nil}},
+		},
+		{
+			name:         "should match NULL values with IS NULL",
+			query:        "SELECT id FROM NULL_DB.ITEMS WHERE price IS NULL ORDER BY id",
+			expectedRows: [][]any{{int64(2)}, {int64(3)}},
+		},
+		{
+			name:         "should match values with IS NOT NULL",
+			query:        "SELECT id FROM NULL_DB.ITEMS WHERE code IS NOT NULL",
+			expectedRows: [][]any{{int64(1)}},
+		},
+		{
+			name:         "should not match NULL values with negated comparisons",
+			query:        "SELECT id FROM NULL_DB.ITEMS WHERE NOT price > 100",
+			expectedRows: [][]any{{int64(1)}},
+		},
+		{
+			name:         "should not match comparisons with NULL",
+			query:        "SELECT id FROM NULL_DB.ITEMS WHERE price = NULL OR code <> NULL",
+			expectedRows: nil,
+		},
+		{
+			name:         "should sort by the index of columns with NULL values",
+			query:        "SELECT id FROM NULL_DB.ITEMS ORDER BY code DESC LIMIT 2",
+			expectedRows: [][]any{{int64(1)}, {int64(3)}},
+		},
+		{
+			name:         "should type NULL with CAST",
+			query:        "SELECT COALESCE(CAST(NULL AS FLOAT), price) FROM NULL_DB.ITEMS WHERE id = 1",
+			expectedRows: [][]any{{19.5}},
+		},
+		{
+			name:         "should compute operations with NULL as NULL",
+			query:        "SELECT NULL + id, NULL || name, -NULL, UPPER(NULL) FROM NULL_DB.ITEMS WHERE id = 1",
+			expectedRows: [][]any{{nil, nil, nil, nil}},
+		},
+		{
+			name:         "should type NULL results of CASE by the other results",
+			query:        "SELECT CASE WHEN id = 2 THEN NULL ELSE 1 END, CASE WHEN id = 2 THEN NULL ELSE price END FROM NULL_DB.ITEMS ORDER BY id",
+			expectedRows: [][]any{{int64(1), 19.5}, {nil, nil}, {int64(1), nil}},
+		},
+		{
+			name:         "should type NULL arguments of COALESCE by the other arguments",
+			query:        "SELECT COALESCE(price, NULL), COALESCE(NULL, id), COALESCE(NULL, NULL) FROM NULL_DB.ITEMS ORDER BY id",
+			expectedRows: [][]any{{19.5, int64(1), nil}, {nil, int64(2), nil}, {nil, int64(3), nil}},
+		},
+		{
+			name:          "should return ErrNotNullConstraintViolation for omitted NOT NULL columns",
+			query:         "INSERT INTO NULL_DB.ITEMS (id, price) VALUES (4, 1.5)",
+			expectedError: dml.ErrNotNullConstraintViolation,
+		},
+		{
+			name:          "should return ErrNotNullConstraintViolation for NULL primary keys",
+			query:         "INSERT INTO NULL_DB.ITEMS (id, name) VALUES (NULL, 'sofa')",
+			expectedError: dml.ErrNotNullConstraintViolation,
+		},
+		{
+			name:          "should return ErrNotNullConstraintViolation for NOT NULL columns set to NULL",
+			query:         "UPDATE NULL_DB.ITEMS SET name = NULL WHERE id = 1",
+			expectedError: dml.ErrNotNullConstraintViolation,
+		},
+		{
+			name:          "should return ErrUniqueConstraintViolation for repeated values that are not NULL",
+			query:         "INSERT INTO NULL_DB.ITEMS (id, name, code) VALUES (4, 'sofa', 'a')",
+			expectedError: dml.ErrUniqueConstraintViolation,
+		},
+		{
+			name:                 "should set columns to NULL",
+			query:                "UPDATE NULL_DB.ITEMS SET price = NULL, code = NULL WHERE id = 1",
+			verifyQuery:          "SELECT id FROM NULL_DB.ITEMS WHERE price IS NULL AND code IS NULL ORDER BY id",
+			expectedAffectedRows: 1,
+			expectedRows:         [][]any{{int64(1)}, {int64(2)}, {int64(3)}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query)
+			if !errors.Is(err, testCase.expectedError) {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+				return
+			}
+
+			if testCase.expectedError != nil {
+				return
+			}
+
+			if testCase.verifyQuery != "" {
+				if result["AffectedRows"] != testCase.expectedAffectedRows {
+					t.Errorf("expected %d affected rows, got %v", testCase.expectedAffectedRows, result["AffectedRows"])
+					return
+				}
+
+				result, err = testPlannerExecuteQuery(store, testCase.verifyQuery)
+				if err != nil {
+					t.Errorf("not expected error, got %s", err)
+					return
+				}
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
+
+func TestExecuteQueryKeywordNames(t *testing.T) {
+	store := testPlannerMockStorage(
+		t,
+		"CREATE DATABASE KEYWORD_DB",
+		"CREATE TABLE KEYWORD_DB.SETTINGS (key TEXT PRIMARY KEY, value INTEGER, first INTEGER, last INTEGER)",
+		"INSERT INTO KEYWORD_DB.SETTINGS (key, value, first, last) VALUES ('a', 1, 10, 20)",
+		"INSERT INTO KEYWORD_DB.SETTINGS (key, value, first, last) VALUES ('b', 2, 30, 5)",
+	)
+
+	testCases := []struct {
+		name         string
+		query        string
+		expectedRows [][]any
+	}{
+		{
+			name:         "should use non reserved keywords as column names",
+			query:        "SELECT key FROM KEYWORD_DB.SETTINGS WHERE key <> 'a'",
+			expectedRows: [][]any{{"b"}},
+		},
+		{
+			name:         "should sort by columns named FIRST and LAST",
+			query:        "SELECT key, first FROM KEYWORD_DB.SETTINGS ORDER BY last DESC NULLS LAST",
+			expectedRows: [][]any{{"a", int64(10)}, {"b", int64(30)}},
+		},
+		{
+			name:         "should alias columns as LEFT and RIGHT before joins",
+			query:        "SELECT l.key AS left, r.key AS right FROM KEYWORD_DB.SETTINGS AS l LEFT JOIN KEYWORD_DB.SETTINGS AS r ON l.first < r.first ORDER BY l.key",
+			expectedRows: [][]any{{"a", "b"}, {"b", nil}},
+		},
+		{
+			name:         "should use ROW and CURRENT as aliases next to frames",
+			query:        "SELECT key AS row, SUM(value) OVER (ORDER BY key ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS current FROM KEYWORD_DB.SETTINGS ORDER BY key",
+			expectedRows: [][]any{{"a", int64(1)}, {"b", int64(3)}},
+		},
+		{
+			name:         "should use END as an alias of CASE",
+			query:        "SELECT CASE WHEN last > first THEN last ELSE first END AS end FROM KEYWORD_DB.SETTINGS ORDER BY key",
+			expectedRows: [][]any{{int64(20)}, {int64(30)}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := testPlannerExecuteQuery(store, testCase.query)
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			rows, _ := result["Rows"].([][]any)
+			if !slices.EqualFunc(rows, testCase.expectedRows, slices.Equal) {
+				t.Errorf("expected rows %v, got %v", testCase.expectedRows, rows)
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gustapinto/go-sql-store/pkg/executor"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
+)
+
+var (
+	ErrIncompatibleQueries = errors.New("queries have incompatible columns")
+)
+
+func planSetOperation(tx *storage.Tx, ast *parser.AST, with *commonTables) ([]executor.Action, error) {
+	query, err := queryForSetOperation(tx, ast, nil, with)
+	if err != nil {
+		return nil, err
+	}
+
+	action := executor.QueryAction().WithParams(executor.Params{
+		executor.QueryParamsQueryKey: query,
+	})
+
+	return []executor.Action{action}, nil
+}
+
+// queryForNode Returns the query of a select or a set operation, which is a subquery if it
+// has an outer query, and can read the common tables of the WITH clauses around it
+func queryForNode(tx *storage.Tx, node *parser.AST, outer *outerQuery, with *commonTables) (dql.Query, error) {
+	switch node.Type {
+	case parser.TypeSelectOperation:
+		return queryForSelect(tx, node, outer, with)
+
+	case parser.TypeSetOperation:
+		return queryForSetOperation(tx, node, outer, with)
+	}
+
+	return nil, malformedASTError(node, "query")
+}
+
+// queryForSetOperation Returns the set operation between its queries, which must have the
+// same number of columns, each with values that can be compared with each other
+func queryForSetOperation(tx *storage.Tx, ast *parser.AST, outer *outerQuery, with *commonTables) (dql.SetOperation, error) {
+	commonTables, with, err := withForNode(tx, ast, outer, with)
+	if err != nil {
+		return dql.SetOperation{}, err
+	}
+
+	var queries []dql.Query
+	for _, operand := range queryOperands(ast) {
+		query, err := queryForNode(tx, operand, outer, with)
+		if err != nil {
+			return dql.SetOperation{}, err
+		}
+
+		queries = append(queries, query)
+	}
+
+	if len(queries) != 2 {
+		return dql.SetOperation{}, malformedASTError(ast, "two queries")
+	}
+
+	left, right := queries[0].Columns(), queries[1].Columns()
+	if len(left) != len(right) {
+		return dql.SetOperation{}, fmt.Errorf("%w: %s between queries with %d and %d columns", ErrIncompatibleQueries, ast.Value, len(left), len(right))
+	}
+
+	for i := range left {
+		if !dataTypesAreComparable(left[i].DataType, right[i].DataType) {
+			return dql.SetOperation{}, fmt.Errorf("%w: %s between %s and %s columns", ErrIncompatibleQueries, ast.Value, left[i].DataType, right[i].DataType)
+		}
+	}
+
+	operator, all := strings.CutSuffix(ast.Value, " ALL")
+
+	query := dql.SetOperation{
+		With:     commonTables,
+		Operator: dql.SetOperator(operator),
+		All:      all,
+		Left:     queries[0],
+		Right:    queries[1],
+	}
+
+	if query.Operator != dql.SetOperatorUnion && query.Operator != dql.SetOperatorIntersect && query.Operator != dql.SetOperatorExcept {
+		return dql.SetOperation{}, fmt.Errorf("%w: %s", ErrUnsupportedStatement, ast.Value)
+	}
+
+	orders, err := ordersForResultColumns(query.Columns(), ast.FirstChildOfType(parser.TypeOrderBy))
+	if err != nil {
+		return dql.SetOperation{}, err
+	}
+
+	limit, offset, err := pageForQuery(ast)
+	if err != nil {
+		return dql.SetOperation{}, err
+	}
+
+	query.Orders, query.Limit, query.Offset = orders, limit, offset
+	return query, nil
+}
+
+// ordersForResultColumns Returns the orders of the ORDER BY items of a query that sorts its
+// result, which reference its columns by name
+func ordersForResultColumns(columns []dql.ResultColumn, orderBy *parser.AST) ([]dql.Order, error) {
+	var orders []dql.Order
+	if orderBy == nil {
+		return orders, nil
+	}
+
+	for _, item := range orderBy.ChildrenOfType(parser.TypeOrderItem) {
+		columnNode := item.FirstChildOfType(parser.TypeColumn)
+		if columnNode == nil {
+			return nil, malformedASTError(item, parser.TypeColumn)
+		}
+
+		index := slices.IndexFunc(columns, func(column dql.ResultColumn) bool {
+			return stringutils.EqualsIgnoreCase(column.Name, columnNode.Value)
+		})
+		if index < 0 {
+			return nil, fmt.Errorf("%w: %s", dql.ErrColumnNotFound, columnNode.Value)
+		}
+
+		orders = append(orders, orderForItem(item, columns[index].Name))
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,224 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gustapinto/go-sql-store/pkg/executor"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dml"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
+)
+
+var (
+	ErrColumnValueCountMismatch = errors.New("column and value counts does not match")
+)
+
+func tableForStatement(tx *storage.Tx, ast *parser.AST) (*ddl.Table, error) {
+	database, name, err := tableNames(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	return ddl.GetTable(tx, database, name)
+}
+
+func columnForName(table *ddl.Table, name string) (ddl.Column, error) {
+	for _, column := range table.Columns {
+		if stringutils.EqualsIgnoreCase(column.Name, name) {
+			return column, nil
+		}
+	}
+
+	return ddl.Column{}, fmt.Errorf("%w: %s", dql.ErrColumnNotFound, name)
+}
+
+func literalValue(literal *parser.AST, column ddl.Column) (any, error) {
+	var value any
+	var err error
+
+	switch literal.Type {
+	case parser.TypeNullLiteral:
+		// NULL is a value of any type
+		return nil, nil
+
+	case parser.TypeStringLiteral:
+		if column.DataType == ddl.ColumnDataTypeTimestamp {
+			value, err = ddl.ParseTimestamp(literal.Value)
+		} else {
+			value = literal.Value
+		}
+
+	case parser.TypeIntegerLiteral:
+		var integer int64
+		integer, err = strconv.ParseInt(literal.Value, 10, 64)
+		if err == nil && column.DataType == ddl.ColumnDataTypeFloat {
+			// Only integers that are exactly a float are FLOAT values
+			return ddl.ValueForDataType(integer, column.DataType)
+		}
+
+		value = integer
+
+	case parser.TypeFloatLiteral:
+		value, err = strconv.ParseFloat(literal.Value, 64)
+
+	default:
+		return nil, malformedASTError(literal, "literal")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", dql.ErrInvalidDataType, err.Error())
+	}
+
+	if !ddl.ValueHasCorrectTypeForColumn(value, column) {
+		return nil, fmt.Errorf("%w: %s is not a valid %s value for column %s", dql.ErrInvalidDataType, literal.Value, column.DataType, column.Name)
+	}
+
+	return value, nil
+}
+
+func valueForColumn(valueNode *parser.AST, column ddl.Column) (any, error) {
+	if valueNode.Type != parser.TypeValue || len(valueNode.Children) != 1 {
+		return nil, malformedASTError(valueNode, "literal")
+	}
+
+	return literalValue(valueNode.Children[0], column)
+}
+
+func planInsert(tx *storage.Tx, ast *parser.AST) ([]executor.Action, error) {
+	table, err := tableForStatement(tx, ast)
+	if err != nil {
+		return nil, err
+	}
+
+	tableDefinition := ast.FirstChildOfType(parser.TypeTableDefinition)
+
+	columnList := tableDefinition.FirstChildOfType(parser.TypeColumnList)
+	if columnList == nil {
+		return nil, malformedASTError(tableDefinition, parser.TypeColumnList)
+	}
+
+	valueList := tableDefinition.FirstChildOfType(parser.TypeValueList)
+	if valueList == nil {
+		return nil, malformedASTError(tableDefinition, parser.TypeValueList)
+	}
+
+	if len(columnList.Children) != len(valueList.Children) {
+		return nil, ErrColumnValueCountMismatch
+	}
+
+	row := dml.Row{
+		Database: table.Database,
+		Table:    table.Name,
+	}
+
+	for i, columnNode := range columnList.Children {
+		column, err := columnForName(table, columnNode.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, existing := range row.Columns {
+			if stringutils.EqualsIgnoreCase(existing.Definition.Name, column.Name) {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicatedColumn, column.Name)
+			}
+		}
+
+		value, err := valueForColumn(valueList.Children[i], column)
+		if err != nil {
+			return nil, err
+		}
+
+		row.Columns = append(row.Columns, dml.Column{
+			Definition: column,
+			Value:      value,
+		})
+	}
+
+	action := executor.InsertAction().WithParams(executor.Params{
+		executor.InsertParamsRowKey: row,
+	})
+
+	return []executor.Action{action}, nil
+}
+
+func planUpdate(tx *storage.Tx, ast *parser.AST, with *commonTables) ([]executor.Action, error) {
+	table, err := tableForStatement(tx, ast)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := ast.FirstChildOfType(parser.TypeAssignmentList)
+	if assignments == nil {
+		return nil, malformedASTError(ast, parser.TypeAssignmentList)
+	}
+
+	scope := tableScope(tx, table, with)
+
+	columns := make(map[string]dql.Expression, len(assignments.Children))
+	for _, assignment := range assignments.Children {
+		columnNode := assignment.FirstChildOfType(parser.TypeColumn)
+		if columnNode == nil || len(assignment.Children) != 2 {
+			return nil, malformedASTError(assignment, "column and value")
+		}
+
+		column, err := columnForName(table, columnNode.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		typed, err := expressionForNode(scope, assignment.Children[1], &column)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case typed.dataType == column.DataType, typed.dataType == nullDataType:
+		case typed.dataType == ddl.ColumnDataTypeInteger && column.DataType == ddl.ColumnDataTypeFloat:
+			typed.expression = dql.Widen{Expression: typed.expression}
+		default:
+			return nil, fmt.Errorf("%w: column %s is %s, got %s", dql.ErrInvalidDataType, column.Name, column.DataType, typed.dataType)
+		}
+
+		columns[strings.ToUpper(column.Name)] = typed.expression
+	}
+
+	where, err := expressionForWhere(scope, ast.FirstChildOfType(parser.TypeWhere))
+	if err != nil {
+		return nil, err
+	}
+
+	action := executor.UpdateAction().WithParams(executor.Params{
+		executor.UpdateParamsDatabaseKey:  table.Database,
+		executor.UpdateParamsTableNameKey: table.Name,
+		executor.UpdateParamsWhereKey:     where,
+		executor.UpdateParamsColumnsKey:   columns,
+	})
+
+	return []executor.Action{action}, nil
+}
+
+func planDelete(tx *storage.Tx, ast *parser.AST, with *commonTables) ([]executor.Action, error) {
+	table, err := tableForStatement(tx, ast)
+	if err != nil {
+		return nil, err
+	}
+
+	where, err := expressionForWhere(tableScope(tx, table, with), ast.FirstChildOfType(parser.TypeWhere))
+	if err != nil {
+		return nil, err
+	}
+
+	action := executor.DeleteAction().WithParams(executor.Params{
+		executor.DeleteParamsDatabaseKey:  table.Database,
+		executor.DeleteParamsTableNameKey: table.Name,
+		executor.DeleteParamsWhereKey:     where,
+	})
+
+	return []executor.Action{action}, nil
+}
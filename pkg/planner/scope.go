@@ -0,0 +1,286 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
+)
+
+var (
+	ErrAmbiguousColumn = errors.New("column reference is ambiguous")
+	ErrAmbiguousTable  = errors.New("table name specified more than once")
+	ErrSubqueryColumns = errors.New("subquery must return a single column")
+)
+
+// scope The columns the expressions of a statement can reference
+type scope struct {
+	tx     *storage.Tx
+	tables []scopeTable
+
+	// with The common tables the subqueries of the statement can read
+	with *commonTables
+
+	// outer The query around the statement if it is a subquery, whose columns can also be
+	// referenced
+	outer *outerQuery
+
+	// qualified Whether the rows are joined, so the names of their columns are qualified by
+	// the alias of their table, as in ALIAS.COLUMN
+	qualified bool
+
+	// grouping The grouped columns and the aggregates of an aggregated select, whose select
+	// list, HAVING and ORDER BY can only reference the grouped columns outside of aggregates
+	grouping *grouping
+
+	// windowing The window functions of the select list of a select, the only place they
+	// can be called
+	windowing *windowing
+}
+
+// scopeTable A table of a scope, with the alias that qualifies its columns, which is its
+// name if it has no alias. The table of a common table has its source
+type scopeTable struct {
+	table  *ddl.Table
+	source *dql.CommonTable
+	alias  string
+}
+
+// outerQuery The query around a subquery, with the row the subquery runs for. The subquery
+// is correlated if it references its columns
+type outerQuery struct {
+	scope      *scope
+	row        *dql.OuterRow
+	correlated bool
+}
+
+func tableScope(tx *storage.Tx, table *ddl.Table, with *commonTables) *scope {
+	return &scope{tx: tx, with: with, tables: []scopeTable{{table: table, alias: strings.ToUpper(table.Name)}}}
+}
+
+// scopeForFrom Returns the scope of the tables of a FROM and the joins of the tables after
+// the first one, whose conditions can reference the tables joined before them
+func scopeForFrom(tx *storage.Tx, from *parser.AST, outer *outerQuery, with *commonTables) (*scope, []dql.Join, error) {
+	joinNodes := from.ChildrenOfType(parser.TypeJoin)
+	fromScope := &scope{tx: tx, with: with, outer: outer, qualified: len(joinNodes) > 0}
+	if err := fromScope.addTable(from); err != nil {
+		return nil, nil, err
+	}
+
+	joins := make([]dql.Join, 0, len(joinNodes))
+	for _, joinNode := range joinNodes {
+		if err := fromScope.addTable(joinNode); err != nil {
+			return nil, nil, err
+		}
+
+		table := fromScope.tables[len(fromScope.tables)-1]
+		join := dql.Join{
+			Type:     dql.JoinType(joinNode.Value),
+			Database: table.table.Database,
+			Table:    table.table.Name,
+			Source:   table.source,
+			Alias:    table.alias,
+		}
+
+		if on := joinNode.FirstChildOfType(parser.TypeOn); on != nil {
+			if len(on.Children) != 1 {
+				return nil, nil, malformedASTError(on, "condition")
+			}
+
+			condition, err := conditionForNode(fromScope, on.Children[0])
+			if err != nil {
+				return nil, nil, err
+			}
+
+			join.On = condition
+		} else if join.Type != dql.JoinTypeCross {
+			return nil, nil, malformedASTError(joinNode, parser.TypeOn)
+		}
+
+		joins = append(joins, join)
+	}
+
+	return fromScope, joins, nil
+}
+
+// addTable Adds the table of the TABLE_DEFINITION of a node to the scope, which is a common
+// table if it has no database
+func (s *scope) addTable(node *parser.AST) error {
+	tableDefinition := node.FirstChildOfType(parser.TypeTableDefinition)
+	if tableDefinition == nil {
+		return malformedASTError(node, parser.TypeTableDefinition)
+	}
+
+	var table *ddl.Table
+	var source *dql.CommonTable
+	if tableDefinition.FirstChildOfType(parser.TypeDatabase) != nil {
+		var err error
+		if table, err = tableForStatement(s.tx, node); err != nil {
+			return err
+		}
+	} else {
+		name, err := childValue(tableDefinition, parser.TypeTable)
+		if err != nil {
+			return err
+		}
+
+		if source = s.with.lookup(name); source == nil {
+			return fmt.Errorf("%w: %s", ddl.ErrTableDoesNotExists, name)
+		}
+
+		table = source.Definition()
+	}
+
+	alias := strings.ToUpper(table.Name)
+	if aliasNode := tableDefinition.FirstChildOfType(parser.TypeAlias); aliasNode != nil {
+		alias = strings.ToUpper(aliasNode.Value)
+	}
+
+	for _, existing := range s.tables {
+		if existing.alias == alias {
+			return fmt.Errorf("%w: %s", ErrAmbiguousTable, alias)
+		}
+	}
+
+	s.tables = append(s.tables, scopeTable{table: table, source: source, alias: alias})
+	return nil
+}
+
+// ungrouped Returns the scope of the rows before they are grouped, where window functions
+// can not be called either
+func (s *scope) ungrouped() *scope {
+	ungrouped := *s
+	ungrouped.grouping, ungrouped.windowing = nil, nil
+	return &ungrouped
+}
+
+// unwindowed Returns the scope of the rows a window function is computed over
+func (s *scope) unwindowed() *scope {
+	unwindowed := *s
+	unwindowed.windowing = nil
+	return &unwindowed
+}
+
+// reference Returns the column of a COLUMN node and the expression that reads it. Columns
+// that are not in the scope are looked up in the outer queries, in which case they are
+// read from the row of the outer query and the subqueries up to it are correlated
+func (s *scope) reference(node *parser.AST) (ddl.Column, dql.Expression, error) {
+	column, err := s.columnForNode(node)
+	if err == nil {
+		return column, dql.ColumnRef{Name: column.Name}, nil
+	}
+
+	for inner := s; inner.outer != nil && errors.Is(err, dql.ErrColumnNotFound); inner = inner.outer.scope {
+		inner.outer.correlated = true
+
+		var outerColumn ddl.Column
+		if outerColumn, err = inner.outer.scope.columnForNode(node); err == nil {
+			return outerColumn, dql.OuterRef{Name: outerColumn.Name, Row: inner.outer.row}, nil
+		}
+	}
+
+	return ddl.Column{}, nil, err
+}
+
+// subquery Returns the subquery of a SUBQUERY node
+func (s *scope) subquery(node *parser.AST) (*dql.Subquery, error) {
+	if len(node.Children) != 1 {
+		return nil, malformedASTError(node, "query")
+	}
+
+	outer := &outerQuery{scope: s, row: &dql.OuterRow{}}
+	query, err := queryForNode(s.tx, node.Children[0], outer, s.with)
+	if err != nil {
+		return nil, err
+	}
+
+	if !outer.correlated {
+		return dql.NewSubquery(s.tx, query, nil), nil
+	}
+
+	return dql.NewSubquery(s.tx, query, outer.row), nil
+}
+
+// singleColumnSubquery Returns the subquery of a SUBQUERY node that must return a single
+// column, along with the type of the column
+func (s *scope) singleColumnSubquery(node *parser.AST) (*dql.Subquery, ddl.ColumnDataType, error) {
+	subquery, err := s.subquery(node)
+	if err != nil {
+		return nil, "", err
+	}
+
+	columns := subquery.Columns()
+	if len(columns) != 1 {
+		return nil, "", fmt.Errorf("%w: got %d columns", ErrSubqueryColumns, len(columns))
+	}
+
+	return subquery, columns[0].DataType, nil
+}
+
+// columnForNode Returns the column of a COLUMN node, see [scope.column]
+func (s *scope) columnForNode(node *parser.AST) (ddl.Column, error) {
+	qualifier := ""
+	if table := node.FirstChildOfType(parser.TypeTable); table != nil {
+		qualifier = table.Value
+	}
+
+	return s.column(qualifier, node.Value)
+}
+
+// column Returns the column with the name in the table with the alias, or in any table if
+// there is no alias, in which case a single table can have it. The name of the column is
+// the name it has in the rows, which is qualified if they are joined
+func (s *scope) column(alias, name string) (ddl.Column, error) {
+	reference := name
+	if alias != "" {
+		reference = alias + "." + name
+	}
+
+	var found *ddl.Column
+	for _, table := range s.tables {
+		if alias != "" && !stringutils.EqualsIgnoreCase(table.alias, alias) {
+			continue
+		}
+
+		column, err := columnForName(table.table, name)
+		if err != nil {
+			continue
+		}
+
+		if found != nil {
+			return ddl.Column{}, fmt.Errorf("%w: %s", ErrAmbiguousColumn, reference)
+		}
+
+		if s.qualified {
+			column.Name = table.alias + "." + column.Name
+		}
+
+		found = &column
+	}
+
+	if found == nil {
+		return ddl.Column{}, fmt.Errorf("%w: %s", dql.ErrColumnNotFound, reference)
+	}
+
+	if s.grouping == nil {
+		return *found, nil
+	}
+
+	for _, grouped := range s.grouping.columns {
+		if grouped.Name == found.Name {
+			return *found, nil
+		}
+	}
+
+	return ddl.Column{}, fmt.Errorf("%w: %s", ErrColumnNotGrouped, found.Name)
+}
+
+// unqualifiedName Returns the name of a column without the alias of its table
+func unqualifiedName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
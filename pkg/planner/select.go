@@ -0,0 +1,311 @@
+package planner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gustapinto/go-sql-store/pkg/executor"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
+)
+
+// unnamedColumn The name of the result columns of expressions that are not a column and
+// have no alias
+const unnamedColumn = "?column?"
+
+func planSelect(tx *storage.Tx, ast *parser.AST, with *commonTables) ([]executor.Action, error) {
+	query, err := queryForSelect(tx, ast, nil, with)
+	if err != nil {
+		return nil, err
+	}
+
+	action := executor.SelectAction().WithParams(executor.Params{
+		executor.SelectParamsDatabaseKey:    query.Database,
+		executor.SelectParamsWithKey:        query.With,
+		executor.SelectParamsTableNameKey:   query.Table,
+		executor.SelectParamsSourceKey:      query.Source,
+		executor.SelectParamsAliasKey:       query.Alias,
+		executor.SelectParamsJoinsKey:       query.Joins,
+		executor.SelectParamsWhereKey:       query.Where,
+		executor.SelectParamsProjectionsKey: query.Projections,
+		executor.SelectParamsAggregationKey: query.Aggregation,
+		executor.SelectParamsWindowsKey:     query.Windows,
+		executor.SelectParamsDistinctKey:    query.Distinct,
+		executor.SelectParamsOrdersKey:      query.Orders,
+		executor.SelectParamsLimitKey:       query.Limit,
+		executor.SelectParamsOffsetKey:      query.Offset,
+	})
+
+	return []executor.Action{action}, nil
+}
+
+func queryForSelect(tx *storage.Tx, ast *parser.AST, outer *outerQuery, with *commonTables) (dql.SelectQuery, error) {
+	from := ast.FirstChildOfType(parser.TypeFrom)
+	if from == nil {
+		return dql.SelectQuery{}, malformedASTError(ast, parser.TypeFrom)
+	}
+
+	commonTables, with, err := withForNode(tx, ast, outer, with)
+	if err != nil {
+		return dql.SelectQuery{}, err
+	}
+
+	selectScope, joins, err := scopeForFrom(tx, from, outer, with)
+	if err != nil {
+		return dql.SelectQuery{}, err
+	}
+
+	selectList := ast.FirstChildOfType(parser.TypeSelectList)
+	if selectList == nil {
+		return dql.SelectQuery{}, malformedASTError(ast, parser.TypeSelectList)
+	}
+
+	where, err := expressionForWhere(selectScope, ast.FirstChildOfType(parser.TypeWhere))
+	if err != nil {
+		return dql.SelectQuery{}, err
+	}
+
+	if with.functions.isAggregated(ast) {
+		selectScope.grouping, err = groupingForGroupBy(selectScope, ast.FirstChildOfType(parser.TypeGroupBy))
+		if err != nil {
+			return dql.SelectQuery{}, err
+		}
+	}
+
+	selectScope.windowing = &windowing{}
+	projections, err := projectionsForSelectList(selectScope, selectList)
+	if err != nil {
+		return dql.SelectQuery{}, err
+	}
+
+	windows := selectScope.windowing.windows
+	selectScope.windowing = nil
+
+	having, err := expressionForHaving(selectScope, ast.FirstChildOfType(parser.TypeHaving))
+	if err != nil {
+		return dql.SelectQuery{}, err
+	}
+
+	orders, err := ordersForOrderBy(selectScope, ast.FirstChildOfType(parser.TypeOrderBy), projections)
+	if err != nil {
+		return dql.SelectQuery{}, err
+	}
+
+	limit, offset, err := pageForQuery(ast)
+	if err != nil {
+		return dql.SelectQuery{}, err
+	}
+
+	var aggregation *dql.Aggregation
+	if selectScope.grouping != nil {
+		aggregation = selectScope.grouping.aggregation(having)
+	}
+
+	table := selectScope.tables[0]
+	return dql.SelectQuery{
+		With:        commonTables,
+		Database:    table.table.Database,
+		Table:       table.table.Name,
+		Source:      table.source,
+		Alias:       table.alias,
+		Joins:       joins,
+		Where:       where,
+		Aggregation: aggregation,
+		Windows:     windows,
+		Projections: projections,
+		Distinct:    ast.FirstChildOfType(parser.TypeDistinct) != nil,
+		Orders:      orders,
+		Limit:       limit,
+		Offset:      offset,
+	}, nil
+}
+
+func projectionsForSelectList(scope *scope, selectList *parser.AST) ([]dql.Projection, error) {
+	var projections []dql.Projection
+	for _, item := range selectList.Children {
+		if item.Type == parser.TypeAllColumns {
+			for _, table := range scope.tables {
+				for _, column := range table.table.Columns {
+					column, err := scope.column(table.alias, column.Name)
+					if err != nil {
+						return nil, err
+					}
+
+					projections = append(projections, dql.Projection{
+						Column:     dql.ResultColumn{Name: unqualifiedName(column.Name), DataType: column.DataType},
+						Expression: dql.ColumnRef{Name: column.Name},
+					})
+				}
+			}
+
+			continue
+		}
+
+		projection, err := projectionForNode(scope, item)
+		if err != nil {
+			return nil, err
+		}
+
+		projections = append(projections, projection)
+	}
+
+	return projections, nil
+}
+
+// projectionForNode Returns the projection of an item of the select list, named by its
+// alias, by its column without its table if it is one or by its function in lowercase if
+// it is a call
+func projectionForNode(scope *scope, item *parser.AST) (dql.Projection, error) {
+	name := unnamedColumn
+	if item.Type == parser.TypeAlias {
+		if len(item.Children) != 1 {
+			return dql.Projection{}, malformedASTError(item, "expression")
+		}
+
+		name = item.Value
+		item = item.Children[0]
+	}
+
+	typed, err := expressionForNode(scope, item, nil)
+	if err != nil {
+		return dql.Projection{}, err
+	}
+
+	if typed.dataType == conditionDataType {
+		return dql.Projection{}, fmt.Errorf("%w: expected a value in the select list, got a condition", dql.ErrInvalidDataType)
+	}
+
+	if typed.dataType == nullDataType {
+		// A NULL that is not typed by anything else is projected as TEXT
+		typed.dataType = ddl.ColumnDataTypeText
+	}
+
+	if name == unnamedColumn {
+		switch column, isColumn := typed.expression.(dql.ColumnRef); {
+		case item.Type == parser.TypeColumn && isColumn:
+			name = column.Name
+
+		case item.Type == parser.TypeFunction:
+			name = strings.ToLower(item.Value)
+
+		case item.Type == parser.TypeOver && len(item.Children) > 0:
+			name = strings.ToLower(item.Children[0].Value)
+
+		case item.Type == parser.TypeCase:
+			name = "case"
+
+		case item.Type == parser.TypeCast:
+			name = strings.ToLower(item.Value)
+		}
+	}
+
+	return dql.Projection{
+		Column:     dql.ResultColumn{Name: name, DataType: typed.dataType},
+		Expression: typed.expression,
+	}, nil
+}
+
+func expressionForHaving(scope *scope, having *parser.AST) (dql.Expression, error) {
+	if having == nil {
+		return nil, nil
+	}
+
+	if len(having.Children) != 1 {
+		return nil, malformedASTError(having, "condition")
+	}
+
+	return conditionForNode(scope, having.Children[0])
+}
+
+// ordersForOrderBy Returns the orders of the ORDER BY items, which are either the alias of
+// a projected column or aggregate, or a column of the scope
+func ordersForOrderBy(scope *scope, orderBy *parser.AST, projections []dql.Projection) ([]dql.Order, error) {
+	if orderBy == nil {
+		return []dql.Order{}, nil
+	}
+
+	orders := make([]dql.Order, 0, len(orderBy.Children))
+	for _, item := range orderBy.ChildrenOfType(parser.TypeOrderItem) {
+		columnNode := item.FirstChildOfType(parser.TypeColumn)
+		if columnNode == nil {
+			return nil, malformedASTError(item, parser.TypeColumn)
+		}
+
+		name, err := orderedColumnName(scope, columnNode, projections)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, orderForItem(item, name))
+	}
+
+	return orders, nil
+}
+
+func orderForItem(item *parser.AST, column string) dql.Order {
+	order := dql.Order{
+		Column:     column,
+		Descending: item.Value == "DESC",
+	}
+
+	if nulls := item.FirstChildOfType(parser.TypeNulls); nulls != nil {
+		order.Nulls = dql.NullsOrder(nulls.Value)
+	}
+
+	return order
+}
+
+func orderedColumnName(scope *scope, columnNode *parser.AST, projections []dql.Projection) (string, error) {
+	// Qualified names always reference a column of a table
+	if columnNode.FirstChildOfType(parser.TypeTable) == nil {
+		for _, projection := range projections {
+			if column, isColumn := projection.Expression.(dql.ColumnRef); isColumn && stringutils.EqualsIgnoreCase(projection.Column.Name, columnNode.Value) {
+				return column.Name, nil
+			}
+		}
+	}
+
+	column, err := scope.columnForNode(columnNode)
+	if err != nil {
+		return "", err
+	}
+
+	return column.Name, nil
+}
+
+// pageForQuery Returns the LIMIT and OFFSET of a query, the limit being negative if there
+// is no LIMIT
+func pageForQuery(ast *parser.AST) (int, int, error) {
+	limit := -1
+	if limitNode := ast.FirstChildOfType(parser.TypeLimit); limitNode != nil {
+		var err error
+		if limit, err = countValue(limitNode); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	offset, err := countValue(ast.FirstChildOfType(parser.TypeOffset))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return limit, offset, nil
+}
+
+// countValue Returns the value of a LIMIT or OFFSET node, or zero if there is none
+func countValue(node *parser.AST) (int, error) {
+	if node == nil {
+		return 0, nil
+	}
+
+	count, err := strconv.Atoi(node.Value)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("%w: %s is not a valid %s", dql.ErrInvalidDataType, node.Value, node.Type)
+	}
+
+	return count, nil
+}
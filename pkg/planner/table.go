@@ -0,0 +1,165 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gustapinto/go-sql-store/pkg/executor"
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+	"github.com/gustapinto/go-sql-store/pkg/utils/stringutils"
+)
+
+var (
+	ErrUnsupportedDataType    = errors.New("unsupported data type")
+	ErrDuplicatedColumn       = errors.New("duplicated column")
+	ErrTableWithoutPrimaryKey = errors.New("table must have exactly one primary key column")
+)
+
+func tableNames(ast *parser.AST) (database, table string, err error) {
+	tableDefinition := ast.FirstChildOfType(parser.TypeTableDefinition)
+	if tableDefinition == nil {
+		return "", "", malformedASTError(ast, parser.TypeTableDefinition)
+	}
+
+	database, err = childValue(tableDefinition, parser.TypeDatabase)
+	if err != nil {
+		return "", "", err
+	}
+
+	table, err = childValue(tableDefinition, parser.TypeTable)
+	if err != nil {
+		return "", "", err
+	}
+
+	return database, table, nil
+}
+
+func defaultConstraintName(column string, constraintType ddl.ConstraintDataType) string {
+	switch constraintType {
+	case ddl.ConstraintPrimaryKey:
+		return strings.ToLower(column) + "_pkey"
+
+	case ddl.ConstraintUnique:
+		return strings.ToLower(column) + "_unique"
+	}
+
+	return strings.ToLower(column) + "_" + strings.ToLower(string(constraintType))
+}
+
+func columnFromDefinition(columnDefinition *parser.AST) (ddl.Column, error) {
+	dataType, err := childValue(columnDefinition, parser.TypeDataType)
+	if err != nil {
+		return ddl.Column{}, err
+	}
+
+	column := ddl.Column{
+		Name:     strings.ToUpper(columnDefinition.Value),
+		DataType: ddl.ColumnDataType(dataType),
+	}
+
+	if !ddl.IsColumnDataTypeSupported(column.DataType) {
+		return ddl.Column{}, fmt.Errorf("%w: %s", ErrUnsupportedDataType, dataType)
+	}
+
+	for _, constraintNode := range columnDefinition.ChildrenOfType(parser.TypeConstraint) {
+		constraint := ddl.Constraint{
+			Type: ddl.ConstraintDataType(constraintNode.Value),
+		}
+
+		if name := constraintNode.FirstChildOfType(parser.TypeConstraintName); name != nil {
+			constraint.Name = name.Value
+		} else {
+			constraint.Name = defaultConstraintName(columnDefinition.Value, constraint.Type)
+		}
+
+		column.Constraints = append(column.Constraints, constraint)
+	}
+
+	return column, nil
+}
+
+func planCreateTable(tx *storage.Tx, ast *parser.AST) ([]executor.Action, error) {
+	database, name, err := tableNames(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ddl.GetDatabase(tx, database); err != nil {
+		return nil, err
+	}
+
+	columnDefinitions := ast.FirstChildOfType(parser.TypeTableDefinition).FirstChildOfType(parser.TypeColumnDefinitionList)
+	if columnDefinitions == nil {
+		return nil, malformedASTError(ast, parser.TypeColumnDefinitionList)
+	}
+
+	table := ddl.Table{
+		Name:     name,
+		Database: database,
+	}
+
+	primaryKeys := 0
+	constraintNames := make(map[string]bool)
+	for _, columnDefinition := range columnDefinitions.Children {
+		column, err := columnFromDefinition(columnDefinition)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, existing := range table.Columns {
+			if stringutils.EqualsIgnoreCase(existing.Name, column.Name) {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicatedColumn, column.Name)
+			}
+		}
+
+		// The indexes backing the constraints are stored under their names, so two
+		// constraints can not share one
+		for _, constraint := range column.Constraints {
+			name := strings.ToUpper(constraint.Name)
+			if constraintNames[name] {
+				return nil, fmt.Errorf("%w: %s", ddl.ErrDuplicatedConstraint, constraint.Name)
+			}
+
+			constraintNames[name] = true
+		}
+
+		if ddl.ColumnIsPrimaryKey(column) {
+			primaryKeys++
+		}
+
+		table.Columns = append(table.Columns, column)
+	}
+
+	if primaryKeys != 1 {
+		return nil, ErrTableWithoutPrimaryKey
+	}
+
+	action := executor.CreateTableAction().WithParams(executor.Params{
+		executor.CreateTableParamsTableKey:             table,
+		executor.CreateTableParamsCreateOrReplaceKey:   ast.HasChildOfType(parser.TypeOrReplace),
+		executor.CreateTableParamsCreateIfNotExistsKey: ast.HasChildOfType(parser.TypeIfNotExists),
+	})
+
+	return []executor.Action{action}, nil
+}
+
+func planDropTable(tx *storage.Tx, ast *parser.AST) ([]executor.Action, error) {
+	database, name, err := tableNames(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ddl.GetTable(tx, database, name); err != nil {
+		return nil, err
+	}
+
+	action := executor.DropTableAction().WithParams(executor.Params{
+		executor.DropTableParamsDatabaseKey:  database,
+		executor.DropTableParamsTableNameKey: name,
+	})
+
+	return []executor.Action{action}, nil
+}
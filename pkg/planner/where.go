@@ -0,0 +1,393 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+)
+
+// conditionDataType The type of the expressions that evaluate to true, false or unknown
+const conditionDataType ddl.ColumnDataType = "CONDITION"
+
+// nullDataType The type of a NULL that is not compared with a column, which can be used
+// where a value of any type can
+const nullDataType ddl.ColumnDataType = "NULL"
+
+// typedExpression An expression and the type of the values it evaluates to
+type typedExpression struct {
+	expression dql.Expression
+	dataType   ddl.ColumnDataType
+}
+
+func expressionForWhere(scope *scope, where *parser.AST) (dql.Expression, error) {
+	if where == nil {
+		return dql.Literal{Value: true}, nil
+	}
+
+	if len(where.Children) != 1 {
+		return nil, malformedASTError(where, "condition")
+	}
+
+	return conditionForNode(scope, where.Children[0])
+}
+
+func conditionForNode(scope *scope, node *parser.AST) (dql.Expression, error) {
+	typed, err := expressionForNode(scope, node, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if typed.dataType != conditionDataType {
+		return nil, fmt.Errorf("%w: expected a condition, got a %s value", dql.ErrInvalidDataType, typed.dataType)
+	}
+
+	return typed.expression, nil
+}
+
+// expressionForNode Returns the expression of the node, the literals of the node are typed
+// as the column they are compared with, if any
+func expressionForNode(scope *scope, node *parser.AST, comparedColumn *ddl.Column) (typedExpression, error) {
+	switch node.Type {
+	case parser.TypeColumn:
+		column, expression, err := scope.reference(node)
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		return typedExpression{expression: expression, dataType: column.DataType}, nil
+
+	case parser.TypeSubquery:
+		subquery, dataType, err := scope.singleColumnSubquery(node)
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		return typedExpression{expression: dql.ScalarSubquery{Subquery: subquery}, dataType: dataType}, nil
+
+	case parser.TypeExists:
+		if len(node.Children) != 1 {
+			return typedExpression{}, malformedASTError(node, parser.TypeSubquery)
+		}
+
+		subquery, err := scope.subquery(node.Children[0])
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		return typedExpression{expression: dql.Exists{Subquery: subquery}, dataType: conditionDataType}, nil
+
+	case parser.TypeValue:
+		if len(node.Children) != 1 {
+			return typedExpression{}, malformedASTError(node, "literal")
+		}
+
+		// A FLOAT literal compared with an INTEGER column is not narrowed to the column type,
+		// the comparison is widened to FLOAT instead
+		column := ddl.Column{DataType: literalDataType(node.Children[0])}
+		if comparedColumn != nil && !(column.DataType == ddl.ColumnDataTypeFloat && comparedColumn.DataType == ddl.ColumnDataTypeInteger) {
+			column = *comparedColumn
+		}
+
+		value, err := literalValue(node.Children[0], column)
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		return typedExpression{expression: dql.Literal{Value: value}, dataType: column.DataType}, nil
+
+	case parser.TypeAnd, parser.TypeOr:
+		if len(node.Children) != 2 {
+			return typedExpression{}, malformedASTError(node, "two conditions")
+		}
+
+		left, err := conditionForNode(scope, node.Children[0])
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		right, err := conditionForNode(scope, node.Children[1])
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		if node.Type == parser.TypeAnd {
+			return typedExpression{expression: dql.And{Left: left, Right: right}, dataType: conditionDataType}, nil
+		}
+
+		return typedExpression{expression: dql.Or{Left: left, Right: right}, dataType: conditionDataType}, nil
+
+	case parser.TypeNot:
+		if len(node.Children) != 1 {
+			return typedExpression{}, malformedASTError(node, "condition")
+		}
+
+		condition, err := conditionForNode(scope, node.Children[0])
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		return typedExpression{expression: dql.Not{Expression: condition}, dataType: conditionDataType}, nil
+
+	case parser.TypeComparison:
+		comparison, err := comparisonForNode(scope, node)
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		return typedExpression{expression: comparison, dataType: conditionDataType}, nil
+
+	case parser.TypeArithmetic:
+		return arithmeticForNode(scope, node)
+
+	case parser.TypeFunction:
+		return functionForNode(scope, node)
+
+	case parser.TypeOver:
+		return windowForNode(scope, node)
+
+	case parser.TypeCase:
+		return caseForNode(scope, node)
+
+	case parser.TypeCast:
+		return castForNode(scope, node)
+
+	case parser.TypeNegation:
+		if len(node.Children) != 1 {
+			return typedExpression{}, malformedASTError(node, "operand")
+		}
+
+		operand, err := expressionForNode(scope, node.Children[0], nil)
+		if err != nil {
+			return typedExpression{}, err
+		}
+
+		if !dataTypeIsNumber(operand.dataType) && operand.dataType != nullDataType {
+			return typedExpression{}, fmt.Errorf("%w: can not negate a %s value", dql.ErrInvalidDataType, operand.dataType)
+		}
+
+		return typedExpression{expression: dql.Negation{Expression: operand.expression}, dataType: operand.dataType}, nil
+	}
+
+	return typedExpression{}, malformedASTError(node, "expression")
+}
+
+func literalDataType(literal *parser.AST) ddl.ColumnDataType {
+	switch literal.Type {
+	case parser.TypeIntegerLiteral:
+		return ddl.ColumnDataTypeInteger
+
+	case parser.TypeFloatLiteral:
+		return ddl.ColumnDataTypeFloat
+
+	case parser.TypeNullLiteral:
+		return nullDataType
+	}
+
+	return ddl.ColumnDataTypeText
+}
+
+// comparedColumn Returns the first column among the operands of a comparison, which types
+// the literals compared with it
+func comparedColumn(scope *scope, operands []*parser.AST) (*ddl.Column, error) {
+	for _, operand := range operands {
+		if operand.Type != parser.TypeColumn {
+			continue
+		}
+
+		column, _, err := scope.reference(operand)
+		if err != nil {
+			return nil, err
+		}
+
+		return &column, nil
+	}
+
+	return nil, nil
+}
+
+// operandsForComparison Returns the expressions of the operands and their type, they must
+// have types that can be compared with each other
+func operandsForComparison(scope *scope, comparison *parser.AST, operands []*parser.AST) ([]dql.Expression, ddl.ColumnDataType, error) {
+	column, err := comparedColumn(scope, operands)
+	if err != nil {
+		return nil, "", err
+	}
+
+	expressions := make([]dql.Expression, 0, len(operands))
+	var dataType ddl.ColumnDataType
+	for i, operand := range operands {
+		typed, err := expressionForNode(scope, operand, column)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if typed.dataType == conditionDataType {
+			return nil, "", fmt.Errorf("%w: %s can not compare conditions", dql.ErrInvalidDataType, comparison.Value)
+		}
+
+		if i == 0 || dataType == nullDataType {
+			dataType = typed.dataType
+		} else if !dataTypesAreComparable(dataType, typed.dataType) {
+			return nil, "", fmt.Errorf("%w: %s can not compare %s with %s", dql.ErrInvalidDataType, comparison.Value, dataType, typed.dataType)
+		}
+
+		expressions = append(expressions, typed.expression)
+	}
+
+	return expressions, dataType, nil
+}
+
+func dataTypeIsNumber(dataType ddl.ColumnDataType) bool {
+	return dataType == ddl.ColumnDataTypeInteger || dataType == ddl.ColumnDataTypeFloat
+}
+
+func dataTypesAreComparable(t1, t2 ddl.ColumnDataType) bool {
+	return t1 == t2 || t1 == nullDataType || t2 == nullDataType || (dataTypeIsNumber(t1) && dataTypeIsNumber(t2))
+}
+
+// arithmeticForNode Returns the expression of an arithmetic operation, which is INTEGER
+// if both operands are INTEGER and FLOAT if any of them is FLOAT, or TEXT for ||. A NULL
+// operand takes the type of the other one
+func arithmeticForNode(scope *scope, node *parser.AST) (typedExpression, error) {
+	if len(node.Children) != 2 {
+		return typedExpression{}, malformedASTError(node, "two operands")
+	}
+
+	left, err := expressionForNode(scope, node.Children[0], nil)
+	if err != nil {
+		return typedExpression{}, err
+	}
+
+	right, err := expressionForNode(scope, node.Children[1], nil)
+	if err != nil {
+		return typedExpression{}, err
+	}
+
+	operator := dql.ArithmeticOperator(node.Value)
+	expression := dql.Arithmetic{Operator: operator, Left: left.expression, Right: right.expression}
+
+	if left.dataType == nullDataType {
+		left.dataType = right.dataType
+	}
+
+	if right.dataType == nullDataType {
+		right.dataType = left.dataType
+	}
+
+	if left.dataType == nullDataType {
+		return typedExpression{expression: expression, dataType: nullDataType}, nil
+	}
+
+	switch operator {
+	case dql.ArithmeticOperatorConcat:
+		if left.dataType == ddl.ColumnDataTypeText && right.dataType == ddl.ColumnDataTypeText {
+			return typedExpression{expression: expression, dataType: ddl.ColumnDataTypeText}, nil
+		}
+
+	case dql.ArithmeticOperatorAdd,
+		dql.ArithmeticOperatorSubtract,
+		dql.ArithmeticOperatorMultiply,
+		dql.ArithmeticOperatorDivide,
+		dql.ArithmeticOperatorModulo:
+		if !dataTypeIsNumber(left.dataType) || !dataTypeIsNumber(right.dataType) {
+			break
+		}
+
+		dataType := ddl.ColumnDataTypeFloat
+		if left.dataType == ddl.ColumnDataTypeInteger && right.dataType == ddl.ColumnDataTypeInteger {
+			dataType = ddl.ColumnDataTypeInteger
+		}
+
+		return typedExpression{expression: expression, dataType: dataType}, nil
+
+	default:
+		return typedExpression{}, fmt.Errorf("%w: operator %s", ErrUnsupportedStatement, node.Value)
+	}
+
+	return typedExpression{}, fmt.Errorf("%w: can not apply %s to %s and %s", dql.ErrInvalidDataType, node.Value, left.dataType, right.dataType)
+}
+
+func comparisonForNode(scope *scope, comparison *parser.AST) (dql.Expression, error) {
+	operands := comparison.Children
+	if comparison.Value == "IN" && len(operands) == 2 && operands[1].Type == parser.TypeSubquery {
+		return inSubqueryForNode(scope, comparison)
+	}
+
+	if comparison.Value == "IN" {
+		if len(operands) != 2 || operands[1].Type != parser.TypeValueList {
+			return nil, malformedASTError(comparison, parser.TypeValueList)
+		}
+
+		operands = append([]*parser.AST{operands[0]}, operands[1].Children...)
+	}
+
+	expected := 2
+	switch comparison.Value {
+	case "BETWEEN":
+		expected = 3
+
+	case "IS NULL":
+		expected = 1
+
+	case "IN":
+		expected = max(len(operands), 2)
+	}
+
+	if len(operands) != expected {
+		return nil, malformedASTError(comparison, "operands")
+	}
+
+	expressions, dataType, err := operandsForComparison(scope, comparison, operands)
+	if err != nil {
+		return nil, err
+	}
+
+	switch comparison.Value {
+	case "=", "<>", "<", "<=", ">", ">=":
+		return dql.Comparison{
+			Comparison: dql.FilterComparison(comparison.Value),
+			Left:       expressions[0],
+			Right:      expressions[1],
+		}, nil
+
+	case "BETWEEN":
+		return dql.Between{Expression: expressions[0], Low: expressions[1], High: expressions[2]}, nil
+
+	case "IN":
+		return dql.In{Expression: expressions[0], Values: expressions[1:]}, nil
+
+	case "LIKE", "ILIKE":
+		if dataType != ddl.ColumnDataTypeText {
+			return nil, fmt.Errorf("%w: %s requires %s operands, got %s", dql.ErrInvalidDataType, comparison.Value, ddl.ColumnDataTypeText, dataType)
+		}
+
+		return dql.Like{Expression: expressions[0], Pattern: expressions[1], IgnoreCase: comparison.Value == "ILIKE"}, nil
+
+	case "IS NULL":
+		return dql.IsNull{Expression: expressions[0]}, nil
+	}
+
+	return nil, fmt.Errorf("%w: comparison %s", ErrUnsupportedStatement, comparison.Value)
+}
+
+// inSubqueryForNode Returns the IN comparison of a value with the values returned by a
+// subquery, which must return a single column of a type comparable with the value
+func inSubqueryForNode(scope *scope, comparison *parser.AST) (dql.Expression, error) {
+	typed, err := expressionForNode(scope, comparison.Children[0], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	subquery, dataType, err := scope.singleColumnSubquery(comparison.Children[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if !dataTypesAreComparable(typed.dataType, dataType) {
+		return nil, fmt.Errorf("%w: IN can not compare %s with %s", dql.ErrInvalidDataType, typed.dataType, dataType)
+	}
+
+	return dql.InSubquery{Expression: typed.expression, Subquery: subquery}, nil
+}
@@ -0,0 +1,241 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+)
+
+var (
+	ErrMisplacedWindow = errors.New("window functions are not allowed here")
+	ErrInvalidFrame    = errors.New("invalid window frame")
+)
+
+var windowFunctions = map[string]dql.WindowFunction{
+	string(dql.WindowFunctionRowNumber): dql.WindowFunctionRowNumber,
+	string(dql.WindowFunctionRank):      dql.WindowFunctionRank,
+	string(dql.WindowFunctionDenseRank): dql.WindowFunctionDenseRank,
+	string(dql.WindowFunctionLag):       dql.WindowFunctionLag,
+	string(dql.WindowFunctionLead):      dql.WindowFunctionLead,
+}
+
+type windowing struct {
+	windows []dql.Window
+}
+
+// windowForNode Adds the window function of an OVER node to the windows of the scope and
+// returns a reference to its column in the rows. Its arguments, partitions and orders are
+// evaluated over the rows, or the group rows of an aggregated select
+func windowForNode(scope *scope, node *parser.AST) (typedExpression, error) {
+	if scope.windowing == nil {
+		return typedExpression{}, ErrMisplacedWindow
+	}
+
+	function := node.FirstChildOfType(parser.TypeFunction)
+	if function == nil {
+		return typedExpression{}, malformedASTError(node, parser.TypeFunction)
+	}
+
+	rowScope := scope.unwindowed()
+
+	window := dql.Window{}
+	if partitionBy := node.FirstChildOfType(parser.TypePartitionBy); partitionBy != nil {
+		for _, columnNode := range partitionBy.ChildrenOfType(parser.TypeColumn) {
+			column, err := rowScope.columnForNode(columnNode)
+			if err != nil {
+				return typedExpression{}, err
+			}
+
+			window.PartitionBy = append(window.PartitionBy, column.Name)
+		}
+	}
+
+	if orderBy := node.FirstChildOfType(parser.TypeOrderBy); orderBy != nil {
+		for _, item := range orderBy.ChildrenOfType(parser.TypeOrderItem) {
+			columnNode := item.FirstChildOfType(parser.TypeColumn)
+			if columnNode == nil {
+				return typedExpression{}, malformedASTError(item, parser.TypeColumn)
+			}
+
+			column, err := rowScope.columnForNode(columnNode)
+			if err != nil {
+				return typedExpression{}, err
+			}
+
+			window.Orders = append(window.Orders, orderForItem(item, column.Name))
+		}
+	}
+
+	var err error
+	if window.Frame, err = frameForNode(node.FirstChildOfType(parser.TypeFrame)); err != nil {
+		return typedExpression{}, err
+	}
+
+	if err := windowFunctionForNode(rowScope, function, &window); err != nil {
+		return typedExpression{}, err
+	}
+
+	window.Column.Name = fmt.Sprintf("$%s_OVER%d", window.Function, len(scope.windowing.windows))
+	scope.windowing.windows = append(scope.windowing.windows, window)
+
+	return typedExpression{
+		expression: dql.ColumnRef{Name: window.Column.Name},
+		dataType:   window.Column.DataType,
+	}, nil
+}
+
+// windowFunctionForNode Sets the function of the window, its arguments and the type of its
+// column. ROW_NUMBER, RANK and DENSE_RANK are INTEGER, LAG and LEAD have the type of their
+// argument and aggregate functions the type they have in an aggregated select
+func windowFunctionForNode(scope *scope, function *parser.AST, window *dql.Window) error {
+	arguments := function.Children
+
+	if aggregateFunction, isAggregate := scope.with.functions.aggregate(function.Value); isAggregate {
+		if len(arguments) != 1 {
+			return fmt.Errorf("%w: %s expects 1 argument, got %d", ErrFunctionArguments, function.Value, len(arguments))
+		}
+
+		window.Function = dql.WindowFunction(aggregateFunction)
+		window.NewState = scope.with.functions.newState(aggregateFunction)
+
+		switch arguments[0].Type {
+		case parser.TypeAllColumns:
+			if aggregateFunction != dql.AggregateFunctionCount {
+				return fmt.Errorf("%w: %s(*)", ErrUnsupportedStatement, aggregateFunction)
+			}
+
+			window.Column.DataType = ddl.ColumnDataTypeInteger
+			return nil
+
+		case parser.TypeDistinct:
+			return fmt.Errorf("%w: DISTINCT in window function %s", ErrUnsupportedStatement, aggregateFunction)
+		}
+
+		typed, err := expressionForNode(scope, arguments[0], nil)
+		if err != nil {
+			return err
+		}
+
+		window.Expression = typed.expression
+		window.Column.DataType, err = scope.with.functions.aggregateDataType(aggregateFunction, typed.dataType)
+		return err
+	}
+
+	windowFunction, isWindow := windowFunctions[function.Value]
+	if !isWindow {
+		return fmt.Errorf("%w: window function %s", ErrUnsupportedStatement, function.Value)
+	}
+
+	window.Function = windowFunction
+
+	if windowFunction != dql.WindowFunctionLag && windowFunction != dql.WindowFunctionLead {
+		if len(arguments) != 0 {
+			return fmt.Errorf("%w: %s expects no arguments, got %d", ErrFunctionArguments, windowFunction, len(arguments))
+		}
+
+		window.Column.DataType = ddl.ColumnDataTypeInteger
+		return nil
+	}
+
+	if len(arguments) == 0 || len(arguments) > 3 {
+		return fmt.Errorf("%w: %s expects 1 to 3 arguments, got %d", ErrFunctionArguments, windowFunction, len(arguments))
+	}
+
+	typed, err := expressionForNode(scope, arguments[0], nil)
+	if err != nil {
+		return err
+	}
+
+	if typed.dataType == conditionDataType {
+		return fmt.Errorf("%w: expected a value in %s, got a condition", dql.ErrInvalidDataType, windowFunction)
+	}
+
+	window.Expression, window.Offset, window.Column.DataType = typed.expression, 1, typed.dataType
+
+	if len(arguments) > 1 {
+		if window.Offset, err = offsetValue(arguments[1]); err != nil {
+			return err
+		}
+	}
+
+	if len(arguments) > 2 {
+		// The default is typed as the argument, as if they were compared
+		column := ddl.Column{DataType: typed.dataType}
+		defaultValue, err := expressionForNode(scope, arguments[2], &column)
+		if err != nil {
+			return err
+		}
+
+		if !dataTypesAreComparable(typed.dataType, defaultValue.dataType) {
+			return fmt.Errorf("%w: default of %s is %s, expected %s", dql.ErrInvalidDataType, windowFunction, defaultValue.dataType, typed.dataType)
+		}
+
+		window.Default = defaultValue.expression
+	}
+
+	return nil
+}
+
+// offsetValue Returns the offset of a LAG or LEAD, which must be a non negative integer
+func offsetValue(node *parser.AST) (int, error) {
+	if node.Type == parser.TypeValue && len(node.Children) == 1 && node.Children[0].Type == parser.TypeIntegerLiteral {
+		if offset, err := strconv.Atoi(node.Children[0].Value); err == nil {
+			return offset, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: the offset of LAG and LEAD must be a non negative integer", dql.ErrInvalidDataType)
+}
+
+// frameForNode Returns the frame of a FRAME node, or nil if there is none. A frame can not
+// start at UNBOUNDED FOLLOWING nor end at UNBOUNDED PRECEDING
+func frameForNode(node *parser.AST) (*dql.Frame, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	bounds := node.ChildrenOfType(parser.TypeFrameBound)
+	if len(bounds) != 2 {
+		return nil, malformedASTError(node, "two frame bounds")
+	}
+
+	start, err := frameBoundForNode(bounds[0])
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := frameBoundForNode(bounds[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if start.Type == dql.FrameBoundUnboundedFollowing || end.Type == dql.FrameBoundUnboundedPreceding {
+		return nil, fmt.Errorf("%w: frame from %s to %s", ErrInvalidFrame, start.Type, end.Type)
+	}
+
+	return &dql.Frame{Start: start, End: end}, nil
+}
+
+func frameBoundForNode(node *parser.AST) (dql.FrameBound, error) {
+	bound := dql.FrameBound{Type: dql.FrameBoundType(node.Value)}
+	if bound.Type != dql.FrameBoundPreceding && bound.Type != dql.FrameBoundFollowing {
+		return bound, nil
+	}
+
+	count := node.FirstChildOfType(parser.TypeIntegerLiteral)
+	if count == nil {
+		return dql.FrameBound{}, malformedASTError(node, parser.TypeIntegerLiteral)
+	}
+
+	offset, err := strconv.Atoi(count.Value)
+	if err != nil || offset < 0 {
+		return dql.FrameBound{}, fmt.Errorf("%w: %s is not a valid frame offset", ErrInvalidFrame, count.Value)
+	}
+
+	bound.Offset = offset
+	return bound, nil
+}
@@ -0,0 +1,209 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gustapinto/go-sql-store/pkg/operators/ddl"
+	"github.com/gustapinto/go-sql-store/pkg/operators/dql"
+	"github.com/gustapinto/go-sql-store/pkg/parser"
+	"github.com/gustapinto/go-sql-store/pkg/storage"
+)
+
+var (
+	ErrCommonTableColumns = errors.New("common table has another number of columns than its query")
+)
+
+// commonTables The common tables a query can read, added by the WITH clauses around it
+type commonTables struct {
+	tables                 map[string]*commonTable
+	parent                 *commonTables
+	maxRecursiveIterations int
+
+	// functions The user defined functions of the statement
+	functions *functions
+}
+
+type commonTable struct {
+	table      *dql.CommonTable
+	referenced bool
+}
+
+// lookup Returns the common table with the name, from the innermost WITH clause that has it
+func (c *commonTables) lookup(name string) *dql.CommonTable {
+	for with := c; with != nil; with = with.parent {
+		if entry, exists := with.tables[strings.ToUpper(name)]; exists {
+			entry.referenced = true
+			return entry.table
+		}
+	}
+
+	return nil
+}
+
+// withForNode Returns the common tables of the WITH clause of a query, if it has one, and
+// the common tables its queries can read. Each common table can read the ones before it
+func withForNode(tx *storage.Tx, node *parser.AST, outer *outerQuery, parent *commonTables) ([]*dql.CommonTable, *commonTables, error) {
+	withNode := node.FirstChildOfType(parser.TypeWith)
+	if withNode == nil {
+		return nil, parent, nil
+	}
+
+	with := &commonTables{
+		tables:                 make(map[string]*commonTable),
+		parent:                 parent,
+		maxRecursiveIterations: parent.maxRecursiveIterations,
+		functions:              parent.functions,
+	}
+
+	tables := make([]*dql.CommonTable, 0, len(withNode.Children))
+	for _, commonTableNode := range withNode.ChildrenOfType(parser.TypeCommonTable) {
+		name := strings.ToUpper(commonTableNode.Value)
+		if _, exists := with.tables[name]; exists {
+			return nil, nil, fmt.Errorf("%w: %s", ErrAmbiguousTable, name)
+		}
+
+		table, err := commonTableForNode(tx, commonTableNode, withNode.Value == "RECURSIVE", outer, with)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		with.tables[name] = &commonTable{table: table}
+		tables = append(tables, table)
+	}
+
+	return tables, with, nil
+}
+
+// commonTableForNode Returns the common table of a COMMON_TABLE node. In a WITH RECURSIVE
+// clause a common table is recursive if its query is a UNION whose right query reads it,
+// in which case the left query can not read it
+func commonTableForNode(tx *storage.Tx, node *parser.AST, recursive bool, outer *outerQuery, with *commonTables) (*dql.CommonTable, error) {
+	subquery := node.FirstChildOfType(parser.TypeSubquery)
+	if subquery == nil || len(subquery.Children) != 1 {
+		return nil, malformedASTError(node, parser.TypeSubquery)
+	}
+
+	table := &dql.CommonTable{
+		Name:          strings.ToUpper(node.Value),
+		MaxIterations: with.maxRecursiveIterations,
+	}
+
+	queryNode := subquery.Children[0]
+	if recursive && isRecursiveUnion(queryNode) {
+		operands := queryOperands(queryNode)
+		if len(operands) != 2 {
+			return nil, malformedASTError(queryNode, "two queries")
+		}
+
+		query, err := queryForNode(tx, operands[0], outer, with)
+		if err != nil {
+			return nil, err
+		}
+
+		if table.Columns, err = commonTableColumns(node, query.Columns()); err != nil {
+			return nil, err
+		}
+
+		self := &commonTable{table: table}
+		with.tables[table.Name] = self
+
+		recursiveQuery, err := queryForNode(tx, operands[1], outer, with)
+		delete(with.tables, table.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if self.referenced {
+			if err := checkRecursiveColumns(table, recursiveQuery.Columns()); err != nil {
+				return nil, err
+			}
+
+			table.Query, table.Recursive, table.All = query, recursiveQuery, queryNode.Value == "UNION ALL"
+			return table, nil
+		}
+	}
+
+	query, err := queryForNode(tx, queryNode, outer, with)
+	if err != nil {
+		return nil, err
+	}
+
+	if table.Columns, err = commonTableColumns(node, query.Columns()); err != nil {
+		return nil, err
+	}
+
+	table.Query = query
+	return table, nil
+}
+
+// isRecursiveUnion Checks if a query is a UNION that can be run as a recursive query, which
+// it can not be if it sorts or pages its rows
+func isRecursiveUnion(node *parser.AST) bool {
+	if node.Type != parser.TypeSetOperation || (node.Value != "UNION" && node.Value != "UNION ALL") {
+		return false
+	}
+
+	for _, nodeType := range []string{parser.TypeWith, parser.TypeOrderBy, parser.TypeLimit, parser.TypeOffset} {
+		if node.FirstChildOfType(nodeType) != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// queryOperands Returns the queries combined by a set operation
+func queryOperands(node *parser.AST) []*parser.AST {
+	var operands []*parser.AST
+	for _, child := range node.Children {
+		if child.Type == parser.TypeSelectOperation || child.Type == parser.TypeSetOperation {
+			operands = append(operands, child)
+		}
+	}
+
+	return operands
+}
+
+// commonTableColumns Returns the columns of a common table, which are the columns of its
+// query, renamed by its column list if it has one
+func commonTableColumns(node *parser.AST, resultColumns []dql.ResultColumn) ([]ddl.Column, error) {
+	columns := make([]ddl.Column, 0, len(resultColumns))
+	for _, column := range resultColumns {
+		columns = append(columns, ddl.Column{Name: strings.ToUpper(column.Name), DataType: column.DataType})
+	}
+
+	columnList := node.FirstChildOfType(parser.TypeColumnList)
+	if columnList == nil {
+		return columns, nil
+	}
+
+	names := columnList.ChildrenOfType(parser.TypeColumn)
+	if len(names) != len(columns) {
+		return nil, fmt.Errorf("%w: %s has %d columns, its query has %d", ErrCommonTableColumns, node.Value, len(names), len(columns))
+	}
+
+	for i, name := range names {
+		columns[i].Name = strings.ToUpper(name.Value)
+	}
+
+	return columns, nil
+}
+
+// checkRecursiveColumns Checks if the columns of a recursive query have the types of the
+// columns of its common table, or are INTEGER for FLOAT columns
+func checkRecursiveColumns(table *dql.CommonTable, columns []dql.ResultColumn) error {
+	if len(columns) != len(table.Columns) {
+		return fmt.Errorf("%w: recursive query of %s has %d columns, expected %d", ErrIncompatibleQueries, table.Name, len(columns), len(table.Columns))
+	}
+
+	for i, column := range columns {
+		expected := table.Columns[i].DataType
+		if column.DataType != expected && (expected != ddl.ColumnDataTypeFloat || column.DataType != ddl.ColumnDataTypeInteger) {
+			return fmt.Errorf("%w: recursive query of %s returns %s for the %s column %s", ErrIncompatibleQueries, table.Name, column.DataType, expected, table.Columns[i].Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+)
+
+// version A value of a record as written by the transaction committed with CommitID
+type version struct {
+	CommitID uint64
+	Value    []byte
+	Deleted  bool
+}
+
+// versionChain Every version of a record that may still be visible to a snapshot, ordered
+// from the oldest to the newest. Chains are what is stored in the kv collections
+type versionChain struct {
+	Versions  []version
+	Cacheable bool
+}
+
+func encodeVersionChain(chain versionChain) ([]byte, error) {
+	return encodingutils.Encode(chain)
+}
+
+func decodeVersionChain(buffer []byte) (versionChain, error) {
+	return encodingutils.Decode[versionChain](buffer)
+}
+
+// visible Returns the value of the record as seen by a snapshot, a record that did not
+// exist or was deleted at the snapshot has no visible value
+func (c versionChain) visible(snapshot uint64) ([]byte, bool) {
+	for i := len(c.Versions) - 1; i >= 0; i-- {
+		if c.Versions[i].CommitID > snapshot {
+			continue
+		}
+
+		if c.Versions[i].Deleted {
+			return nil, false
+		}
+
+		return c.Versions[i].Value, true
+	}
+
+	return nil, false
+}
+
+func (c versionChain) latestCommitID() uint64 {
+	if len(c.Versions) == 0 {
+		return 0
+	}
+
+	return c.Versions[len(c.Versions)-1].CommitID
+}
+
+// hasCommit Checks if the chain already has the changes of a commit, a chain only holds
+// versions newer than the ones pruned from it, so any version at or after the commit means
+// it was applied
+func (c versionChain) hasCommit(commitID uint64) bool {
+	return c.latestCommitID() >= commitID
+}
+
+// prune Drops the versions that no snapshot at or after the horizon can see, that is every
+// version older than the newest one committed at or before the horizon
+func (c versionChain) prune(horizon uint64) versionChain {
+	oldestVisible := 0
+	for i, version := range c.Versions {
+		if version.CommitID <= horizon {
+			oldestVisible = i
+		}
+	}
+
+	return versionChain{Versions: c.Versions[oldestVisible:], Cacheable: c.Cacheable}
+}
+
+// isGarbage Checks if the whole chain is invisible to every snapshot at or after the
+// horizon, which is the case once its only version is a deletion they all can see
+func (c versionChain) isGarbage(horizon uint64) bool {
+	return len(c.Versions) == 1 && c.Versions[0].Deleted && c.Versions[0].CommitID <= horizon
+}
@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	gokvstore "github.com/gustapinto/go-kv-store"
+)
+
+func TestVersionChain(t *testing.T) {
+	chain := versionChain{
+		Versions: []version{
+			{CommitID: 2, Value: []byte("a")},
+			{CommitID: 4, Deleted: true},
+			{CommitID: 6, Value: []byte("b")},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		snapshot         uint64
+		expectedValue    string
+		expectedVisible  bool
+		expectedVersions int
+	}{
+		{
+			name:             "should not see versions committed after the snapshot",
+			snapshot:         1,
+			expectedVisible:  false,
+			expectedVersions: 3,
+		},
+		{
+			name:             "should see the newest version committed before the snapshot",
+			snapshot:         3,
+			expectedValue:    "a",
+			expectedVisible:  true,
+			expectedVersions: 3,
+		},
+		{
+			name:             "should not see deleted versions",
+			snapshot:         5,
+			expectedVisible:  false,
+			expectedVersions: 2,
+		},
+		{
+			name:             "should see the latest version",
+			snapshot:         6,
+			expectedValue:    "b",
+			expectedVisible:  true,
+			expectedVersions: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			value, visible := chain.visible(testCase.snapshot)
+			if visible != testCase.expectedVisible || string(value) != testCase.expectedValue {
+				t.Errorf("expected value %q visible %v, got %q visible %v", testCase.expectedValue, testCase.expectedVisible, value, visible)
+				return
+			}
+
+			pruned := chain.prune(testCase.snapshot)
+			if len(pruned.Versions) != testCase.expectedVersions {
+				t.Errorf("expected %d versions after pruning, got %d", testCase.expectedVersions, len(pruned.Versions))
+				return
+			}
+
+			// Pruning must never change what the snapshot sees
+			if prunedValue, prunedVisible := pruned.visible(testCase.snapshot); prunedVisible != visible || !slices.Equal(prunedValue, value) {
+				t.Errorf("expected pruned chain to keep value %q, got %q", value, prunedValue)
+				return
+			}
+		})
+	}
+}
+
+func TestMVCCSnapshotIsolation(t *testing.T) {
+	store := testTxMockStorage(t)
+
+	reader := store.Begin()
+	defer reader.Rollback()
+
+	writer := store.Begin()
+	if err := writer.Put("foo", "existing", []byte("updated"), false); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if err := writer.Put("foo", "new", []byte("value"), false); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if err := writer.Truncate("bar"); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if err := writer.Commit(); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if value, err := reader.Get("foo", "existing"); err != nil || string(value) != "value" {
+		t.Errorf("expected the snapshot value, got %s and error %v", value, err)
+		return
+	}
+
+	if keys := testTxKeys(t, reader, "foo"); !slices.Equal(keys, []string{"existing"}) {
+		t.Errorf("expected the snapshot keys, got %v", keys)
+		return
+	}
+
+	if keys := testTxCommittedKeys(t, store, "foo"); !slices.Equal(keys, []string{"existing", "new"}) {
+		t.Errorf("expected new transactions to see the commit, got keys %v", keys)
+		return
+	}
+}
+
+func TestMVCCWritersDoNotBlockReaders(t *testing.T) {
+	store := testTxMockStorage(t)
+
+	// Holding the commit lock is what a writer does while applying a commit
+	store.commitLock.Lock()
+	defer store.commitLock.Unlock()
+
+	done := make(chan error)
+	go func() {
+		tx := store.Begin()
+		defer tx.Rollback()
+
+		_, err := tx.Get("foo", "existing")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("not expected error, got %s", err)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Errorf("expected reader to not wait for the writer")
+	}
+}
+
+func TestMVCCGC(t *testing.T) {
+	store := testTxMockStorage(t)
+	reader := store.Begin()
+
+	for _, value := range []string{"v1", "v2", "v3"} {
+		tx := store.Begin()
+		if err := tx.Put("foo", "existing", []byte(value), false); err != nil {
+			t.Errorf("not expected error, got %s", err)
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			t.Errorf("not expected error, got %s", err)
+			return
+		}
+	}
+
+	tx := store.Begin()
+	if err := tx.Delete("foo", "existing"); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if err := store.GC(); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if value, err := reader.Get("foo", "existing"); err != nil || string(value) != "value" {
+		t.Errorf("expected GC to keep the versions seen by open transactions, got %s and error %v", value, err)
+		return
+	}
+	reader.Rollback()
+
+	if err := store.GC(); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	c, err := store.collection("foo")
+	if err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if keys := c.keys(); len(keys) != 0 {
+		t.Errorf("expected GC to remove deleted records, got keys %v", keys)
+		return
+	}
+
+	tx = store.Begin()
+	defer tx.Rollback()
+
+	if _, err := tx.Get("foo", "existing"); !errors.Is(err, gokvstore.ErrKeyNotFound) {
+		t.Errorf("expected error %v, got %v", gokvstore.ErrKeyNotFound, err)
+		return
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"slices"
+)
+
+// KeyRange The keys from Start up to, but not including, End, scanned in ascending order
+// or in descending order if Reverse is set. An empty bound leaves its side of the range
+// open, as the kv store does not accept empty keys
+type KeyRange struct {
+	Start   string
+	End     string
+	Reverse bool
+}
+
+func (r KeyRange) contains(key string) bool {
+	return (r.Start == "" || key >= r.Start) && (r.End == "" || key < r.End)
+}
+
+// before Checks if the key a is scanned before the key b
+func (r KeyRange) before(a, b string) bool {
+	if r.Reverse {
+		return a > b
+	}
+
+	return a < b
+}
+
+// bounds Returns the positions of the first key in the range and of the first key after
+// it in the sorted keys
+func (r KeyRange) bounds(keys []string) (start, end int) {
+	end = len(keys)
+	if r.Start != "" {
+		start, _ = slices.BinarySearch(keys, r.Start)
+	}
+
+	if r.End != "" {
+		end, _ = slices.BinarySearch(keys, r.End)
+	}
+
+	return start, max(start, end)
+}
+
+// orderedKeys The keys of a collection as a sorted run. Keys added after the run was built
+// are buffered and merged into a new run on the next ordered read, so a run handed to a
+// reader is never modified
+type orderedKeys struct {
+	sorted []string
+	added  []string
+}
+
+func newOrderedKeys(keys []string) *orderedKeys {
+	slices.Sort(keys)
+	return &orderedKeys{sorted: keys}
+}
+
+func (o *orderedKeys) add(key string) {
+	o.added = append(o.added, key)
+}
+
+func (o *orderedKeys) keys() []string {
+	if len(o.added) == 0 {
+		return o.sorted
+	}
+
+	slices.Sort(o.added)
+	added := slices.Compact(o.added)
+
+	merged := make([]string, 0, len(o.sorted)+len(added))
+	i, j := 0, 0
+	for i < len(o.sorted) || j < len(added) {
+		switch {
+		case j == len(added) || (i < len(o.sorted) && o.sorted[i] < added[j]):
+			merged = append(merged, o.sorted[i])
+			i++
+
+		case i == len(o.sorted) || added[j] < o.sorted[i]:
+			merged = append(merged, added[j])
+			j++
+
+		default:
+			merged = append(merged, o.sorted[i])
+			i++
+			j++
+		}
+	}
+
+	o.sorted = merged
+	o.added = nil
+
+	return o.sorted
+}
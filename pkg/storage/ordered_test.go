@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTxScanRange(t *testing.T) {
+	testCases := []struct {
+		name         string
+		keyRange     KeyRange
+		limit        int
+		expectedKeys []string
+	}{
+		{
+			name:         "should scan every key in order",
+			keyRange:     KeyRange{},
+			expectedKeys: []string{"a", "b", "c", "d", "e", "f"},
+		},
+		{
+			name:         "should scan every key in reverse order",
+			keyRange:     KeyRange{Reverse: true},
+			expectedKeys: []string{"f", "e", "d", "c", "b", "a"},
+		},
+		{
+			name:         "should scan from the start key up to the end key",
+			keyRange:     KeyRange{Start: "b", End: "e"},
+			expectedKeys: []string{"b", "c", "d"},
+		},
+		{
+			name:         "should scan a range in reverse order",
+			keyRange:     KeyRange{Start: "bb", End: "f", Reverse: true},
+			expectedKeys: []string{"e", "d", "c"},
+		},
+		{
+			name:         "should stop scanning early",
+			keyRange:     KeyRange{Start: "b"},
+			limit:        2,
+			expectedKeys: []string{"b", "c"},
+		},
+		{
+			name:         "should scan an empty range",
+			keyRange:     KeyRange{Start: "d", End: "c"},
+			expectedKeys: nil,
+		},
+	}
+
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("not expected error when opening storage, got %s", err)
+	}
+	defer store.Close()
+
+	// The committed keys are read once, so the ordered keys are built before the others
+	// are committed
+	for _, keys := range [][]string{{"e", "a", "z"}, {"c", "b"}} {
+		tx := store.Begin()
+		for _, key := range keys {
+			if err := tx.Put("foo", key, []byte(key), false); err != nil {
+				t.Fatalf("not expected error when mocking storage, got %s", err)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("not expected error when mocking storage, got %s", err)
+		}
+
+		reader := store.Begin()
+		if err := reader.ScanRange("foo", KeyRange{}, func(string, []byte) error { return nil }); err != nil {
+			t.Fatalf("not expected error when mocking storage, got %s", err)
+		}
+		reader.Rollback()
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			tx := store.Begin()
+			defer tx.Rollback()
+
+			// Buffered writes must be merged with the committed keys
+			if err := tx.Put("foo", "d", []byte("d"), false); err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if err := tx.Put("foo", "f", []byte("f"), false); err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if err := tx.Delete("foo", "z"); err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			var keys []string
+			err := tx.ScanRange("foo", testCase.keyRange, func(key string, value []byte) error {
+				if key != string(value) {
+					t.Errorf("expected value %s for key %s, got %s", key, key, value)
+				}
+
+				keys = append(keys, key)
+				if len(keys) == testCase.limit {
+					return ErrStopScan
+				}
+
+				return nil
+			})
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if !slices.Equal(keys, testCase.expectedKeys) {
+				t.Errorf("expected keys %v, got %v", testCase.expectedKeys, keys)
+				return
+			}
+		})
+	}
+}
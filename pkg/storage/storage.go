@@ -0,0 +1,761 @@
+package storage
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	gokvstore "github.com/gustapinto/go-kv-store"
+	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+)
+
+const (
+	systemDataDir      = "_system"
+	collectionsDataDir = "_system/collections"
+	lastCommitIDKey    = "last_commit_id"
+
+	// gcInterval How many commits are made between garbage collections of old versions
+	gcInterval = 1024
+)
+
+var (
+	ErrInvalidDataDir       = errors.New("invalid data directory")
+	ErrStorageNeedsRecovery = errors.New("a commit was not fully applied, the storage must be opened again to recover it")
+)
+
+// collection A kv collection of version chains, the mutex only guards the kv collection
+// itself, as it is not safe for concurrent use, and its ordered keys
+type collection struct {
+	dataDir string
+
+	mu      sync.Mutex
+	kv      *gokvstore.Collection
+	ordered *orderedKeys
+}
+
+func (c *collection) get(key string) (chain versionChain, exists bool, err error) {
+	c.mu.Lock()
+	buffer, err := c.kv.Get(key)
+	c.mu.Unlock()
+
+	if err != nil {
+		if errors.Is(err, gokvstore.ErrKeyNotFound) {
+			return versionChain{}, false, nil
+		}
+
+		return versionChain{}, false, err
+	}
+
+	chain, err = decodeVersionChain(buffer)
+	if err != nil {
+		return versionChain{}, false, err
+	}
+
+	return chain, true, nil
+}
+
+func (c *collection) put(key string, chain versionChain) error {
+	buffer, err := encodeVersionChain(chain)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ordered != nil && !c.kv.Exists(key) {
+		c.ordered.add(key)
+	}
+
+	return c.kv.Put(key, buffer, chain.Cacheable)
+}
+
+func (c *collection) keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return slices.Collect(c.kv.Keys())
+}
+
+// sortedKeys Returns the keys in ascending order, they are only sorted on the first call
+// and then kept sorted as keys are added
+func (c *collection) sortedKeys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ordered == nil {
+		c.ordered = newOrderedKeys(slices.Collect(c.kv.Keys()))
+	}
+
+	return c.ordered.keys()
+}
+
+// Storage Owns the root collection and the caches derived from it. Every read and write
+// must go through a [Tx].
+//
+// Records are stored as chains of versions tagged with the ID of the commit that wrote
+// them. A transaction reads the versions committed before it began, so it sees a
+// consistent snapshot, and readers never wait for writers, as commits only make their
+// versions visible after every one of them was written. Versions that no snapshot can
+// see anymore are dropped by [Storage.GC].
+//
+// When opened from a data directory every commit is first recorded in a [WAL], which is
+// replayed by [Open], so a crash while applying a commit never leaves it half applied
+type Storage struct {
+	root *gokvstore.Collection
+	wal  *WAL
+
+	// commitLock Serializes commits and garbage collections, readers never take it
+	commitLock     sync.Mutex
+	lastCommitID   atomic.Uint64
+	commitsSinceGC int
+	failure        error
+
+	mu          sync.Mutex
+	collections map[string]*collection
+	registered  map[string]struct{}
+	snapshots   map[uint64]int
+	truncations map[string]uint64
+
+	// beforeApply Is called before applying each write of a commit, tests use it to
+	// simulate crashes
+	beforeApply func(index int) error
+}
+
+func New(root *gokvstore.Collection) (*Storage, error) {
+	s := &Storage{
+		root:        root,
+		collections: map[string]*collection{},
+		registered:  map[string]struct{}{},
+		snapshots:   map[uint64]int{},
+		truncations: map[string]uint64{},
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Open Opens a storage in the data directory, replaying every change recorded in its
+// write-ahead log that was not applied yet
+func Open(dataDir string) (*Storage, error) {
+	absDataDir, err := filepath.Abs(dataDir)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidDataDir, err)
+	}
+
+	store := gokvstore.NewFsRecordStore(absDataDir)
+	if store == nil {
+		return nil, ErrInvalidDataDir
+	}
+
+	root, err := gokvstore.NewCollection(store)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := New(root)
+	if err != nil {
+		return nil, err
+	}
+
+	wal, err := OpenWAL(walPath(absDataDir))
+	if err != nil {
+		return nil, err
+	}
+	s.wal = wal
+
+	if err := s.recover(); err != nil {
+		wal.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// load Reads the last commit ID and the registry of collections
+func (s *Storage) load() error {
+	system, err := s.collection(systemDataDir)
+	if err != nil {
+		return err
+	}
+
+	buffer, err := system.kv.Get(lastCommitIDKey)
+	if err != nil && !errors.Is(err, gokvstore.ErrKeyNotFound) {
+		return err
+	}
+
+	if err == nil {
+		lastCommitID, err := encodingutils.Decode[uint64](buffer)
+		if err != nil {
+			return err
+		}
+
+		s.lastCommitID.Store(lastCommitID)
+	}
+
+	collections, err := s.collection(collectionsDataDir)
+	if err != nil {
+		return err
+	}
+
+	for _, dataDir := range collections.keys() {
+		s.registered[dataDir] = struct{}{}
+	}
+
+	return nil
+}
+
+// recover Applies every record of the write-ahead log again and discards them. Replaying
+// a record that was already applied, fully or partially, leaves the collections in the
+// same state
+func (s *Storage) recover() error {
+	records, err := s.wal.Records()
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := s.apply(record); err != nil {
+			return err
+		}
+
+		if record.CommitID > s.lastCommitID.Load() {
+			s.lastCommitID.Store(record.CommitID)
+		}
+	}
+
+	return s.wal.Checkpoint()
+}
+
+// Close Closes the write-ahead log, the storage must not be used afterwards
+func (s *Storage) Close() error {
+	s.commitLock.Lock()
+	defer s.commitLock.Unlock()
+
+	if s.wal == nil {
+		return nil
+	}
+
+	return s.wal.Close()
+}
+
+// Begin Starts a transaction reading the snapshot of the last commit. Old versions are
+// kept while the transaction is open, so it must always be committed or rolled back
+func (s *Storage) Begin() *Tx {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.lastCommitID.Load()
+	s.snapshots[snapshot]++
+
+	return newTx(s, snapshot)
+}
+
+func (s *Storage) release(snapshot uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshots[snapshot]--
+	if s.snapshots[snapshot] <= 0 {
+		delete(s.snapshots, snapshot)
+	}
+}
+
+// horizon Returns the oldest snapshot that is still in use or may be taken by a new
+// transaction, versions only visible to older snapshots can be dropped
+func (s *Storage) horizon() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	horizon := s.lastCommitID.Load()
+	for snapshot := range s.snapshots {
+		horizon = min(horizon, snapshot)
+	}
+
+	return horizon
+}
+
+func (s *Storage) collection(dataDir string) (*collection, error) {
+	dataDir = normalizeDataDir(dataDir)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, exists := s.collections[dataDir]; exists {
+		return c, nil
+	}
+
+	kv, err := s.root.NewCollection(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &collection{dataDir: dataDir, kv: kv}
+	s.collections[dataDir] = c
+
+	return c, nil
+}
+
+// register Records that the collection holds records, so truncating any of its parent
+// directories can find them
+func (s *Storage) register(dataDir string) error {
+	s.mu.Lock()
+	_, registered := s.registered[dataDir]
+	s.mu.Unlock()
+
+	if registered {
+		return nil
+	}
+
+	collections, err := s.collection(collectionsDataDir)
+	if err != nil {
+		return err
+	}
+
+	collections.mu.Lock()
+	err = collections.kv.Put(dataDir, []byte(dataDir), false)
+	collections.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.registered[dataDir] = struct{}{}
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *Storage) registeredUnder(dataDir string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var dataDirs []string
+	for registered := range s.registered {
+		if isSameOrSubDataDir(registered, dataDir) {
+			dataDirs = append(dataDirs, registered)
+		}
+	}
+
+	return dataDirs
+}
+
+// remove Deletes records from a collection and opens it again, as
+// [gokvstore.Collection.Delete] does not forget deleted keys
+func (s *Storage) remove(c *collection, keys []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if err := c.kv.Delete(key); err != nil && !errors.Is(err, gokvstore.ErrKeyNotFound) {
+			return err
+		}
+	}
+
+	kv, err := s.root.NewCollection(c.dataDir)
+	if err != nil {
+		return err
+	}
+
+	c.kv = kv
+	c.ordered = nil
+	return nil
+}
+
+func (s *Storage) read(dataDir, key string, snapshot uint64) ([]byte, error) {
+	c, err := s.collection(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, _, err := c.get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	value, _ := chain.visible(snapshot)
+	return value, nil
+}
+
+func (s *Storage) scan(dataDir string, snapshot uint64, fn func(key string, value []byte) error) error {
+	c, err := s.collection(dataDir)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range c.keys() {
+		chain, _, err := c.get(key)
+		if err != nil {
+			return err
+		}
+
+		if value, visible := chain.visible(snapshot); visible {
+			if err := fn(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// scanRange Calls fn for every record visible to the snapshot in the key range, in the
+// order of the range
+func (s *Storage) scanRange(dataDir string, snapshot uint64, keyRange KeyRange, fn func(key string, value []byte) error) error {
+	c, err := s.collection(dataDir)
+	if err != nil {
+		return err
+	}
+
+	keys := c.sortedKeys()
+	start, end := keyRange.bounds(keys)
+
+	for i := range end - start {
+		key := keys[start+i]
+		if keyRange.Reverse {
+			key = keys[end-1-i]
+		}
+
+		chain, _, err := c.get(key)
+		if err != nil {
+			return err
+		}
+
+		if value, visible := chain.visible(snapshot); visible {
+			if err := fn(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// commit Validates the transaction writes against the commits made after its snapshot,
+// records them in the write-ahead log and applies them
+func (s *Storage) commit(tx *Tx) error {
+	s.commitLock.Lock()
+	defer s.commitLock.Unlock()
+
+	if s.failure != nil {
+		return errors.Join(ErrStorageNeedsRecovery, s.failure)
+	}
+
+	entries, truncated, err := s.resolve(tx)
+	if err != nil {
+		return err
+	}
+
+	record := walRecord{
+		CommitID: s.lastCommitID.Load() + 1,
+		Entries:  entries,
+	}
+
+	if s.wal != nil {
+		if err := s.wal.Append(record); err != nil {
+			return err
+		}
+	}
+
+	if err := s.apply(record); err != nil {
+		s.failure = err
+		return err
+	}
+
+	s.mu.Lock()
+	s.lastCommitID.Store(record.CommitID)
+	for _, dataDir := range truncated {
+		s.truncations[dataDir] = record.CommitID
+	}
+	s.mu.Unlock()
+
+	if s.wal != nil && s.wal.size > walCheckpointSize {
+		if err := s.wal.Checkpoint(); err != nil {
+			return err
+		}
+	}
+
+	s.commitsSinceGC++
+	if s.commitsSinceGC >= gcInterval {
+		if err := s.gc(); err != nil {
+			slog.Default().Warn("Garbage collection failed", "error", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) truncatedSince(dataDir string, snapshot uint64, includeSubDataDirs bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for truncated, commitID := range s.truncations {
+		if commitID <= snapshot {
+			continue
+		}
+
+		if isSameOrSubDataDir(dataDir, truncated) || (includeSubDataDirs && isSameOrSubDataDir(truncated, dataDir)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolve Turns the ordered writes of a transaction into the final change of each record,
+// expanding truncations into deletions of every record under the truncated directory.
+// It fails with [ErrWriteConflict] if any of those records was changed, or any of their
+// directories truncated, by a commit made after the transaction snapshot
+func (s *Storage) resolve(tx *Tx) (entries []walEntry, truncated []string, err error) {
+	type recordID struct {
+		dataDir string
+		key     string
+	}
+
+	indexes := map[recordID]int{}
+	set := func(entry walEntry) {
+		id := recordID{dataDir: entry.DataDir, key: entry.Key}
+		if i, exists := indexes[id]; exists {
+			entries[i] = entry
+			return
+		}
+
+		indexes[id] = len(entries)
+		entries = append(entries, entry)
+	}
+
+	for _, write := range tx.writes {
+		if !write.truncated {
+			if s.truncatedSince(write.dataDir, tx.snapshot, false) {
+				return nil, nil, ErrWriteConflict
+			}
+
+			c, err := s.collection(write.dataDir)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			chain, _, err := c.get(write.key)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if chain.latestCommitID() > tx.snapshot {
+				return nil, nil, ErrWriteConflict
+			}
+
+			set(walEntry{
+				DataDir:   write.dataDir,
+				Key:       write.key,
+				Value:     write.value,
+				Cacheable: write.cacheable,
+				Deleted:   write.deleted,
+			})
+
+			continue
+		}
+
+		if s.truncatedSince(write.dataDir, tx.snapshot, true) {
+			return nil, nil, ErrWriteConflict
+		}
+
+		truncated = append(truncated, write.dataDir)
+
+		for _, dataDir := range s.registeredUnder(write.dataDir) {
+			c, err := s.collection(dataDir)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			for _, key := range c.keys() {
+				chain, _, err := c.get(key)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				if chain.latestCommitID() > tx.snapshot {
+					return nil, nil, ErrWriteConflict
+				}
+
+				set(walEntry{DataDir: dataDir, Key: key, Deleted: true})
+			}
+		}
+
+		// Records written earlier by the transaction itself are deleted as well
+		for id, i := range indexes {
+			if isSameOrSubDataDir(id.dataDir, write.dataDir) {
+				entries[i] = walEntry{DataDir: id.dataDir, Key: id.key, Deleted: true}
+			}
+		}
+	}
+
+	return entries, truncated, nil
+}
+
+// apply Adds a version for each entry of the record, skipping the records that already
+// have it, so a record can be applied more than once
+func (s *Storage) apply(record walRecord) error {
+	horizon := s.horizon()
+
+	for i, entry := range record.Entries {
+		if s.beforeApply != nil {
+			if err := s.beforeApply(i); err != nil {
+				return err
+			}
+		}
+
+		if err := s.register(entry.DataDir); err != nil {
+			return err
+		}
+
+		c, err := s.collection(entry.DataDir)
+		if err != nil {
+			return err
+		}
+
+		chain, exists, err := c.get(entry.Key)
+		if err != nil {
+			return err
+		}
+
+		if chain.hasCommit(record.CommitID) {
+			continue
+		}
+
+		if entry.Deleted {
+			if _, visible := chain.visible(record.CommitID); !exists || !visible {
+				continue
+			}
+		}
+
+		chain.Cacheable = entry.Cacheable || chain.Cacheable
+		chain.Versions = append(chain.Versions, version{
+			CommitID: record.CommitID,
+			Value:    entry.Value,
+			Deleted:  entry.Deleted,
+		})
+
+		if err := c.put(entry.Key, chain.prune(horizon)); err != nil {
+			return err
+		}
+	}
+
+	system, err := s.collection(systemDataDir)
+	if err != nil {
+		return err
+	}
+
+	lastCommitID, err := encodingutils.Encode(record.CommitID)
+	if err != nil {
+		return err
+	}
+
+	system.mu.Lock()
+	defer system.mu.Unlock()
+
+	return system.kv.Put(lastCommitIDKey, lastCommitID, true)
+}
+
+// GC Drops every version that no open transaction, nor any transaction started after,
+// can see, and deletes the records whose only remaining version is a deletion
+func (s *Storage) GC() error {
+	s.commitLock.Lock()
+	defer s.commitLock.Unlock()
+
+	return s.gc()
+}
+
+func (s *Storage) gc() error {
+	s.commitsSinceGC = 0
+	horizon := s.horizon()
+
+	for _, dataDir := range s.registeredUnder("") {
+		c, err := s.collection(dataDir)
+		if err != nil {
+			return err
+		}
+
+		var garbage []string
+		for _, key := range c.keys() {
+			chain, _, err := c.get(key)
+			if err != nil {
+				return err
+			}
+
+			pruned := chain.prune(horizon)
+			if pruned.isGarbage(horizon) {
+				garbage = append(garbage, key)
+				continue
+			}
+
+			if len(pruned.Versions) < len(chain.Versions) {
+				if err := c.put(key, pruned); err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(garbage) > 0 {
+			if err := s.remove(c, garbage); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// TruncateAll Deletes the entire storage data directory and clears every cached collection
+func (s *Storage) TruncateAll() error {
+	s.commitLock.Lock()
+	defer s.commitLock.Unlock()
+
+	s.mu.Lock()
+	clear(s.collections)
+	clear(s.registered)
+	clear(s.truncations)
+	s.mu.Unlock()
+
+	if err := s.root.Truncate(); err != nil {
+		return err
+	}
+
+	if s.wal == nil {
+		return nil
+	}
+
+	// The log was deleted with the data directory, so it is created again
+	if err := s.wal.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.wal.path), 0755); err != nil {
+		return err
+	}
+
+	wal, err := OpenWAL(s.wal.path)
+	if err != nil {
+		return err
+	}
+
+	s.wal = wal
+	return nil
+}
+
+func normalizeDataDir(dataDir string) string {
+	return strings.TrimSuffix(dataDir, "/")
+}
+
+func isSameOrSubDataDir(dataDir, parent string) bool {
+	dataDir, parent = normalizeDataDir(dataDir), normalizeDataDir(parent)
+
+	return parent == "" || dataDir == parent || strings.HasPrefix(dataDir, parent+"/")
+}
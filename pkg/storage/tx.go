@@ -0,0 +1,337 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+
+	gokvstore "github.com/gustapinto/go-kv-store"
+)
+
+var (
+	ErrTxClosed      = errors.New("transaction is already closed")
+	ErrWriteConflict = errors.New("write conflict, a record was modified by a concurrent transaction")
+
+	// ErrStopScan Can be returned by the function called by a scan to stop it early, the
+	// scan then returns no error
+	ErrStopScan = errors.New("stop scan")
+)
+
+type txWrite struct {
+	dataDir   string
+	key       string
+	value     []byte
+	cacheable bool
+	deleted   bool
+	truncated bool
+}
+
+// Tx A transaction over a [Storage]. Reads see the snapshot committed when the transaction
+// began overlaid with the transaction own writes, which are buffered in memory and applied
+// atomically on [Tx.Commit].
+//
+// Conflicts are detected optimistically: if a record written by the transaction was
+// changed by a commit made after its snapshot, committing fails with [ErrWriteConflict].
+// A Tx is not safe for concurrent use
+type Tx struct {
+	store    *Storage
+	snapshot uint64
+	writes   []txWrite
+	closed   bool
+
+	pending   map[string]map[string]txWrite
+	truncated []string
+}
+
+func newTx(store *Storage, snapshot uint64) *Tx {
+	return &Tx{
+		store:    store,
+		snapshot: snapshot,
+		pending:  map[string]map[string]txWrite{},
+	}
+}
+
+func (tx *Tx) Storage() *Storage {
+	return tx.store
+}
+
+// Snapshot Returns the ID of the last commit visible to the transaction
+func (tx *Tx) Snapshot() uint64 {
+	return tx.snapshot
+}
+
+func (tx *Tx) Closed() bool {
+	return tx.closed
+}
+
+func (tx *Tx) isTruncated(dataDir string) bool {
+	for _, truncated := range tx.truncated {
+		if isSameOrSubDataDir(dataDir, truncated) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (tx *Tx) index(write txWrite) {
+	if write.truncated {
+		tx.truncated = append(tx.truncated, write.dataDir)
+
+		for dataDir := range tx.pending {
+			if isSameOrSubDataDir(dataDir, write.dataDir) {
+				delete(tx.pending, dataDir)
+			}
+		}
+
+		return
+	}
+
+	if _, exists := tx.pending[write.dataDir]; !exists {
+		tx.pending[write.dataDir] = map[string]txWrite{}
+	}
+
+	tx.pending[write.dataDir][write.key] = write
+}
+
+func (tx *Tx) read(dataDir, key string) ([]byte, error) {
+	if tx.closed {
+		return nil, ErrTxClosed
+	}
+
+	if write, exists := tx.pending[dataDir][key]; exists {
+		if write.deleted {
+			return nil, nil
+		}
+
+		return write.value, nil
+	}
+
+	if tx.isTruncated(dataDir) {
+		return nil, nil
+	}
+
+	return tx.store.read(dataDir, key, tx.snapshot)
+}
+
+func (tx *Tx) Get(dataDir, key string) ([]byte, error) {
+	value, err := tx.read(normalizeDataDir(dataDir), key)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return nil, gokvstore.ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+func (tx *Tx) Exists(dataDir, key string) (bool, error) {
+	value, err := tx.read(normalizeDataDir(dataDir), key)
+	if err != nil {
+		return false, err
+	}
+
+	return value != nil, nil
+}
+
+func (tx *Tx) write(write txWrite) error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+
+	tx.writes = append(tx.writes, write)
+	tx.index(write)
+
+	return nil
+}
+
+func (tx *Tx) Put(dataDir, key string, value []byte, cacheable bool) error {
+	return tx.write(txWrite{
+		dataDir:   normalizeDataDir(dataDir),
+		key:       key,
+		value:     value,
+		cacheable: cacheable,
+	})
+}
+
+// Delete Deletes a record, it returns [gokvstore.ErrKeyNotFound] if the key does not exist
+func (tx *Tx) Delete(dataDir, key string) error {
+	exists, err := tx.Exists(dataDir, key)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return gokvstore.ErrKeyNotFound
+	}
+
+	return tx.write(txWrite{
+		dataDir: normalizeDataDir(dataDir),
+		key:     key,
+		deleted: true,
+	})
+}
+
+// Truncate Deletes every record of the collection and of its sub collections
+func (tx *Tx) Truncate(dataDir string) error {
+	return tx.write(txWrite{
+		dataDir:   normalizeDataDir(dataDir),
+		truncated: true,
+	})
+}
+
+// Scan Calls fn for every record visible to the transaction in the collection, stopping
+// on the first error
+func (tx *Tx) Scan(dataDir string, fn func(key string, value []byte) error) error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+
+	dataDir = normalizeDataDir(dataDir)
+	pending := tx.pending[dataDir]
+
+	if !tx.isTruncated(dataDir) {
+		err := tx.store.scan(dataDir, tx.snapshot, func(key string, value []byte) error {
+			if _, exists := pending[key]; exists {
+				return nil
+			}
+
+			return fn(key, value)
+		})
+		if err != nil {
+			return ignoreStopScan(err)
+		}
+	}
+
+	for key, write := range pending {
+		if write.deleted {
+			continue
+		}
+
+		if err := fn(key, write.value); err != nil {
+			return ignoreStopScan(err)
+		}
+	}
+
+	return nil
+}
+
+// ScanRange Calls fn for every record visible to the transaction in the key range of the
+// collection, in the order of the range, stopping on the first error
+func (tx *Tx) ScanRange(dataDir string, keyRange KeyRange, fn func(key string, value []byte) error) error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+
+	dataDir = normalizeDataDir(dataDir)
+	pending := tx.pending[dataDir]
+
+	var pendingKeys []string
+	for key := range pending {
+		if keyRange.contains(key) {
+			pendingKeys = append(pendingKeys, key)
+		}
+	}
+
+	slices.Sort(pendingKeys)
+	if keyRange.Reverse {
+		slices.Reverse(pendingKeys)
+	}
+
+	// The buffered writes are merged with the committed records, as both are in order
+	next := 0
+	scanPendingUntil := func(key string) error {
+		for ; next < len(pendingKeys) && (key == "" || keyRange.before(pendingKeys[next], key)); next++ {
+			if write := pending[pendingKeys[next]]; !write.deleted {
+				if err := fn(write.key, write.value); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if !tx.isTruncated(dataDir) {
+		err := tx.store.scanRange(dataDir, tx.snapshot, keyRange, func(key string, value []byte) error {
+			if err := scanPendingUntil(key); err != nil {
+				return err
+			}
+
+			if _, exists := pending[key]; exists {
+				return nil
+			}
+
+			return fn(key, value)
+		})
+		if err != nil {
+			return ignoreStopScan(err)
+		}
+	}
+
+	return ignoreStopScan(scanPendingUntil(""))
+}
+
+func ignoreStopScan(err error) error {
+	if errors.Is(err, ErrStopScan) {
+		return nil
+	}
+
+	return err
+}
+
+// Savepoint Returns a marker that can be used with [Tx.RollbackTo] to discard every
+// write made after it
+func (tx *Tx) Savepoint() int {
+	return len(tx.writes)
+}
+
+func (tx *Tx) RollbackTo(savepoint int) error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+
+	if savepoint < 0 || savepoint > len(tx.writes) {
+		return nil
+	}
+
+	tx.writes = tx.writes[:savepoint]
+	tx.pending = map[string]map[string]txWrite{}
+	tx.truncated = nil
+
+	for _, write := range tx.writes {
+		tx.index(write)
+	}
+
+	return nil
+}
+
+// Commit Applies every buffered write, or none of them if the transaction conflicts with
+// another committed transaction
+func (tx *Tx) Commit() error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+	tx.closed = true
+	defer tx.store.release(tx.snapshot)
+
+	if len(tx.writes) == 0 {
+		return nil
+	}
+
+	return tx.store.commit(tx)
+}
+
+func (tx *Tx) Rollback() error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+	tx.closed = true
+	defer tx.store.release(tx.snapshot)
+
+	tx.writes = nil
+	tx.pending = nil
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	gokvstore "github.com/gustapinto/go-kv-store"
+)
+
+func testTxMockStorage(t *testing.T) *Storage {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("not expected error when opening storage, got %s", err)
+	}
+
+	tx := store.Begin()
+	if err := tx.Put("foo", "existing", []byte("value"), false); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	return store
+}
+
+func testTxCommittedKeys(t *testing.T, store *Storage, dataDir string) []string {
+	tx := store.Begin()
+	defer tx.Rollback()
+
+	return testTxKeys(t, tx, dataDir)
+}
+
+func testTxKeys(t *testing.T, tx *Tx, dataDir string) []string {
+	var keys []string
+	err := tx.Scan(dataDir, func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("not expected error when scanning, got %s", err)
+	}
+
+	slices.Sort(keys)
+	return keys
+}
+
+func TestTx(t *testing.T) {
+	testCases := []struct {
+		name         string
+		run          func(tx *Tx) error
+		commit       bool
+		expectedKeys []string
+	}{
+		{
+			name: "should apply writes on commit",
+			run: func(tx *Tx) error {
+				return tx.Put("foo", "new", []byte("value"), false)
+			},
+			commit:       true,
+			expectedKeys: []string{"existing", "new"},
+		},
+		{
+			name: "should discard writes on rollback",
+			run: func(tx *Tx) error {
+				if err := tx.Put("foo", "new", []byte("value"), false); err != nil {
+					return err
+				}
+
+				return tx.Delete("foo", "existing")
+			},
+			commit:       false,
+			expectedKeys: []string{"existing"},
+		},
+		{
+			name: "should apply deletes on commit",
+			run: func(tx *Tx) error {
+				return tx.Delete("foo", "existing")
+			},
+			commit:       true,
+			expectedKeys: nil,
+		},
+		{
+			name: "should apply writes made after a truncate",
+			run: func(tx *Tx) error {
+				if err := tx.Truncate("foo"); err != nil {
+					return err
+				}
+
+				return tx.Put("foo", "new", []byte("value"), false)
+			},
+			commit:       true,
+			expectedKeys: []string{"new"},
+		},
+		{
+			name: "should discard writes made after a savepoint",
+			run: func(tx *Tx) error {
+				if err := tx.Put("foo", "new", []byte("value"), false); err != nil {
+					return err
+				}
+
+				savepoint := tx.Savepoint()
+				if err := tx.Delete("foo", "existing"); err != nil {
+					return err
+				}
+
+				return tx.RollbackTo(savepoint)
+			},
+			commit:       true,
+			expectedKeys: []string{"existing", "new"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			store := testTxMockStorage(t)
+			tx := store.Begin()
+
+			if err := testCase.run(tx); err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if keys := testTxCommittedKeys(t, store, "foo"); !slices.Equal(keys, []string{"existing"}) {
+				t.Errorf("expected uncommitted writes to be invisible, got keys %v", keys)
+				return
+			}
+
+			var err error
+			if testCase.commit {
+				err = tx.Commit()
+			} else {
+				err = tx.Rollback()
+			}
+
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if keys := testTxCommittedKeys(t, store, "foo"); !slices.Equal(keys, testCase.expectedKeys) {
+				t.Errorf("expected keys %v, got %v", testCase.expectedKeys, keys)
+				return
+			}
+		})
+	}
+}
+
+func TestTxReadYourWrites(t *testing.T) {
+	store := testTxMockStorage(t)
+	tx := store.Begin()
+	defer tx.Rollback()
+
+	if err := tx.Put("foo", "existing", []byte("updated"), false); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if value, err := tx.Get("foo", "existing"); err != nil || string(value) != "updated" {
+		t.Errorf("expected updated value, got %s and error %v", value, err)
+		return
+	}
+
+	if err := tx.Delete("foo", "existing"); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if _, err := tx.Get("foo", "existing"); !errors.Is(err, gokvstore.ErrKeyNotFound) {
+		t.Errorf("expected error %v, got %v", gokvstore.ErrKeyNotFound, err)
+		return
+	}
+}
+
+func TestTxWriteConflict(t *testing.T) {
+	store := testTxMockStorage(t)
+	tx1, tx2 := store.Begin(), store.Begin()
+
+	if err := tx1.Put("foo", "existing", []byte("tx1"), false); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if err := tx2.Put("foo", "existing", []byte("tx2"), false); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if err := tx1.Commit(); err != nil {
+		t.Errorf("not expected error, got %s", err)
+		return
+	}
+
+	if err := tx2.Commit(); !errors.Is(err, ErrWriteConflict) {
+		t.Errorf("expected error %v, got %v", ErrWriteConflict, err)
+		return
+	}
+
+	if err := tx2.Commit(); !errors.Is(err, ErrTxClosed) {
+		t.Errorf("expected error %v, got %v", ErrTxClosed, err)
+		return
+	}
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gustapinto/go-sql-store/pkg/utils/encodingutils"
+)
+
+const (
+	walFileName = "wal.log"
+
+	// walCheckpointSize The log is truncated once it grows past this size and every
+	// logged change was applied
+	walCheckpointSize = 1 << 20
+
+	walHeaderSize = 8
+)
+
+var (
+	ErrCorruptedWAL = errors.New("write-ahead log is corrupted")
+)
+
+// walEntry A logical change to a collection, recorded before it is applied
+type walEntry struct {
+	DataDir   string
+	Key       string
+	Value     []byte
+	Cacheable bool
+	Deleted   bool
+}
+
+// walRecord Every change of a committed transaction. A record is only considered committed
+// if it was fully written to the log, so a transaction is either replayed entirely or not
+// at all
+type walRecord struct {
+	CommitID uint64
+	Entries  []walEntry
+}
+
+// WAL An append only write-ahead log. Each record is stored as its length and CRC32
+// checksum followed by the gob encoded record, a record with a short or mismatching
+// payload marks a torn write and ends the log
+type WAL struct {
+	path string
+	file *os.File
+	size int64
+}
+
+func OpenWAL(path string) (*WAL, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &WAL{
+		path: path,
+		file: file,
+		size: info.Size(),
+	}, nil
+}
+
+func walPath(dataDir string) string {
+	return filepath.Join(dataDir, walFileName)
+}
+
+// Append Writes the record to the log and flushes it to disk
+func (w *WAL) Append(record walRecord) error {
+	payload, err := encodingutils.Encode(record)
+	if err != nil {
+		return err
+	}
+
+	buffer := make([]byte, walHeaderSize, walHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(buffer[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buffer[4:8], crc32.ChecksumIEEE(payload))
+	buffer = append(buffer, payload...)
+
+	if _, err := w.file.Write(buffer); err != nil {
+		return err
+	}
+
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+
+	w.size += int64(len(buffer))
+	return nil
+}
+
+// Records Reads every complete record of the log, a torn record at the end of the log is
+// discarded
+func (w *WAL) Records() ([]walRecord, error) {
+	file, err := os.Open(w.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	header := make([]byte, walHeaderSize)
+
+	var records []walRecord
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return records, nil
+			}
+
+			return nil, err
+		}
+
+		payload := make([]byte, binary.LittleEndian.Uint32(header[0:4]))
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return records, nil
+			}
+
+			return nil, err
+		}
+
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(header[4:8]) {
+			return records, nil
+		}
+
+		record, err := encodingutils.Decode[walRecord](payload)
+		if err != nil {
+			return nil, errors.Join(ErrCorruptedWAL, err)
+		}
+
+		records = append(records, record)
+	}
+}
+
+// Checkpoint Discards every record of the log, it must only be called after every record
+// was applied
+func (w *WAL) Checkpoint() error {
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+
+	w.size = 0
+	return nil
+}
+
+func (w *WAL) Close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var errTestWALSimulatedCrash = errors.New("simulated crash")
+
+// testWALCommittedState The state before the crashed transaction, a table definition and
+// its rows, like a CREATE TABLE followed by some INSERTs
+var testWALCommittedState = map[string]map[string]string{
+	"tables/foo":      {"definition": "v1"},
+	"tables/foo/rows": {"1": "a", "2": "b", "3": "c"},
+}
+
+// testWALCrashedState The state after the crashed transaction, which replaces the table
+// like a CREATE OR REPLACE TABLE and inserts rows into it
+var testWALCrashedState = map[string]map[string]string{
+	"tables/foo":      {"definition": "v2"},
+	"tables/foo/rows": {"1": "x", "4": "y"},
+}
+
+func testWALCrashedTx(tx *Tx) error {
+	if err := tx.Truncate("tables/foo"); err != nil {
+		return err
+	}
+
+	if err := tx.Put("tables/foo", "definition", []byte("v2"), true); err != nil {
+		return err
+	}
+
+	if err := tx.Put("tables/foo/rows", "1", []byte("x"), false); err != nil {
+		return err
+	}
+
+	return tx.Put("tables/foo/rows", "4", []byte("y"), false)
+}
+
+func testWALMockStorage(t *testing.T, dataDir string) *Storage {
+	store, err := Open(dataDir)
+	if err != nil {
+		t.Fatalf("not expected error when opening storage, got %s", err)
+	}
+
+	tx := store.Begin()
+	for dataDir, records := range testWALCommittedState {
+		for key, value := range records {
+			if err := tx.Put(dataDir, key, []byte(value), false); err != nil {
+				t.Fatalf("not expected error when mocking storage, got %s", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("not expected error when mocking storage, got %s", err)
+	}
+
+	return store
+}
+
+func testWALState(t *testing.T, store *Storage) map[string]map[string]string {
+	tx := store.Begin()
+	defer tx.Rollback()
+
+	state := map[string]map[string]string{}
+	for dataDir := range testWALCommittedState {
+		records := map[string]string{}
+
+		err := tx.Scan(dataDir, func(key string, value []byte) error {
+			records[key] = string(value)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("not expected error when scanning, got %s", err)
+		}
+
+		state[dataDir] = records
+	}
+
+	return state
+}
+
+func testWALStatesEqual(s1, s2 map[string]map[string]string) bool {
+	return maps.EqualFunc(s1, s2, func(r1, r2 map[string]string) bool {
+		return maps.Equal(r1, r2)
+	})
+}
+
+func TestWALCrashWhileApplying(t *testing.T) {
+	// The crashed transaction resolves into 5 writes, the last case does not crash at all
+	for crashedWrite := range 6 {
+		t.Run(fmt.Sprintf("should recover the committed state after crashing before write %d", crashedWrite), func(t *testing.T) {
+			dataDir := t.TempDir()
+			store := testWALMockStorage(t, dataDir)
+
+			store.beforeApply = func(index int) error {
+				if index == crashedWrite {
+					return errTestWALSimulatedCrash
+				}
+
+				return nil
+			}
+
+			tx := store.Begin()
+			if err := testWALCrashedTx(tx); err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if err := tx.Commit(); err != nil && !errors.Is(err, errTestWALSimulatedCrash) {
+				t.Errorf("expected error %v, got %v", errTestWALSimulatedCrash, err)
+				return
+			}
+			store.Close()
+
+			recovered, err := Open(dataDir)
+			if err != nil {
+				t.Errorf("not expected error when recovering, got %s", err)
+				return
+			}
+			defer recovered.Close()
+
+			if state := testWALState(t, recovered); !testWALStatesEqual(state, testWALCrashedState) {
+				t.Errorf("expected state %v, got %v", testWALCrashedState, state)
+				return
+			}
+		})
+	}
+}
+
+func TestWALCrashWhileLogging(t *testing.T) {
+	record, err := func() ([]byte, error) {
+		wal, err := OpenWAL(filepath.Join(t.TempDir(), walFileName))
+		if err != nil {
+			return nil, err
+		}
+		defer wal.Close()
+
+		record := walRecord{
+			CommitID: 1,
+			Entries:  []walEntry{{DataDir: "tables/foo", Key: "definition", Deleted: true}},
+		}
+
+		if err := wal.Append(record); err != nil {
+			return nil, err
+		}
+
+		return os.ReadFile(wal.path)
+	}()
+	if err != nil {
+		t.Fatalf("not expected error when mocking log record, got %s", err)
+	}
+
+	for _, written := range []int{0, 1, walHeaderSize - 1, walHeaderSize, len(record) - 1} {
+		t.Run(fmt.Sprintf("should discard a record torn after %d bytes", written), func(t *testing.T) {
+			dataDir := t.TempDir()
+			store := testWALMockStorage(t, dataDir)
+			store.Close()
+
+			file, err := os.OpenFile(walPath(dataDir), os.O_WRONLY|os.O_APPEND, 0666)
+			if err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			if _, err := file.Write(record[:written]); err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+			file.Close()
+
+			recovered, err := Open(dataDir)
+			if err != nil {
+				t.Errorf("not expected error when recovering, got %s", err)
+				return
+			}
+			defer recovered.Close()
+
+			if state := testWALState(t, recovered); !testWALStatesEqual(state, testWALCommittedState) {
+				t.Errorf("expected state %v, got %v", testWALCommittedState, state)
+				return
+			}
+
+			// The torn record must not hide records appended after recovering
+			tx := recovered.Begin()
+			if err := testWALCrashedTx(tx); err != nil {
+				t.Errorf("not expected error, got %s", err)
+				return
+			}
+
+			recovered.beforeApply = func(int) error {
+				return errTestWALSimulatedCrash
+			}
+
+			if err := tx.Commit(); !errors.Is(err, errTestWALSimulatedCrash) {
+				t.Errorf("expected error %v, got %v", errTestWALSimulatedCrash, err)
+				return
+			}
+			recovered.Close()
+
+			reopened, err := Open(dataDir)
+			if err != nil {
+				t.Errorf("not expected error when recovering, got %s", err)
+				return
+			}
+			defer reopened.Close()
+
+			if state := testWALState(t, reopened); !testWALStatesEqual(state, testWALCrashedState) {
+				t.Errorf("expected state %v, got %v", testWALCrashedState, state)
+				return
+			}
+		})
+	}
+}
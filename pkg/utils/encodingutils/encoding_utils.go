@@ -3,8 +3,14 @@ package encodingutils
 import (
 	"bytes"
 	"encoding/gob"
+	"time"
 )
 
+func init() {
+	// Values of TIMESTAMP columns are stored in interfaces, so gob must know their type
+	gob.Register(time.Time{})
+}
+
 func Encode[T any](data T) ([]byte, error) {
 	var buffer bytes.Buffer
 	if err := gob.NewEncoder(&buffer).Encode(data); err != nil {
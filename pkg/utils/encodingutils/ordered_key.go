@@ -0,0 +1,120 @@
+package encodingutils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Every encoded value starts with a tag of its type, so NULLs sort before any other value
+// and values of different types never compare as equal
+const (
+	orderedKeyNullTag    = '0'
+	orderedKeyIntegerTag = '1'
+	orderedKeyFloatTag   = '2'
+	orderedKeyTimeTag    = '3'
+	orderedKeyStringTag  = '4'
+	orderedKeyOtherTag   = '9'
+
+	// orderedKeyPrefixEnd Greater than any byte of a valid UTF-8 string, so appending it to
+	// a key gives a bound after every key starting with it
+	orderedKeyPrefixEnd = "\xff"
+)
+
+// EncodeOrderedKey Encodes the values into a key whose byte order matches the order of
+// the values, comparing them from the first to the last. Integers, floats and times are
+// encoded with a fixed width and strings are escaped and terminated, so a value is never
+// mistaken for the prefix of another. The key is valid UTF-8, as required by the kv store
+func EncodeOrderedKey(values ...any) string {
+	builder := strings.Builder{}
+	for _, value := range values {
+		writeOrderedKeyValue(&builder, value)
+	}
+
+	return builder.String()
+}
+
+// OrderedKeyPrefixEnd Returns a bound that is greater than every key starting with the
+// prefix and lower than any other key greater than it
+func OrderedKeyPrefixEnd(prefix string) string {
+	return prefix + orderedKeyPrefixEnd
+}
+
+func writeOrderedKeyValue(builder *strings.Builder, value any) {
+	switch v := value.(type) {
+	case nil:
+		builder.WriteByte(orderedKeyNullTag)
+
+	case int:
+		writeOrderedKeyInteger(builder, int64(v))
+
+	case int8:
+		writeOrderedKeyInteger(builder, int64(v))
+
+	case int16:
+		writeOrderedKeyInteger(builder, int64(v))
+
+	case int32:
+		writeOrderedKeyInteger(builder, int64(v))
+
+	case int64:
+		writeOrderedKeyInteger(builder, v)
+
+	case float32:
+		writeOrderedKeyFloat(builder, float64(v))
+
+	case float64:
+		writeOrderedKeyFloat(builder, v)
+
+	case time.Time:
+		builder.WriteByte(orderedKeyTimeTag)
+		writeOrderedKeyUint(builder, uint64(v.UnixNano())^(1<<63))
+
+	case string:
+		builder.WriteByte(orderedKeyStringTag)
+		writeOrderedKeyString(builder, v)
+
+	default:
+		builder.WriteByte(orderedKeyOtherTag)
+		writeOrderedKeyString(builder, fmt.Sprint(v))
+	}
+}
+
+// writeOrderedKeyInteger Flips the sign bit, so negative integers sort before positive ones
+func writeOrderedKeyInteger(builder *strings.Builder, value int64) {
+	builder.WriteByte(orderedKeyIntegerTag)
+	writeOrderedKeyUint(builder, uint64(value)^(1<<63))
+}
+
+// writeOrderedKeyFloat Flips the sign bit of positive floats and every bit of negative
+// ones, which makes the IEEE 754 bits sort as the floats do
+func writeOrderedKeyFloat(builder *strings.Builder, value float64) {
+	if value == 0 {
+		value = 0 // Encodes -0 as 0
+	}
+
+	bits := math.Float64bits(value)
+	if bits&(1<<63) != 0 {
+		bits = ^bits
+	} else {
+		bits |= 1 << 63
+	}
+
+	builder.WriteByte(orderedKeyFloatTag)
+	writeOrderedKeyUint(builder, bits)
+}
+
+func writeOrderedKeyUint(builder *strings.Builder, value uint64) {
+	encoded := strconv.FormatUint(value, 16)
+	builder.WriteString(strings.Repeat("0", 16-len(encoded)))
+	builder.WriteString(encoded)
+}
+
+// writeOrderedKeyString Escapes every zero byte as "\x00\x01" and terminates the string
+// with "\x00\x00", so a string sorts before every longer string it is a prefix of
+func writeOrderedKeyString(builder *strings.Builder, value string) {
+	builder.WriteString(strings.ReplaceAll(value, "\x00", "\x00\x01"))
+	builder.WriteString("\x00\x00")
+}
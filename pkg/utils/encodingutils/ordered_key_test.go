@@ -0,0 +1,98 @@
+package encodingutils
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEncodeOrderedKey(t *testing.T) {
+	testCases := []struct {
+		name    string
+		lesser  []any
+		greater []any
+	}{
+		{
+			name:    "null should sort before any value",
+			lesser:  []any{nil},
+			greater: []any{int64(math.MinInt64)},
+		},
+		{
+			name:    "negative integers should sort before positive integers",
+			lesser:  []any{int64(-10)},
+			greater: []any{int64(2)},
+		},
+		{
+			name:    "integers should sort by value instead of digits",
+			lesser:  []any{int64(9)},
+			greater: []any{int64(10)},
+		},
+		{
+			name:    "integers of every size should sort together",
+			lesser:  []any{int32(9)},
+			greater: []any{10},
+		},
+		{
+			name:    "negative floats should sort by value",
+			lesser:  []any{-2.5},
+			greater: []any{-1.25},
+		},
+		{
+			name:    "negative floats should sort before positive floats",
+			lesser:  []any{-0.5},
+			greater: []any{0.25},
+		},
+		{
+			name:    "infinity should sort after every float",
+			lesser:  []any{math.MaxFloat64},
+			greater: []any{math.Inf(1)},
+		},
+		{
+			name:    "times should sort chronologically",
+			lesser:  []any{time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+			greater: []any{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "strings should sort lexicographically",
+			lesser:  []any{"bar"},
+			greater: []any{"foo"},
+		},
+		{
+			name:    "a string should sort before the strings it is a prefix of",
+			lesser:  []any{"foo"},
+			greater: []any{"foo\x00"},
+		},
+		{
+			name:    "the first value should take precedence",
+			lesser:  []any{"a", int64(10)},
+			greater: []any{"ab", int64(1)},
+		},
+		{
+			name:    "the next value should break ties",
+			lesser:  []any{"a", int64(1)},
+			greater: []any{"a", int64(10)},
+		},
+		{
+			name:    "a key should sort before the keys it is a prefix of",
+			lesser:  []any{"a"},
+			greater: []any{"a", nil},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			lesser := EncodeOrderedKey(testCase.lesser...)
+			greater := EncodeOrderedKey(testCase.greater...)
+
+			if lesser >= greater {
+				t.Errorf("expected %q to sort before %q", lesser, greater)
+				return
+			}
+
+			if end := OrderedKeyPrefixEnd(lesser); end <= lesser || (len(testCase.lesser) == len(testCase.greater) && end >= greater) {
+				t.Errorf("expected %q to be between %q and %q", end, lesser, greater)
+				return
+			}
+		})
+	}
+}